@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
+	"homemoney/internal/middleware"
 	"homemoney/internal/repository"
 	"homemoney/internal/routes"
 	"homemoney/internal/service"
@@ -39,11 +41,19 @@ func main() {
 
 	// 创建Repository实例
 	expenseRepo := repository.NewExpenseRepository(db.GetDB())
+	budgetRepo := repository.NewBudgetRepository(db.GetDB())
+	incomeRepo := repository.NewIncomeRepository(db.GetDB())
+	accountRepo := repository.NewAccountRepository(db.GetDB())
+	recurringRepo := repository.NewRecurringExpenseRepository(db.GetDB())
+	categoryRepo := repository.NewCategoryRepository(db.GetDB())
+	settlementRepo := repository.NewSettlementRepository(db.GetDB())
+	exchangeRateRepo := repository.NewExchangeRateRepository(db.GetDB())
 
 	// 创建会员相关的Repository实例
 	memberRepo := repository.NewMemberRepository(db.GetDB())
 	planRepo := repository.NewSubscriptionPlanRepository(db.GetDB())
 	subscriptionRepo := repository.NewUserSubscriptionRepository(db.GetDB())
+	authRepo := repository.NewAuthRepository(db.GetDB())
 
 	// 设置Gin模式
 	if os.Getenv("GIN_MODE") == "release" {
@@ -72,24 +82,61 @@ func main() {
 	routes.SetupHealthRoutes(router, startTime)
 	routes.SetupHelpRoutes(router)
 
-	// 设置API路由
-	routes.SetupExpenseRoutes(router, expenseRepo)
+	// 设置认证路由，账本、会员、管理和维护接口都需要登录
+	authService := service.NewAuthService(authRepo, memberRepo)
+	authMiddleware := middleware.RequireAuth(authService)
+	routes.SetupAuthRoutes(router, authService, authMiddleware)
+
+	// 设置API路由，只读成员只能查看账本
+	ledgerAccess := middleware.RequireLedgerAccess()
+	routes.SetupExpenseRoutes(router, expenseRepo, authMiddleware, ledgerAccess)
+	routes.SetupExportRoutes(router, expenseRepo, authMiddleware, ledgerAccess)
+	routes.SetupBudgetRoutes(router, budgetRepo, authMiddleware, ledgerAccess)
+	routes.SetupIncomeRoutes(router, expenseRepo, incomeRepo, authMiddleware, ledgerAccess)
+	routes.SetupAccountRoutes(router, accountRepo, authMiddleware, ledgerAccess)
+	routes.SetupRecurringExpenseRoutes(router, recurringRepo, authMiddleware, ledgerAccess)
+	routes.SetupCategoryRoutes(router, categoryRepo, authMiddleware, ledgerAccess)
+	routes.SetupSettlementRoutes(router, settlementRepo, authMiddleware, ledgerAccess)
+	routes.SetupExchangeRateRoutes(router, exchangeRateRepo, authMiddleware)
 
 	// 设置会员相关的API路由 - 对应JS版本的memberRoutes
-	routes.SetupMemberRoutes(router, memberRepo, planRepo, subscriptionRepo)
+	routes.SetupMemberRoutes(router, memberRepo, planRepo, subscriptionRepo, authMiddleware)
 
 	// 初始化服务实例
 	// 创建支付服务实例
-	paymentService := service.NewPaymentService(planRepo, memberRepo)
+	paymentOrderRepo := repository.NewPaymentOrderRepository(db.GetDB())
+	paymentGatewayConfig := service.PaymentGatewayConfigFromEnv()
+	paymentGateway := service.NewHTTPPaymentGateway(paymentGatewayConfig)
+	paymentService := service.NewPaymentService(planRepo, memberRepo, subscriptionRepo, paymentOrderRepo, paymentGateway, paymentGatewayConfig.Secret, service.RenewalPolicyFromEnv())
+	// 创建捐赠记录服务实例，并一次性导入旧版的捐赠记录文件
+	donationService := service.NewDonationService(repository.NewDonationRepository(db.GetDB()))
+	if err := donationService.ImportLegacyFile(filepath.Join("data", "donation_records.json")); err != nil {
+		log.Printf("导入旧版捐赠记录失败: %v", err)
+	}
 	// 创建JSON文件服务实例
 	jsonFileService := service.NewJsonFileService()
 	// 创建日志服务实例
 	logService := service.NewLogService(db.GetDB())
 
+	// 创建通知服务实例，站内信始终启用，邮件和webhook按环境变量配置启用
+	notificationRepo := repository.NewNotificationRepository(db.GetDB())
+	notificationService := service.NewNotificationService(notificationRepo, subscriptionRepo, budgetRepo,
+		service.NotifiersFromEnv(notificationRepo), service.NotificationConfigFromEnv())
+
+	// 创建后台任务调度器，过期检查、自动续费等任务在进程内定时执行
+	scheduler := service.NewScheduler()
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, planRepo, memberRepo)
+	if err := service.RegisterMaintenanceJobs(scheduler, recurringRepo, subscriptionService, paymentService, notificationService, authRepo); err != nil {
+		log.Fatalf("后台任务配置错误: %v", err)
+	}
+
 	// 注册路由
-	routes.SetupPaymentRoutes(router, paymentService)
+	routes.SetupPaymentRoutes(router, paymentService, authMiddleware)
+	routes.SetupDonationRoutes(router, donationService, authMiddleware)
 	routes.SetupJsonFileRoutes(router.Group("/api"), jsonFileService)
-	routes.SetupLogRoutes(router.Group("/api"), logService)
+	routes.SetupLogRoutes(router.Group("/api"), logService, authMiddleware)
+	routes.SetupJobRoutes(router, scheduler, authMiddleware)
+	routes.SetupNotificationRoutes(router, notificationService, authMiddleware)
 
 	// 创建HTTP服务器
 	srv := &http.Server{
@@ -100,6 +147,12 @@ func main() {
 		IdleTimeout:  config.IdleTimeout,
 	}
 
+	// 启动后台任务
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	scheduler.Start(jobCtx)
+
 	// 启动服务器
 	go func() {
 		log.Printf("服务器启动在端口 %s", config.Port)
@@ -117,6 +170,12 @@ func main() {
 
 	log.Println("正在关闭服务器...")
 
+	// 停止后台任务，等待正在运行的任务结束，避免续费等操作执行到一半
+	stopJobs()
+	if !scheduler.Wait(30 * time.Second) {
+		log.Println("等待后台任务结束超时")
+	}
+
 	// 优雅关闭服务器
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
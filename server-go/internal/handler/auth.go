@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/repository"
+	"homemoney/internal/service"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler 认证处理程序
+type AuthHandler struct {
+	authService *service.AuthService
+}
+
+// NewAuthHandler 创建新的认证处理程序
+func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// Register 注册账号 - POST /api/auth/register
+// 提供inviteCode时加入已有家庭，否则创建名为householdName的新家庭
+func (h *AuthHandler) Register(c *gin.Context) {
+	var request struct {
+		Username      string `json:"username" binding:"required"`
+		Password      string `json:"password" binding:"required,min=8"`
+		HouseholdName string `json:"householdName"`
+		InviteCode    string `json:"inviteCode"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "用户名和密码是必填项，密码至少8位", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.authService.Register(request.Username, request.Password, request.HouseholdName, request.InviteCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUsernameTaken):
+			utils.ErrorResponseWithStatus(c, "注册失败", err.Error(), http.StatusConflict)
+		case errors.Is(err, service.ErrInvalidInviteCode):
+			utils.ErrorResponseWithStatus(c, "注册失败", err.Error(), http.StatusBadRequest)
+		default:
+			utils.ErrorResponseWithStatus(c, "注册失败", err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(result))
+}
+
+// Setup 初始化系统管理员 - POST /api/auth/setup
+// 需要提供与 AUTH_SETUP_TOKEN 环境变量一致的setupToken，只能执行一次
+func (h *AuthHandler) Setup(c *gin.Context) {
+	var request struct {
+		SetupToken    string `json:"setupToken" binding:"required"`
+		Username      string `json:"username" binding:"required"`
+		Password      string `json:"password" binding:"required,min=8"`
+		HouseholdName string `json:"householdName"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "初始化令牌、用户名和密码是必填项，密码至少8位", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.authService.Setup(request.SetupToken, request.Username, request.Password, request.HouseholdName)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSetupDisabled), errors.Is(err, service.ErrInvalidSetupToken):
+			utils.ErrorResponseWithStatus(c, "初始化失败", err.Error(), http.StatusForbidden)
+		case errors.Is(err, repository.ErrAdminExists), errors.Is(err, service.ErrUsernameTaken):
+			utils.ErrorResponseWithStatus(c, "初始化失败", err.Error(), http.StatusConflict)
+		default:
+			utils.ErrorResponseWithStatus(c, "初始化失败", err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(result))
+}
+
+// CreateClaimCode 为尚未设置密码的会员生成一次性认领码 - POST /api/auth/claim-codes
+func (h *AuthHandler) CreateClaimCode(c *gin.Context) {
+	var request struct {
+		Username string `json:"username" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "用户名是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.authService.CreateClaimCode(middleware.CurrentMember(c), request.Username)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPermissionDenied):
+			utils.ErrorResponseWithStatus(c, "权限不足", err.Error(), http.StatusForbidden)
+		case errors.Is(err, service.ErrMemberNotFound):
+			utils.ErrorResponseWithStatus(c, "会员不存在", err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrMemberClaimed):
+			utils.ErrorResponseWithStatus(c, "生成认领码失败", err.Error(), http.StatusConflict)
+		default:
+			utils.ErrorResponseWithStatus(c, "生成认领码失败", err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(result))
+}
+
+// Claim 使用认领码为尚未设置密码的会员设置密码并登录 - POST /api/auth/claim
+func (h *AuthHandler) Claim(c *gin.Context) {
+	var request struct {
+		Username  string `json:"username" binding:"required"`
+		ClaimCode string `json:"claimCode" binding:"required"`
+		Password  string `json:"password" binding:"required,min=8"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "用户名、认领码和密码是必填项，密码至少8位", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.authService.Claim(request.Username, request.ClaimCode, request.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidClaimCode) {
+			utils.ErrorResponseWithStatus(c, "认领失败", err.Error(), http.StatusBadRequest)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "认领失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// Login 登录 - POST /api/auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var request struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "用户名和密码是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.authService.Login(request.Username, request.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			utils.ErrorResponseWithStatus(c, "登录失败", err.Error(), http.StatusUnauthorized)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "登录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// Logout 注销当前登录令牌 - POST /api/auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.authService.Logout(middleware.BearerToken(c)); err != nil {
+		utils.ErrorResponseWithStatus(c, "注销失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Me 获取当前登录的会员及其家庭 - GET /api/auth/me
+func (h *AuthHandler) Me(c *gin.Context) {
+	member := middleware.CurrentMember(c)
+
+	household, err := h.authService.GetHousehold(middleware.HouseholdID(c))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取家庭信息失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{
+		"member":    member,
+		"household": household,
+	}))
+}
+
+// UpdateHousehold 修改当前家庭的本位币 - PUT /api/auth/household
+func (h *AuthHandler) UpdateHousehold(c *gin.Context) {
+	var request struct {
+		BaseCurrency string `json:"baseCurrency" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "本位币是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	household, err := h.authService.UpdateBaseCurrency(middleware.HouseholdID(c), request.BaseCurrency)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "验证失败"):
+			utils.ErrorResponseWithStatus(c, "修改本位币失败", err.Error(), http.StatusBadRequest)
+		case err.Error() == "记录不存在":
+			utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		default:
+			utils.ErrorResponseWithStatus(c, "修改本位币失败", err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(household))
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"homemoney/internal/models"
+	"homemoney/internal/service"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DonationHandler 捐赠记录处理程序
+type DonationHandler struct {
+	donationService *service.DonationService
+}
+
+// NewDonationHandler 创建新的捐赠记录处理程序
+func NewDonationHandler(donationService *service.DonationService) *DonationHandler {
+	return &DonationHandler{
+		donationService: donationService,
+	}
+}
+
+// GetDonations 查询捐赠记录 - GET /api/donations
+// 支持username、status、startDate、endDate、minAmount、maxAmount、excludeUsers筛选，返回分页记录和按用户汇总
+func (h *DonationHandler) GetDonations(c *gin.Context) {
+	query, err := parseDonationQuery(c)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查询参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, total, summary, err := h.donationService.ListDonations(query)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查询参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := utils.PaginatedResponse(records, total, query.Offset/query.Limit+1, query.Limit)
+	response["summary"] = summary
+	c.JSON(http.StatusOK, response)
+}
+
+// GetLeaderboard 捐赠排行榜 - GET /api/donations/leaderboard
+// 按成功捐赠总额排序，limit默认10、最多100，支持startDate、endDate、excludeUsers筛选
+func (h *DonationHandler) GetLeaderboard(c *gin.Context) {
+	query, err := parseDonationQuery(c)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查询参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+	query.Username = ""
+	query.Offset = 0
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		utils.ErrorResponseWithStatus(c, "查询参数错误", "limit参数必须在1-100之间", http.StatusBadRequest)
+		return
+	}
+
+	donors, err := h.donationService.Leaderboard(query, limit)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取排行榜失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(donors))
+}
+
+// ImportDonations 导入捐赠记录文件 - POST /api/donations/import
+// 上传字段为file，内容为旧版donation_records.json格式（每行一条JSON），已存在的记录会被跳过
+func (h *DonationHandler) ImportDonations(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "请上传捐赠记录文件", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取上传文件失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.donationService.ImportJSONL(file)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "导入捐赠记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// parseDonationQuery 解析捐赠记录查询参数
+func parseDonationQuery(c *gin.Context) (*models.DonationQuery, error) {
+	query := &models.DonationQuery{
+		Username:  c.Query("username"),
+		Status:    c.Query("status"),
+		StartDate: c.Query("startDate"),
+		EndDate:   c.Query("endDate"),
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	query.Limit = limit
+	query.Offset = (page - 1) * limit
+
+	if minAmountStr := c.Query("minAmount"); minAmountStr != "" {
+		minAmount, err := models.ParseMoney(minAmountStr)
+		if err != nil {
+			return nil, err
+		}
+		query.MinAmount = &minAmount
+	}
+	if maxAmountStr := c.Query("maxAmount"); maxAmountStr != "" {
+		maxAmount, err := models.ParseMoney(maxAmountStr)
+		if err != nil {
+			return nil, err
+		}
+		query.MaxAmount = &maxAmount
+	}
+
+	for _, username := range strings.Split(c.Query("excludeUsers"), ",") {
+		if username = strings.TrimSpace(username); username != "" {
+			query.ExcludeUsers = append(query.ExcludeUsers, username)
+		}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"homemoney/internal/service"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler 后台任务处理程序
+type JobHandler struct {
+	scheduler *service.Scheduler
+}
+
+// NewJobHandler 创建新的后台任务处理程序
+func NewJobHandler(scheduler *service.Scheduler) *JobHandler {
+	return &JobHandler{
+		scheduler: scheduler,
+	}
+}
+
+// GetJobs 查看后台任务运行状态 - GET /api/maintenance/jobs
+// 返回每个任务的调度规则、最近一次运行时间、耗时、错误和下一次运行时间
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.SuccessResponse(h.scheduler.Statuses()))
+}
+
+// RunJob 立即执行后台任务 - POST /api/maintenance/jobs/:name/run
+func (h *JobHandler) RunJob(c *gin.Context) {
+	h.runJob(c, c.Param("name"))
+}
+
+// CheckSubscriptionStatus 立即执行订阅过期检查 - GET /api/maintenance/check-subscriptions
+func (h *JobHandler) CheckSubscriptionStatus(c *gin.Context) {
+	h.runJob(c, service.JobExpireSubscriptions)
+}
+
+// ProcessAutoRenewals 立即执行自动续费 - GET /api/maintenance/process-renewals
+func (h *JobHandler) ProcessAutoRenewals(c *gin.Context) {
+	h.runJob(c, service.JobRenewSubscriptions)
+}
+
+// runJob 立即执行任务，与定时执行共用单次运行锁，任务正在运行时返回409
+func (h *JobHandler) runJob(c *gin.Context, name string) {
+	err := h.scheduler.RunNow(name)
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		utils.ErrorResponseWithStatus(c, "后台任务不存在", name, http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrJobRunning):
+		utils.ErrorResponseWithStatus(c, "后台任务正在运行", err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		utils.ErrorResponseWithStatus(c, "后台任务执行失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, status := range h.scheduler.Statuses() {
+		if status.Name == name {
+			c.JSON(http.StatusOK, utils.SuccessResponse(status))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(nil))
+}
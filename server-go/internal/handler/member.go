@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
 	"homemoney/internal/service"
+	"homemoney/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,8 +38,12 @@ func (h *MemberHandler) GetOrCreateMember(c *gin.Context) {
 		return
 	}
 
-	member, err := h.memberService.GetOrCreateMember(request.Username)
+	member, err := h.memberService.GetOrCreateMember(middleware.CurrentMember(c), request.Username)
 	if err != nil {
+		if errors.Is(err, service.ErrPermissionDenied) {
+			utils.ErrorResponseWithStatus(c, "权限不足", err.Error(), http.StatusForbidden)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "获取或创建会员失败",
 			"message": err.Error(),
@@ -81,7 +89,7 @@ func (h *MemberHandler) GetMemberInfo(c *gin.Context) {
 		return
 	}
 
-	memberInfo, err := h.memberService.GetMemberInfo(username)
+	memberInfo, err := h.memberService.GetMemberInfo(middleware.CurrentMember(c), username)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "会员不存在",
@@ -137,6 +145,34 @@ func (h *MemberHandler) UpdateMemberStatus(c *gin.Context) {
 	})
 }
 
+// UpdateMemberRole 设置会员角色 - PUT /members/:username/role
+func (h *MemberHandler) UpdateMemberRole(c *gin.Context) {
+	var request struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "参数验证失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	member, err := h.memberService.UpdateMemberRole(middleware.CurrentMember(c), c.Param("username"), request.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPermissionDenied):
+			utils.ErrorResponseWithStatus(c, "权限不足", err.Error(), http.StatusForbidden)
+		case errors.Is(err, service.ErrMemberNotFound):
+			utils.ErrorResponseWithStatus(c, "会员不存在", err.Error(), http.StatusNotFound)
+		case models.ValidateRole(request.Role) != nil:
+			utils.ErrorResponseWithStatus(c, "参数验证失败", err.Error(), http.StatusBadRequest)
+		default:
+			utils.ErrorResponseWithStatus(c, "更新会员角色失败", err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(member))
+}
+
 // GetMemberSubscriptions 获取会员订阅列表 - 对应JS版本的 GET /subscriptions
 func (h *MemberHandler) GetMemberSubscriptions(c *gin.Context) {
 	username := c.Param("username")
@@ -148,8 +184,12 @@ func (h *MemberHandler) GetMemberSubscriptions(c *gin.Context) {
 		return
 	}
 
-	subscriptions, err := h.memberService.GetMemberSubscriptions(username)
+	subscriptions, err := h.memberService.GetMemberSubscriptions(middleware.CurrentMember(c), username)
 	if err != nil {
+		if errors.Is(err, service.ErrMemberNotFound) {
+			utils.ErrorResponseWithStatus(c, "会员不存在", err.Error(), http.StatusNotFound)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "获取订阅列表失败",
 			"message": err.Error(),
@@ -192,8 +232,12 @@ func (h *MemberHandler) GetMemberWithActiveSubscription(c *gin.Context) {
 		return
 	}
 
-	member, subscription, err := h.memberService.GetMemberWithActiveSubscription(username)
+	member, subscription, err := h.memberService.GetMemberWithActiveSubscription(middleware.CurrentMember(c), username)
 	if err != nil {
+		if errors.Is(err, service.ErrMemberNotFound) {
+			utils.ErrorResponseWithStatus(c, "会员不存在", err.Error(), http.StatusNotFound)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "获取会员信息失败",
 			"message": err.Error(),
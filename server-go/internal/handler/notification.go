@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/service"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 通知处理程序
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationHandler 创建新的通知处理程序
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetInbox 获取当前会员的站内信 - GET /api/notifications
+// 支持unread=true只看未读，分页参数page、limit，返回中的unread为未读总数
+func (h *NotificationHandler) GetInbox(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit = utils.ValidatePagination(page, limit)
+
+	member := middleware.CurrentMember(c)
+	messages, total, unread, err := h.notificationService.ListInbox(member.ID, c.Query("unread") == "true", limit, (page-1)*limit)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取站内信失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := utils.PaginatedResponse(messages, total, page, limit)
+	response["unread"] = unread
+	c.JSON(http.StatusOK, response)
+}
+
+// MarkRead 将站内信标记为已读 - PUT /api/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		utils.ErrorResponseWithStatus(c, "无效的站内信ID", c.Param("id"), http.StatusBadRequest)
+		return
+	}
+
+	message, err := h.notificationService.MarkRead(middleware.CurrentMember(c).ID, uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrInboxMessageNotFound) {
+			utils.ErrorResponseWithStatus(c, "标记已读失败", err.Error(), http.StatusNotFound)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "标记已读失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(message))
+}
+
+// MarkAllRead 将全部站内信标记为已读 - PUT /api/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	updated, err := h.notificationService.MarkAllRead(middleware.CurrentMember(c).ID)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "标记已读失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{"updated": updated}))
+}
+
+// GetSettings 获取当前会员的通知设置 - GET /api/notifications/settings
+func (h *NotificationHandler) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.SuccessResponse(h.notificationService.GetSettings(middleware.CurrentMember(c))))
+}
+
+// UpdateSettings 更新当前会员的通知邮箱和语言 - PUT /api/notifications/settings
+func (h *NotificationHandler) UpdateSettings(c *gin.Context) {
+	var settings models.NotificationSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.notificationService.UpdateSettings(middleware.CurrentMember(c), &settings)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "更新通知设置失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// GetRecords 查询通知发送记录 - GET /api/admin/notifications
+// 支持username、kind、channel、status筛选，分页参数page、limit
+func (h *NotificationHandler) GetRecords(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	query := &models.NotificationQuery{
+		Username: c.Query("username"),
+		Kind:     c.Query("kind"),
+		Channel:  c.Query("channel"),
+		Status:   c.Query("status"),
+		Limit:    limit,
+		Offset:   (page - 1) * limit,
+	}
+
+	records, total, err := h.notificationService.ListRecords(query)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查询参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.PaginatedResponse(records, total, page, limit))
+}
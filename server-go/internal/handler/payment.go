@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
 	"homemoney/internal/service"
+	"homemoney/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,10 +28,8 @@ func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
 
 // Donate 处理捐赠请求
 func (h *PaymentHandler) Donate(c *gin.Context) {
-	var request struct {
-		Username string  `json:"username" binding:"required"`
-		Amount   float64 `json:"amount" binding:"required,gt=0"`
-	}
+	// 金额按分解析，binding 拒绝小于等于0的金额
+	var request service.DonationRequest
 
 	// 绑定请求参数
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -36,17 +40,8 @@ func (h *PaymentHandler) Donate(c *gin.Context) {
 		return
 	}
 
-	// 验证金额最多两位小数
-	if !isValidAmount(request.Amount) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "金额最多只能有两位小数",
-		})
-		return
-	}
-
 	// 调用服务处理捐赠
-	response, err := h.paymentService.Donate(request.Username, request.Amount)
+	response, err := h.paymentService.Donate(c.Request.Context(), request.Username, request.Amount, c.GetHeader(service.HeaderIdempotencyKey))
 	if err != nil || !response.Success {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -75,7 +70,7 @@ func (h *PaymentHandler) SubscribePayment(c *gin.Context) {
 	}
 
 	// 调用服务处理订阅支付
-	response, err := h.paymentService.SubscribePayment(request.Username, request.PlanID)
+	response, err := h.paymentService.SubscribePayment(c.Request.Context(), request.Username, request.PlanID, c.GetHeader(service.HeaderIdempotencyKey))
 	if err != nil || !response.Success {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -87,9 +82,77 @@ func (h *PaymentHandler) SubscribePayment(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// isValidAmount 验证金额是否有效（最多两位小数）
-func isValidAmount(amount float64) bool {
-	// 检查金额是否为整数或最多两位小数
-	intAmount := int64(amount * 100)
-	return float64(intAmount)/100 == amount
-}
\ No newline at end of file
+// PaymentCallback 处理支付网关回调，签名校验通过后更新订单并激活订阅
+func (h *PaymentHandler) PaymentCallback(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取回调内容失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.paymentService.HandleCallback(
+		c.GetHeader(service.HeaderPaymentTimestamp),
+		c.GetHeader(service.HeaderPaymentSignature),
+		body,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSignature), errors.Is(err, service.ErrCallbackExpired):
+			utils.ErrorResponseWithStatus(c, "回调签名校验失败", err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, service.ErrCallbackNotConfigured):
+			utils.ErrorResponseWithStatus(c, "支付回调不可用", err.Error(), http.StatusServiceUnavailable)
+		case errors.Is(err, service.ErrInvalidCallback), errors.Is(err, repository.ErrPaymentAmountInvalid):
+			utils.ErrorResponseWithStatus(c, "回调内容无效", err.Error(), http.StatusBadRequest)
+		case errors.Is(err, repository.ErrPaymentOrderNotFound):
+			utils.ErrorResponseWithStatus(c, "支付订单不存在", err.Error(), http.StatusNotFound)
+		case errors.Is(err, repository.ErrPaymentOrderSettled), errors.Is(err, repository.ErrOrderNotRefundable):
+			utils.ErrorResponseWithStatus(c, "支付订单状态冲突", err.Error(), http.StatusConflict)
+		default:
+			utils.ErrorResponseWithStatus(c, "处理支付回调失败", err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(order))
+}
+
+// RefundOrder 管理员对已支付订单退款 - POST /api/admin/payments/:orderId/refund
+// mode为full（全额）或prorated（按订阅剩余天数），默认full
+func (h *PaymentHandler) RefundOrder(c *gin.Context) {
+	var request struct {
+		Mode   string `json:"mode"`
+		Reason string `json:"reason"`
+	}
+	// 请求体可以为空，此时全额退款
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponseWithStatus(c, "参数验证失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.Mode == "" {
+		request.Mode = models.RefundFull
+	}
+
+	operator := middleware.CurrentMember(c).Username
+	order, refund, err := h.paymentService.RefundOrder(c.Request.Context(), c.Param("orderId"), request.Mode, request.Reason, operator)
+	if err != nil {
+		var gatewayErr *service.GatewayError
+		switch {
+		case errors.Is(err, repository.ErrPaymentOrderNotFound):
+			utils.ErrorResponseWithStatus(c, "支付订单不存在", err.Error(), http.StatusNotFound)
+		case errors.Is(err, repository.ErrOrderNotRefundable), errors.Is(err, repository.ErrPaymentOrderSettled):
+			utils.ErrorResponseWithStatus(c, "订单无法退款", err.Error(), http.StatusConflict)
+		case errors.Is(err, repository.ErrProratedUnsupported), errors.Is(err, repository.ErrNothingToRefund):
+			utils.ErrorResponseWithStatus(c, "订单无法退款", err.Error(), http.StatusBadRequest)
+		case errors.As(err, &gatewayErr):
+			utils.ErrorResponseWithStatus(c, "支付网关退款失败", err.Error(), http.StatusBadGateway)
+		default:
+			utils.ErrorResponseWithStatus(c, "退款失败", err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{
+		"order":  order,
+		"refund": refund,
+	}))
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"homemoney/internal/middleware"
 	"homemoney/internal/models"
+	"homemoney/internal/repository"
 	"homemoney/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -51,6 +54,7 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 		Username  string `json:"username" binding:"required"`
 		PlanID    string `json:"planId" binding:"required"`
 		AutoRenew bool   `json:"autoRenew"`
+		TrialDays int    `json:"trialDays" binding:"gte=0,lte=365"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -61,7 +65,8 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 		return
 	}
 
-	subscription, err := h.subscriptionService.CreateSubscription(request.Username, request.PlanID, request.AutoRenew)
+	operator := middleware.CurrentMember(c).Username
+	subscription, err := h.subscriptionService.CreateSubscription(request.Username, request.PlanID, request.AutoRenew, request.TrialDays, operator)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "创建订阅失败",
@@ -88,8 +93,15 @@ func (h *SubscriptionHandler) GetCurrentSubscription(c *gin.Context) {
 		return
 	}
 
-	subscription, err := h.subscriptionService.GetCurrentSubscription(username)
+	subscription, err := h.subscriptionService.GetCurrentSubscription(middleware.CurrentMember(c), username)
 	if err != nil {
+		if errors.Is(err, service.ErrMemberNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "会员不存在",
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "获取当前订阅失败",
 			"message": err.Error(),
@@ -124,8 +136,15 @@ func (h *SubscriptionHandler) GetMemberSubscriptions(c *gin.Context) {
 		return
 	}
 
-	subscriptions, err := h.subscriptionService.GetMemberSubscriptions(username)
+	subscriptions, err := h.subscriptionService.GetMemberSubscriptions(middleware.CurrentMember(c), username)
 	if err != nil {
+		if errors.Is(err, service.ErrMemberNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "会员不存在",
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "获取订阅列表失败",
 			"message": err.Error(),
@@ -151,8 +170,8 @@ func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
 		return
 	}
 
-	if err := h.subscriptionService.CancelSubscription(subscriptionID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+	if err := h.subscriptionService.CancelSubscription(middleware.CurrentMember(c), subscriptionID, c.Query("reason")); err != nil {
+		c.JSON(subscriptionErrorStatus(err), gin.H{
 			"error":   "取消订阅失败",
 			"message": err.Error(),
 		})
@@ -179,8 +198,9 @@ func (h *SubscriptionHandler) RenewSubscription(c *gin.Context) {
 		return
 	}
 
-	if err := h.subscriptionService.RenewSubscription(subscriptionID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+	operator := middleware.CurrentMember(c).Username
+	if err := h.subscriptionService.RenewSubscription(subscriptionID, operator); err != nil {
+		c.JSON(subscriptionErrorStatus(err), gin.H{
 			"error":   "续费订阅失败",
 			"message": err.Error(),
 		})
@@ -196,11 +216,25 @@ func (h *SubscriptionHandler) RenewSubscription(c *gin.Context) {
 	})
 }
 
-// CheckSubscriptionStatus 检查订阅状态
-func (h *SubscriptionHandler) CheckSubscriptionStatus(c *gin.Context) {
-	if err := h.subscriptionService.CheckExpiredSubscriptions(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "检查订阅状态失败",
+// UpdateSubscriptionStatus 手工变更订阅状态 - PUT /api/subscriptions/:id/status
+func (h *SubscriptionHandler) UpdateSubscriptionStatus(c *gin.Context) {
+	var request struct {
+		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "参数验证失败",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	operator := middleware.CurrentMember(c).Username
+	subscription, err := h.subscriptionService.TransitionSubscription(c.Param("id"), request.Status, operator, request.Reason)
+	if err != nil {
+		c.JSON(subscriptionErrorStatus(err), gin.H{
+			"error":   "变更订阅状态失败",
 			"message": err.Error(),
 		})
 		return
@@ -208,15 +242,17 @@ func (h *SubscriptionHandler) CheckSubscriptionStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "订阅状态检查完成",
+		"message": "订阅状态已更新",
+		"data":    subscription,
 	})
 }
 
-// ProcessAutoRenewals 处理自动续费
-func (h *SubscriptionHandler) ProcessAutoRenewals(c *gin.Context) {
-	if err := h.subscriptionService.ProcessAutoRenewals(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "处理自动续费失败",
+// GetSubscriptionEvents 获取订阅状态变更记录 - GET /api/subscriptions/:id/events
+func (h *SubscriptionHandler) GetSubscriptionEvents(c *gin.Context) {
+	events, err := h.subscriptionService.GetSubscriptionEvents(middleware.CurrentMember(c), c.Param("id"))
+	if err != nil {
+		c.JSON(subscriptionErrorStatus(err), gin.H{
+			"error":   "获取订阅记录失败",
 			"message": err.Error(),
 		})
 		return
@@ -224,10 +260,21 @@ func (h *SubscriptionHandler) ProcessAutoRenewals(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "自动续费处理完成",
+		"data":    events,
 	})
 }
 
+// subscriptionErrorStatus 订阅不存在返回404，不允许的状态流转和并发修改返回409，其他错误返回400
+func subscriptionErrorStatus(err error) int {
+	if errors.Is(err, service.ErrSubscriptionNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, models.ErrInvalidSubscriptionTransition) || errors.Is(err, repository.ErrSubscriptionChanged) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
 // GetExpiringSubscriptions 获取即将过期的订阅
 func (h *SubscriptionHandler) GetExpiringSubscriptions(c *gin.Context) {
 	daysBefore := 7 // 默认7天
@@ -295,7 +342,7 @@ func (h *SubscriptionHandler) AdminCreatePlan(c *gin.Context) {
 		Name:        request.Name,
 		Description: request.Description,
 		Duration:    request.Duration,
-		Price:       request.Price,
+		Price:       models.NewMoney(request.Price),
 		Period:      request.Period,
 	}
 
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler 资金账户处理器
+type AccountHandler struct {
+	accountRepo *repository.AccountRepository
+}
+
+// NewAccountHandler 创建新的资金账户处理器
+func NewAccountHandler(accountRepo *repository.AccountRepository) *AccountHandler {
+	return &AccountHandler{
+		accountRepo: accountRepo,
+	}
+}
+
+// accounts 返回只读写当前登录家庭数据的账户仓库
+func (h *AccountHandler) accounts(c *gin.Context) *repository.AccountRepository {
+	return h.accountRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// accountRequest 创建/更新账户请求参数
+type accountRequest struct {
+	Name           string       `json:"name" binding:"required"`
+	Type           string       `json:"type" binding:"required"`
+	OpeningBalance models.Money `json:"openingBalance"`
+}
+
+// GetAccounts 获取账户列表
+func (h *AccountHandler) GetAccounts(c *gin.Context) {
+	accounts, err := h.accounts(c).FindAll()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(accounts))
+}
+
+// GetAccountByID 根据ID获取账户
+func (h *AccountHandler) GetAccountByID(c *gin.Context) {
+	account, err := h.accounts(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if account == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(account))
+}
+
+// CreateAccount 创建账户
+func (h *AccountHandler) CreateAccount(c *gin.Context) {
+	var request accountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "账户名称和类型是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account := &models.Account{
+		Name:           strings.TrimSpace(request.Name),
+		Type:           request.Type,
+		OpeningBalance: request.OpeningBalance,
+	}
+	if err := account.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.accounts(c).Create(account); err != nil {
+		h.writeSaveError(c, "无法添加账户", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(account))
+}
+
+// UpdateAccount 更新账户
+func (h *AccountHandler) UpdateAccount(c *gin.Context) {
+	account, err := h.accounts(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if account == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	var request accountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account.Name = strings.TrimSpace(request.Name)
+	account.Type = request.Type
+	account.OpeningBalance = request.OpeningBalance
+	if err := account.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.accounts(c).Update(account); err != nil {
+		h.writeSaveError(c, "更新账户失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(account))
+}
+
+// DeleteAccount 删除账户，已被记录引用的账户不能删除
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	if err := h.accounts(c).Delete(c.Param("id")); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAccountInUse):
+			utils.ErrorResponseWithStatus(c, "删除账户失败", err.Error(), http.StatusConflict)
+		case err.Error() == "记录不存在":
+			utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		default:
+			utils.ErrorResponseWithStatus(c, "删除账户失败", err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAccountBalance 获取账户截至asOf（默认今天）的余额
+func (h *AccountHandler) GetAccountBalance(c *gin.Context) {
+	account, err := h.accounts(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if account == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	asOf := c.DefaultQuery("asOf", time.Now().Format("2006-01-02"))
+	if _, err := time.Parse("2006-01-02", asOf); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", "asOf日期格式错误，应为yyyy-mm-dd格式", http.StatusBadRequest)
+		return
+	}
+
+	balance, err := h.accounts(c).GetBalance(account, asOf)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "计算余额失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(balance))
+}
+
+// GetTransfers 获取转账记录，支持 accountId 参数筛选
+func (h *AccountHandler) GetTransfers(c *gin.Context) {
+	var accountID uint
+	if accountIDStr := c.Query("accountId"); accountIDStr != "" {
+		parsed, err := strconv.ParseUint(accountIDStr, 10, 64)
+		if err != nil {
+			utils.ErrorResponseWithStatus(c, "请求参数错误", "accountId参数无效", http.StatusBadRequest)
+			return
+		}
+		accountID = uint(parsed)
+	}
+
+	transfers, err := h.accounts(c).FindTransfers(accountID)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(transfers))
+}
+
+// CreateTransfer 在两个账户之间转账
+func (h *AccountHandler) CreateTransfer(c *gin.Context) {
+	var transfer models.Transfer
+	if err := c.ShouldBindJSON(&transfer); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if transfer.Date == "" {
+		transfer.Date = time.Now().Format("2006-01-02")
+	}
+	if err := transfer.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.accounts(c).CreateTransfer(&transfer); err != nil {
+		utils.ErrorResponseWithStatus(c, "转账失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(transfer))
+}
+
+// writeSaveError 输出保存账户时的错误，账户名称重复时返回409
+func (h *AccountHandler) writeSaveError(c *gin.Context, message string, err error) {
+	if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		utils.ErrorResponseWithStatus(c, message, "账户名称已存在", http.StatusConflict)
+		return
+	}
+	utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusInternalServerError)
+}
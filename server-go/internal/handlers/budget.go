@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler 预算处理器
+type BudgetHandler struct {
+	budgetRepo *repository.BudgetRepository
+}
+
+// NewBudgetHandler 创建新的预算处理器
+func NewBudgetHandler(budgetRepo *repository.BudgetRepository) *BudgetHandler {
+	return &BudgetHandler{
+		budgetRepo: budgetRepo,
+	}
+}
+
+// budgets 返回只读写当前登录家庭数据的预算仓库
+func (h *BudgetHandler) budgets(c *gin.Context) *repository.BudgetRepository {
+	return h.budgetRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// budgetRequest 创建/更新预算请求参数
+type budgetRequest struct {
+	Category string       `json:"category"`
+	Month    string       `json:"month"`
+	Amount   models.Money `json:"amount" binding:"required"`
+}
+
+// GetBudgets 获取预算列表，支持 month 参数筛选对该月生效的预算
+func (h *BudgetHandler) GetBudgets(c *gin.Context) {
+	budgets, err := h.budgets(c).FindAll(c.Query("month"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(budgets))
+}
+
+// GetBudgetByID 根据ID获取预算
+func (h *BudgetHandler) GetBudgetByID(c *gin.Context) {
+	budget, err := h.budgets(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if budget == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(budget))
+}
+
+// CreateBudget 创建预算
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	var request budgetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "预算金额是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	budget := &models.Budget{
+		Category: strings.TrimSpace(request.Category),
+		Month:    request.Month,
+		Amount:   request.Amount,
+	}
+	if err := budget.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.budgets(c).Create(budget); err != nil {
+		h.writeSaveError(c, "无法添加预算", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(budget))
+}
+
+// UpdateBudget 更新预算
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	budget, err := h.budgets(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if budget == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	var request budgetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	budget.Category = strings.TrimSpace(request.Category)
+	budget.Month = request.Month
+	budget.Amount = request.Amount
+	if err := budget.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.budgets(c).Update(budget); err != nil {
+		h.writeSaveError(c, "更新预算失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(budget))
+}
+
+// DeleteBudget 删除预算
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	if err := h.budgets(c).Delete(c.Param("id")); err != nil {
+		if err.Error() == "记录不存在" {
+			utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "删除预算失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetBudgetStatus 获取预算执行情况，month 默认为当前月份
+func (h *BudgetHandler) GetBudgetStatus(c *gin.Context) {
+	month := c.DefaultQuery("month", time.Now().Format("2006-01"))
+	if _, err := time.Parse("2006-01", month); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", "月份格式错误，期望格式: YYYY-MM", http.StatusBadRequest)
+		return
+	}
+
+	statuses, err := h.budgets(c).GetStatus(month)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取预算执行情况失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{
+		"month":   month,
+		"budgets": statuses,
+	}))
+}
+
+// writeSaveError 输出保存预算时的错误，同一分类同一月份重复时返回409
+func (h *BudgetHandler) writeSaveError(c *gin.Context, message string, err error) {
+	if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		utils.ErrorResponseWithStatus(c, message, "该分类在该月份已存在预算", http.StatusConflict)
+		return
+	}
+	utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CashflowHandler 现金流报表处理器
+type CashflowHandler struct {
+	expenseRepo *repository.ExpenseRepository
+	incomeRepo  *repository.IncomeRepository
+}
+
+// NewCashflowHandler 创建新的现金流报表处理器
+func NewCashflowHandler(expenseRepo *repository.ExpenseRepository, incomeRepo *repository.IncomeRepository) *CashflowHandler {
+	return &CashflowHandler{
+		expenseRepo: expenseRepo,
+		incomeRepo:  incomeRepo,
+	}
+}
+
+// incomes 返回只读写当前登录家庭数据的收入记录仓库
+func (h *CashflowHandler) incomes(c *gin.Context) *repository.IncomeRepository {
+	return h.incomeRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// expenses 返回只读写当前登录家庭数据的消费记录仓库
+func (h *CashflowHandler) expenses(c *gin.Context) *repository.ExpenseRepository {
+	return h.expenseRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// GetCashflow 获取按周期汇总的收入、支出和净现金流
+// 支持 from/to/granularity 参数，其余筛选参数与消费记录一致
+func (h *CashflowHandler) GetCashflow(c *gin.Context) {
+	query, err := parseExpenseQuery(c)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from := c.Query("from"); from != "" {
+		query.StartDate = from
+	}
+	if to := c.Query("to"); to != "" {
+		query.EndDate = to
+	}
+	if err := query.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", models.GranularityMonth)
+	if err := models.ValidateGranularity(granularity); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := buildCashflow(h.expenses(c), h.incomes(c), query, granularity)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取现金流失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(report))
+}
+
+// buildCashflow 汇总收入和支出并按周期补零
+func buildCashflow(expenseRepo *repository.ExpenseRepository, incomeRepo *repository.IncomeRepository, query *models.ExpenseQuery, granularity string) (*models.CashflowReport, error) {
+	expenses, err := expenseRepo.SumByPeriod(query, granularity)
+	if err != nil {
+		return nil, err
+	}
+	incomes, err := incomeRepo.SumByPeriod(query, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := resolveCashflowRange(expenseRepo, incomeRepo, query)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CashflowReport{
+		Granularity: granularity,
+		From:        from,
+		To:          to,
+		Items:       []models.CashflowItem{},
+	}
+	if from == "" || to == "" {
+		return report, nil
+	}
+
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("开始日期格式错误: %w", err)
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("结束日期格式错误: %w", err)
+	}
+
+	for _, period := range models.PeriodKeys(fromDate, toDate, granularity) {
+		item := models.CashflowItem{
+			Period:  period,
+			Income:  incomes[period],
+			Expense: expenses[period],
+		}
+		item.Net = item.Income - item.Expense
+
+		report.TotalIncome += item.Income
+		report.TotalExpense += item.Expense
+		report.Items = append(report.Items, item)
+	}
+	report.Net = report.TotalIncome - report.TotalExpense
+
+	return report, nil
+}
+
+// resolveCashflowRange 确定报表的日期范围，未指定时使用收入和支出数据的最早、最晚日期
+func resolveCashflowRange(expenseRepo *repository.ExpenseRepository, incomeRepo *repository.IncomeRepository, query *models.ExpenseQuery) (string, string, error) {
+	from, to := query.StartDate, query.EndDate
+	if from != "" && to != "" {
+		return from, to, nil
+	}
+
+	expenseMin, expenseMax, err := expenseRepo.DateBounds(query)
+	if err != nil {
+		return "", "", err
+	}
+	incomeMin, incomeMax, err := incomeRepo.DateBounds(query)
+	if err != nil {
+		return "", "", err
+	}
+
+	if from == "" {
+		from = minNonEmpty(expenseMin, incomeMin)
+	}
+	if to == "" {
+		to = maxNonEmpty(expenseMax, incomeMax)
+	}
+	return from, to, nil
+}
+
+// minNonEmpty 返回两个日期字符串中较早的非空值
+func minNonEmpty(a, b string) string {
+	if a == "" || (b != "" && b < a) {
+		return b
+	}
+	return a
+}
+
+// maxNonEmpty 返回两个日期字符串中较晚的非空值
+func maxNonEmpty(a, b string) string {
+	if b > a {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryHandler 消费分类处理器
+type CategoryHandler struct {
+	categoryRepo *repository.CategoryRepository
+}
+
+// NewCategoryHandler 创建新的消费分类处理器
+func NewCategoryHandler(categoryRepo *repository.CategoryRepository) *CategoryHandler {
+	return &CategoryHandler{
+		categoryRepo: categoryRepo,
+	}
+}
+
+// categories 返回只读写当前登录家庭数据的分类仓库
+func (h *CategoryHandler) categories(c *gin.Context) *repository.CategoryRepository {
+	return h.categoryRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// categoryRequest 创建/更新分类请求参数，更新时忽略名称
+type categoryRequest struct {
+	Name     string `json:"name"`
+	ParentID *uint  `json:"parentId"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+}
+
+// GetCategories 获取分类树
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	tree, err := h.categories(c).GetTree()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(tree))
+}
+
+// CreateCategory 创建分类
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var request categoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	category := &models.Category{
+		Name:     strings.TrimSpace(request.Name),
+		ParentID: request.ParentID,
+		Icon:     strings.TrimSpace(request.Icon),
+		Color:    request.Color,
+	}
+	if err := category.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.categories(c).Create(category); err != nil {
+		writeCategoryError(c, "无法添加分类", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(category))
+}
+
+// UpdateCategory 更新分类的上级分类、图标和颜色，名称通过重命名接口修改
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	category, ok := h.findCategory(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var request categoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	category.ParentID = request.ParentID
+	category.Icon = strings.TrimSpace(request.Icon)
+	category.Color = request.Color
+	if err := category.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.categories(c).Update(category); err != nil {
+		writeCategoryError(c, "更新分类失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(category))
+}
+
+// DeleteCategory 删除分类，使用该分类的消费记录保留原类型
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	if err := h.categories(c).Delete(c.Param("id")); err != nil {
+		if err.Error() == "记录不存在" {
+			utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+			return
+		}
+		writeCategoryError(c, "删除分类失败", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SyncCategories 为已有消费类型中还没有分类的类型创建顶级分类
+func (h *CategoryHandler) SyncCategories(c *gin.Context) {
+	result, err := h.categories(c).SyncFromExpenses()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "同步分类失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// RenameCategory 重命名分类，同时改写使用旧名称的消费记录、周期模板和预算
+func (h *CategoryHandler) RenameCategory(c *gin.Context) {
+	category, ok := h.findCategory(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "分类名称是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.categories(c).Rename(category, strings.TrimSpace(request.Name))
+	if err != nil {
+		writeCategoryError(c, "重命名分类失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// MergeCategory 将分类合并到目标分类，合并后原分类被删除
+func (h *CategoryHandler) MergeCategory(c *gin.Context) {
+	source, ok := h.findCategory(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var request struct {
+		TargetID uint `json:"targetId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "目标分类是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+	target, ok := h.findCategory(c, strconv.FormatUint(uint64(request.TargetID), 10))
+	if !ok {
+		return
+	}
+
+	result, err := h.categories(c).Merge(source, target)
+	if err != nil {
+		writeCategoryError(c, "合并分类失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// findCategory 查找分类，不存在或查询失败时输出错误并返回false
+func (h *CategoryHandler) findCategory(c *gin.Context, id string) (*models.Category, bool) {
+	category, err := h.categories(c).FindByID(id)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if category == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "分类不存在: "+id, http.StatusNotFound)
+		return nil, false
+	}
+	return category, true
+}
+
+// writeCategoryError 输出分类操作的错误，名称重复和层级冲突返回409，参数错误返回400
+func writeCategoryError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCategoryNameExists),
+		errors.Is(err, repository.ErrCategoryHasChildren),
+		errors.Is(err, repository.ErrMergeIntoDescendant),
+		errors.Is(err, models.ErrCategoryCycle),
+		strings.Contains(err.Error(), "UNIQUE constraint failed"):
+		utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusConflict)
+	case errors.Is(err, repository.ErrParentCategoryNotFound),
+		strings.HasPrefix(err.Error(), "验证失败"):
+		utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusBadRequest)
+	default:
+		utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exchangeRateHeaderAliases 汇率CSV表头别名
+var exchangeRateHeaderAliases = map[string]string{
+	"日期":           "date",
+	"date":         "date",
+	"原币种":          "from",
+	"from":         "from",
+	"fromcurrency": "from",
+	"目标币种":         "to",
+	"to":           "to",
+	"tocurrency":   "to",
+	"汇率":           "rate",
+	"rate":         "rate",
+}
+
+// ExchangeRateHandler 汇率处理器
+type ExchangeRateHandler struct {
+	exchangeRateRepo *repository.ExchangeRateRepository
+}
+
+// NewExchangeRateHandler 创建新的汇率处理器
+func NewExchangeRateHandler(exchangeRateRepo *repository.ExchangeRateRepository) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		exchangeRateRepo: exchangeRateRepo,
+	}
+}
+
+// GetExchangeRates 获取汇率，currency 参数筛选涉及该币种的汇率
+func (h *ExchangeRateHandler) GetExchangeRates(c *gin.Context) {
+	rates, err := h.exchangeRateRepo.FindAll(models.NormalizeCurrency(c.Query("currency")))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(rates))
+}
+
+// SaveExchangeRates 批量保存汇率，请求体为汇率数组，已有同一日期和币种对的汇率会被覆盖
+func (h *ExchangeRateHandler) SaveExchangeRates(c *gin.Context) {
+	var rates []models.ExchangeRate
+	if err := c.ShouldBindJSON(&rates); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(rates) == 0 {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", "汇率列表不能为空", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exchangeRateRepo.Upsert(rates); err != nil {
+		if strings.Contains(err.Error(), "验证失败") {
+			utils.ErrorResponseWithStatus(c, "保存汇率失败", err.Error(), http.StatusBadRequest)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "保存汇率失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(rates))
+}
+
+// ImportExchangeRates 导入汇率CSV文件，上传字段为file，表头为 date,from,to,rate；
+// 无效的行被跳过并在导入结果中列出
+func (h *ExchangeRateHandler) ImportExchangeRates(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "请上传汇率文件", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取上传文件失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	rows, err := readCSVRows(file)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "解析文件失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, rates, err := buildExchangeRateReport(rows)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "解析文件失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exchangeRateRepo.Upsert(rates); err != nil {
+		utils.ErrorResponseWithStatus(c, "导入汇率失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	report.Imported = len(rates)
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(report))
+}
+
+// DeleteExchangeRate 删除汇率
+func (h *ExchangeRateHandler) DeleteExchangeRate(c *gin.Context) {
+	if err := h.exchangeRateRepo.Delete(c.Param("id")); err != nil {
+		if err.Error() == "记录不存在" {
+			utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "删除汇率失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// buildExchangeRateReport 逐行转换并验证汇率，返回导入结果和有效的汇率；
+// 文件内同一日期和币种对出现多次时以最后一行为准
+func buildExchangeRateReport(rows [][]string) (*models.ExchangeRateImportReport, []models.ExchangeRate, error) {
+	report := &models.ExchangeRateImportReport{Errors: []models.ExchangeRateRowError{}}
+	if len(rows) == 0 {
+		return report, nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		if field, ok := exchangeRateHeaderAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+			if _, exists := columns[field]; !exists {
+				columns[field] = i
+			}
+		}
+	}
+	for _, field := range []string{"date", "from", "to", "rate"} {
+		if _, ok := columns[field]; !ok {
+			return nil, nil, fmt.Errorf("缺少必要的列: %s", field)
+		}
+	}
+
+	var rates []models.ExchangeRate
+	index := make(map[string]int)
+	for i, row := range rows[1:] {
+		rowNumber := i + 2 // 表头为第1行
+		if isBlankRow(row) {
+			continue
+		}
+		report.Total++
+
+		cell := func(field string) string {
+			idx := columns[field]
+			if idx >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[idx])
+		}
+
+		rate := models.ExchangeRate{
+			FromCurrency: cell("from"),
+			ToCurrency:   cell("to"),
+		}
+		date, err := parseImportDate(cell("date"))
+		if err == nil {
+			rate.Date = date
+			rate.Rate, err = strconv.ParseFloat(cell("rate"), 64)
+			if err != nil {
+				err = fmt.Errorf("汇率格式错误: %s", cell("rate"))
+			}
+		}
+		if err == nil {
+			rate.Normalize()
+			err = rate.Validate()
+		}
+		if err != nil {
+			report.Rejected++
+			report.Errors = append(report.Errors, models.ExchangeRateRowError{Row: rowNumber, Reason: err.Error()})
+			continue
+		}
+
+		key := rate.Date + "\x00" + rate.FromCurrency + "\x00" + rate.ToCurrency
+		if idx, ok := index[key]; ok {
+			rates[idx] = rate
+			continue
+		}
+		index[key] = len(rates)
+		rates = append(rates, rate)
+	}
+	return report, rates, nil
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"homemoney/internal/middleware"
 	"homemoney/internal/models"
 	"homemoney/internal/repository"
 	"homemoney/pkg/utils"
@@ -25,24 +29,29 @@ func NewExpenseHandler(expenseRepo *repository.ExpenseRepository) *ExpenseHandle
 	}
 }
 
+// expenses 返回只读写当前登录家庭数据的消费记录仓库
+func (h *ExpenseHandler) expenses(c *gin.Context) *repository.ExpenseRepository {
+	return h.expenseRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
 // GetExpenses 获取消费记录列表
 func (h *ExpenseHandler) GetExpenses(c *gin.Context) {
 	// 解析查询参数
-	query, err := h.parseExpenseQuery(c)
+	query, err := parseExpenseQuery(c)
 	if err != nil {
 		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// 执行查询
-	expenses, total, err := h.expenseRepo.FindWithPagination(query)
+	expenses, total, err := h.expenses(c).FindWithPagination(query)
 	if err != nil {
 		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// 获取元数据
-	meta, err := h.expenseRepo.GetMeta()
+	meta, err := h.expenses(c).GetMeta()
 	if err != nil {
 		// 元数据获取失败不影响主要功能
 		meta = &models.ExpenseMeta{}
@@ -74,8 +83,8 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 	}
 
 	// 保存记录
-	if err := h.expenseRepo.Create(&expense); err != nil {
-		utils.ErrorResponseWithStatus(c, "无法添加记录", err.Error(), http.StatusInternalServerError)
+	if err := h.expenses(c).Create(&expense); err != nil {
+		writeExpenseSaveError(c, "无法添加记录", err)
 		return
 	}
 
@@ -85,13 +94,13 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 
 // GetExpenseStatistics 获取消费统计
 func (h *ExpenseHandler) GetExpenseStatistics(c *gin.Context) {
-	query, err := h.parseExpenseQuery(c)
+	query, err := parseExpenseQuery(c)
 	if err != nil {
 		utils.ErrorResponseWithStatus(c, "获取统计数据失败", err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	stats, err := h.expenseRepo.GetStatistics(query)
+	stats, err := h.expenses(c).GetStatistics(query)
 	if err != nil {
 		utils.ErrorResponseWithStatus(c, "获取统计数据失败", err.Error(), http.StatusInternalServerError)
 		return
@@ -101,12 +110,158 @@ func (h *ExpenseHandler) GetExpenseStatistics(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetExpenseTags 获取当前家庭的全部标签及使用次数
+func (h *ExpenseHandler) GetExpenseTags(c *gin.Context) {
+	tags, err := h.expenses(c).GetTags()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取标签失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(tags))
+}
+
+// maxTimeSeriesPeriods 单次时间序列统计允许的最大周期数
+const maxTimeSeriesPeriods = 3660
+
+// GetExpenseTimeSeries 获取按周期分桶的消费时间序列
+// 支持 granularity=day|week|month|year、groupBy=type 以及 from/to 参数，其余筛选参数与消费记录一致
+func (h *ExpenseHandler) GetExpenseTimeSeries(c *gin.Context) {
+	query, err := parseExpenseQuery(c)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from := c.Query("from"); from != "" {
+		query.StartDate = from
+	}
+	if to := c.Query("to"); to != "" {
+		query.EndDate = to
+	}
+	if err := query.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", models.GranularityDay)
+	if err := models.ValidateGranularity(granularity); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+	groupBy := c.Query("groupBy")
+	if err := models.ValidateGroupBy(groupBy); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 未指定范围时使用数据的最早、最晚日期
+	from, to := query.StartDate, query.EndDate
+	if from == "" || to == "" {
+		minDate, maxDate, err := h.expenses(c).DateBounds(query)
+		if err != nil {
+			utils.ErrorResponseWithStatus(c, "获取统计数据失败", err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if from == "" {
+			from = minDate
+		}
+		if to == "" {
+			to = maxDate
+		}
+	}
+
+	report := &models.TimeSeriesReport{
+		Granularity: granularity,
+		GroupBy:     groupBy,
+		From:        from,
+		To:          to,
+		Periods:     []string{},
+		Series:      []models.TimeSeries{},
+	}
+	if from == "" || to == "" {
+		c.JSON(http.StatusOK, utils.SuccessResponse(report))
+		return
+	}
+
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", "开始日期格式错误，期望格式: YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", "结束日期格式错误，期望格式: YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	report.Periods = models.PeriodKeys(fromDate, toDate, granularity)
+	if len(report.Periods) > maxTimeSeriesPeriods {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", fmt.Sprintf("统计周期数超过上限%d，请缩小日期范围或使用更大的粒度", maxTimeSeriesPeriods), http.StatusBadRequest)
+		return
+	}
+
+	// 限定到补零范围内再汇总
+	query.StartDate, query.EndDate = from, to
+	totals, err := h.expenses(c).SumByPeriodGrouped(query, granularity, groupBy)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取统计数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report.Series = buildTimeSeries(report.Periods, totals, groupBy)
+	c.JSON(http.StatusOK, utils.SuccessResponse(report))
+}
+
+// buildTimeSeries 将汇总结果按分组展开为补零后的序列，序列按总金额从高到低排列
+func buildTimeSeries(periods []string, totals []models.PeriodTotal, groupBy string) []models.TimeSeries {
+	byGroup := make(map[string]map[string]models.PeriodTotal)
+	if groupBy == models.GroupByNone {
+		byGroup[models.TimeSeriesTotalName] = make(map[string]models.PeriodTotal)
+	}
+	for _, total := range totals {
+		name := total.Group
+		if groupBy == models.GroupByNone {
+			name = models.TimeSeriesTotalName
+		}
+		if byGroup[name] == nil {
+			byGroup[name] = make(map[string]models.PeriodTotal)
+		}
+		byGroup[name][total.Period] = total
+	}
+
+	series := make([]models.TimeSeries, 0, len(byGroup))
+	for name, values := range byGroup {
+		item := models.TimeSeries{
+			Name:   name,
+			Points: make([]models.TimeSeriesPoint, 0, len(periods)),
+		}
+		for _, period := range periods {
+			value := values[period]
+			item.Points = append(item.Points, models.TimeSeriesPoint{
+				Period: period,
+				Amount: value.Total,
+				Count:  value.Count,
+			})
+			item.Total += value.Total
+			item.Count += value.Count
+		}
+		series = append(series, item)
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].Total != series[j].Total {
+			return series[i].Total > series[j].Total
+		}
+		return series[i].Name < series[j].Name
+	})
+	return series
+}
+
 // DeleteExpense 删除消费记录
 func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 	id := c.Param("id")
 
 	// 检查记录是否存在
-	exists, err := h.expenseRepo.Exists(id)
+	exists, err := h.expenses(c).Exists(id)
 	if err != nil {
 		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
 		return
@@ -117,7 +272,7 @@ func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 	}
 
 	// 删除记录
-	if err := h.expenseRepo.Delete(id); err != nil {
+	if err := h.expenses(c).Delete(id); err != nil {
 		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -126,12 +281,12 @@ func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// UpdateExpense 更新消费记录（需要先添加这个功能）
+// UpdateExpense 更新消费记录（整体覆盖，未提供的字段会被清空）
 func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 	id := c.Param("id")
 
 	// 查找现有记录
-	expense, err := h.expenseRepo.FindByID(id)
+	expense, err := h.expenses(c).FindByID(id)
 	if err != nil {
 		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
 		return
@@ -153,18 +308,74 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 	expense.Remark = updateData.Remark
 	expense.Amount = updateData.Amount
 	expense.Date = updateData.Date
+	expense.AccountID = updateData.AccountID
+	expense.Tags = updateData.Tags
+	expense.PayerID = updateData.PayerID
+	expense.SplitMode = updateData.SplitMode
+	expense.Splits = updateData.Splits
+	expense.Currency = updateData.Currency
+	if err := expense.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// 保存更新
-	if err := h.expenseRepo.Update(expense); err != nil {
-		utils.ErrorResponseWithStatus(c, "更新记录失败", err.Error(), http.StatusInternalServerError)
+	if err := h.expenses(c).Update(expense); err != nil {
+		writeExpenseSaveError(c, "更新记录失败", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, utils.SuccessResponse(expense))
 }
 
+// PatchExpense 部分更新消费记录，只修改请求中提供的字段
+func (h *ExpenseHandler) PatchExpense(c *gin.Context) {
+	id := c.Param("id")
+
+	// 查找现有记录
+	expense, err := h.expenses(c).FindByID(id)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if expense == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	// 解析请求数据
+	var patch models.ExpensePatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	patch.ApplyTo(expense)
+	if err := expense.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 保存更新
+	if err := h.expenses(c).Update(expense); err != nil {
+		writeExpenseSaveError(c, "更新记录失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(expense))
+}
+
+// writeExpenseSaveError 输出保存消费记录时的错误，验证失败和分摊成员不属于本家庭返回400
+func writeExpenseSaveError(c *gin.Context, message string, err error) {
+	if errors.Is(err, repository.ErrMemberNotInHousehold) || strings.Contains(err.Error(), "验证失败") {
+		utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusBadRequest)
+		return
+	}
+	utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusInternalServerError)
+}
+
 // parseExpenseQuery 解析expense查询参数
-func (h *ExpenseHandler) parseExpenseQuery(c *gin.Context) (*models.ExpenseQuery, error) {
+func parseExpenseQuery(c *gin.Context) (*models.ExpenseQuery, error) {
 	query := &models.ExpenseQuery{}
 
 	// 解析基础参数
@@ -193,6 +404,22 @@ func (h *ExpenseHandler) parseExpenseQuery(c *gin.Context) (*models.ExpenseQuery
 		}
 	}
 
+	// 解析账户参数
+	if accountIDStr := c.Query("accountId"); accountIDStr != "" {
+		if accountID, err := strconv.ParseUint(accountIDStr, 10, 64); err == nil {
+			id := uint(accountID)
+			query.AccountID = &id
+		}
+	}
+
+	// 解析标签参数，如 tags=旅行,餐饮&tagMode=all
+	tags, err := models.ParseTagList(c.Query("tags"))
+	if err != nil {
+		return nil, fmt.Errorf("标签参数错误: %w", err)
+	}
+	query.Tags = tags
+	query.TagMode = c.DefaultQuery("tagMode", models.TagModeAny)
+
 	// 解析日期参数（直接使用字符串）
 	query.StartDate = c.Query("startDate")
 	query.EndDate = c.Query("endDate")
@@ -238,8 +465,8 @@ func (h *ExpenseHandler) BatchCreateExpense(c *gin.Context) {
 	}
 
 	// 批量创建
-	if err := h.expenseRepo.BatchCreate(expenses); err != nil {
-		utils.ErrorResponseWithStatus(c, "批量创建失败", err.Error(), http.StatusInternalServerError)
+	if err := h.expenses(c).BatchCreate(expenses); err != nil {
+		writeExpenseSaveError(c, "批量创建失败", err)
 		return
 	}
 
@@ -253,7 +480,7 @@ func (h *ExpenseHandler) BatchCreateExpense(c *gin.Context) {
 func (h *ExpenseHandler) GetExpenseByID(c *gin.Context) {
 	id := c.Param("id")
 
-	expense, err := h.expenseRepo.FindByID(id)
+	expense, err := h.expenses(c).FindByID(id)
 	if err != nil {
 		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportSheetName 导出工作表名称 - 与JS版本一致
+const exportSheetName = "消费记录"
+
+// exportColumns 导出列 - 与JS版本一致
+var exportColumns = []string{"type", "remark", "amount", "date"}
+
+// ExportHandler 数据导出处理器
+type ExportHandler struct {
+	expenseRepo *repository.ExpenseRepository
+}
+
+// NewExportHandler 创建新的导出处理器
+func NewExportHandler(expenseRepo *repository.ExpenseRepository) *ExportHandler {
+	return &ExportHandler{
+		expenseRepo: expenseRepo,
+	}
+}
+
+// expenses 返回只读写当前登录家庭数据的消费记录仓库
+func (h *ExportHandler) expenses(c *gin.Context) *repository.ExpenseRepository {
+	return h.expenseRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// ExportExcel 导出消费记录 - 对应JS版本的 GET /api/export/excel
+// 支持与消费列表相同的筛选参数，format=csv 时导出CSV
+func (h *ExportHandler) ExportExcel(c *gin.Context) {
+	query, err := parseExpenseQuery(c)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "导出参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filename := fmt.Sprintf("expenses_%d", time.Now().UnixMilli())
+
+	switch c.DefaultQuery("format", "xlsx") {
+	case "csv":
+		h.exportCSV(c, query, filename+".csv")
+	case "xlsx", "excel":
+		h.exportXLSX(c, query, filename+".xlsx")
+	default:
+		utils.ErrorResponseWithStatus(c, "导出参数错误", "format参数只支持xlsx或csv", http.StatusBadRequest)
+	}
+}
+
+// exportXLSX 以流式写入的方式生成Excel文件
+func (h *ExportHandler) exportXLSX(c *gin.Context, query *models.ExpenseQuery, filename string) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), exportSheetName); err != nil {
+		utils.ErrorResponseWithStatus(c, "生成Excel失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sw, err := f.NewStreamWriter(exportSheetName)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "生成Excel失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	header := make([]interface{}, len(exportColumns))
+	for i, col := range exportColumns {
+		header[i] = col
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		utils.ErrorResponseWithStatus(c, "生成Excel失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rowIndex := 2
+	err = h.expenses(c).ForEach(query, func(expense *models.Expense) error {
+		cell, err := excelize.CoordinatesToCellName(1, rowIndex)
+		if err != nil {
+			return err
+		}
+		rowIndex++
+		return sw.SetRow(cell, []interface{}{
+			expense.Type,
+			remarkOrEmpty(expense.Remark),
+			expense.Amount.Float64(),
+			expense.Date,
+		})
+	})
+	if err == nil {
+		err = sw.Flush()
+	}
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "生成Excel失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+	if err := f.Write(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+// exportCSV 逐行写出CSV文件
+func (h *ExportHandler) exportCSV(c *gin.Context, query *models.ExpenseQuery, filename string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	// 写入UTF-8 BOM，保证Excel打开中文不乱码
+	c.Writer.Write([]byte("\xEF\xBB\xBF"))
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(exportColumns)
+
+	err := h.expenses(c).ForEach(query, func(expense *models.Expense) error {
+		return w.Write([]string{
+			expense.Type,
+			remarkOrEmpty(expense.Remark),
+			expense.Amount.String(),
+			expense.Date,
+		})
+	})
+	w.Flush()
+	if err == nil {
+		err = w.Error()
+	}
+	if err != nil {
+		// 响应头已发送，只能记录错误
+		c.Error(err)
+	}
+}
+
+// remarkOrEmpty 备注为空时返回空字符串 - 与JS版本一致
+func remarkOrEmpty(remark *string) string {
+	if remark == nil {
+		return ""
+	}
+	return *remark
+}
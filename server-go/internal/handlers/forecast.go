@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"homemoney/internal/models"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// forecastHistoryMonths 预测时最多回看的历史月数
+const forecastHistoryMonths = 36
+
+// GetExpenseForecast 预测未来各月按类型的消费金额
+// 支持 months（预测月数，默认3，从当月开始）、window（移动平均月数，默认3）和 type 参数
+func (h *ExpenseHandler) GetExpenseForecast(c *gin.Context) {
+	months, err := strconv.Atoi(c.DefaultQuery("months", "3"))
+	if err != nil || months < 1 || months > 12 {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", "months参数必须在1-12之间", http.StatusBadRequest)
+		return
+	}
+	window, err := strconv.Atoi(c.DefaultQuery("window", "3"))
+	if err != nil || window < 1 || window > 12 {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", "window参数必须在1-12之间", http.StatusBadRequest)
+		return
+	}
+
+	// 历史数据截止到上个月月底，当月数据不完整不参与建模
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	query := &models.ExpenseQuery{
+		Type:      c.Query("type"),
+		StartDate: currentMonth.AddDate(0, -forecastHistoryMonths, 0).Format("2006-01-02"),
+		EndDate:   currentMonth.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+
+	result := &models.ExpenseForecast{
+		Months:     months,
+		Window:     window,
+		Confidence: models.ForecastConfidence,
+	}
+
+	var targetMonths []string
+	for i := 0; i < months; i++ {
+		targetMonths = append(targetMonths, currentMonth.AddDate(0, i, 0).Format("2006-01"))
+	}
+
+	// 历史从第一笔记录所在月份开始，避免记账之前的月份被当作零支出
+	firstDate, _, err := h.expenses(c).DateBounds(query)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取预测数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if firstDate == "" {
+		result.Forecast = models.BuildForecast(nil, nil, targetMonths, window)
+		c.JSON(http.StatusOK, utils.SuccessResponse(result))
+		return
+	}
+	query.StartDate = firstDate
+
+	totals, err := h.expenses(c).SumByPeriodGrouped(query, models.GranularityMonth, models.GroupByType)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取预测数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	history := make(map[string]map[string]float64)
+	for _, total := range totals {
+		if history[total.Group] == nil {
+			history[total.Group] = make(map[string]float64)
+		}
+		history[total.Group][total.Period] = total.Total.Float64()
+	}
+
+	fromDate, err := time.Parse("2006-01-02", query.StartDate)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "获取预测数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	toDate := currentMonth.AddDate(0, 0, -1)
+	historyMonths := models.PeriodKeys(fromDate, toDate, models.GranularityMonth)
+
+	result.HistoryFrom = historyMonths[0]
+	result.HistoryTo = historyMonths[len(historyMonths)-1]
+	result.Forecast = models.BuildForecast(history, historyMonths, targetMonths, window)
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// 导入行状态
+const (
+	ImportRowAccepted  = "accepted"
+	ImportRowRejected  = "rejected"
+	ImportRowDuplicate = "duplicate"
+)
+
+// importHeaderAliases 表头别名 - 兼容JS版本的中英文表头以及导出文件的表头
+var importHeaderAliases = map[string]string{
+	"分类":          "type",
+	"类型":          "type",
+	"type":        "type",
+	"category":    "type",
+	"备注":          "remark",
+	"remark":      "remark",
+	"description": "remark",
+	"金额":          "amount",
+	"amount":      "amount",
+	"日期":          "date",
+	"date":        "date",
+}
+
+// importDateLayouts 支持的日期格式
+var importDateLayouts = []string{
+	"2006-01-02",
+	"2006-1-2",
+	"2006/01/02",
+	"2006/1/2",
+	"2006.01.02",
+	"2006.1.2",
+	"2006年1月2日",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+}
+
+// amountNoise 金额中可以忽略的货币符号、千位分隔符和空白；负号和括号不剔除，负数金额由校验拒绝
+var amountNoise = regexp.MustCompile(`[¥￥$€£,，\s\x{00A0}\x{3000}]|元`)
+
+// amountPattern 清洗后的金额格式，只允许可选的正负号和十进制数字
+var amountPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)$`)
+
+// ImportRowResult 单行导入结果
+type ImportRowResult struct {
+	Row     int             `json:"row"`
+	Status  string          `json:"status"`
+	Reason  string          `json:"reason,omitempty"`
+	Expense *models.Expense `json:"expense,omitempty"`
+}
+
+// ImportReport 导入报告
+type ImportReport struct {
+	DryRun     bool              `json:"dryRun"`
+	Total      int               `json:"total"`
+	Accepted   int               `json:"accepted"`
+	Rejected   int               `json:"rejected"`
+	Duplicates int               `json:"duplicates"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+// ImportHandler 数据导入处理器
+type ImportHandler struct {
+	expenseRepo *repository.ExpenseRepository
+}
+
+// NewImportHandler 创建新的导入处理器
+func NewImportHandler(expenseRepo *repository.ExpenseRepository) *ImportHandler {
+	return &ImportHandler{
+		expenseRepo: expenseRepo,
+	}
+}
+
+// expenses 返回只读写当前登录家庭数据的消费记录仓库
+func (h *ImportHandler) expenses(c *gin.Context) *repository.ExpenseRepository {
+	return h.expenseRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// ImportExcel 导入Excel/CSV文件 - 对应JS版本的 POST /api/import/excel
+// 逐行验证并返回导入报告，dryRun=true 时只预览不写入
+func (h *ImportHandler) ImportExcel(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "未上传文件",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取上传文件失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+	case ".csv":
+		rows, err = readCSVRows(file)
+	case ".xlsx", ".xlsm":
+		rows, err = readXLSXRows(file)
+	default:
+		utils.ErrorResponseWithStatus(c, "不支持的文件格式", "仅支持.xlsx和.csv文件", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "解析文件失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, accepted, err := buildImportReport(h.expenses(c), rows)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "解析文件失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+	report.DryRun = dryRun
+
+	if !dryRun && len(accepted) > 0 {
+		if err := h.expenses(c).BatchCreate(accepted); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "数据库插入失败。",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	var message string
+	switch {
+	case report.Accepted == 0:
+		message = "没有有效数据被导入。"
+	case dryRun:
+		message = fmt.Sprintf("预览：可导入 %d 条记录。", report.Accepted)
+	default:
+		message = fmt.Sprintf("成功导入 %d 条记录。", report.Accepted)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    report,
+	})
+}
+
+// buildImportReport 逐行转换并验证记录，返回报告和可写入的记录
+func buildImportReport(expenseRepo *repository.ExpenseRepository, rows [][]string) (*ImportReport, []models.Expense, error) {
+	report := &ImportReport{Rows: []ImportRowResult{}}
+	if len(rows) == 0 {
+		return report, nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		if field, ok := importHeaderAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+			if _, exists := columns[field]; !exists {
+				columns[field] = i
+			}
+		}
+	}
+	for _, field := range []string{"type", "amount", "date"} {
+		if _, ok := columns[field]; !ok {
+			return nil, nil, fmt.Errorf("缺少必要的列: %s", field)
+		}
+	}
+
+	var accepted []models.Expense
+	seen := make(map[string]int)
+
+	for i, row := range rows[1:] {
+		rowNumber := i + 2 // 表头为第1行
+		if isBlankRow(row) {
+			continue
+		}
+		report.Total++
+
+		expense, err := parseImportRow(row, columns)
+		result := ImportRowResult{Row: rowNumber, Expense: expense}
+		if err == nil {
+			err = expense.Validate()
+		}
+
+		switch {
+		case err != nil:
+			result.Status = ImportRowRejected
+			result.Reason = err.Error()
+			report.Rejected++
+		default:
+			key := importDedupKey(expense)
+			if firstRow, ok := seen[key]; ok {
+				result.Status = ImportRowDuplicate
+				result.Reason = fmt.Sprintf("与文件第%d行重复", firstRow)
+				report.Duplicates++
+				break
+			}
+			seen[key] = rowNumber
+
+			duplicate, err := expenseRepo.IsDuplicate(expense)
+			if err != nil {
+				return nil, nil, fmt.Errorf("检查重复记录失败: %w", err)
+			}
+			if duplicate {
+				result.Status = ImportRowDuplicate
+				result.Reason = "数据库中已存在相同记录"
+				report.Duplicates++
+				break
+			}
+
+			result.Status = ImportRowAccepted
+			report.Accepted++
+			accepted = append(accepted, *expense)
+		}
+
+		report.Rows = append(report.Rows, result)
+	}
+
+	return report, accepted, nil
+}
+
+// parseImportRow 将一行数据转换为消费记录
+func parseImportRow(row []string, columns map[string]int) (*models.Expense, error) {
+	cell := func(field string) string {
+		idx, ok := columns[field]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+
+	expense := &models.Expense{
+		Type: cell("type"),
+	}
+	if remark := cell("remark"); remark != "" {
+		expense.Remark = &remark
+	}
+
+	amountStr := amountNoise.ReplaceAllString(cell("amount"), "")
+	if amountStr == "" {
+		return expense, fmt.Errorf("金额为空或格式错误")
+	}
+	if !amountPattern.MatchString(amountStr) {
+		return expense, fmt.Errorf("金额格式错误: %s", cell("amount"))
+	}
+	amount, err := models.ParseMoney(amountStr)
+	if err != nil {
+		return expense, fmt.Errorf("金额格式错误: %s", cell("amount"))
+	}
+	expense.Amount = amount
+
+	date, err := parseImportDate(cell("date"))
+	if err != nil {
+		return expense, err
+	}
+	expense.Date = date
+
+	return expense, nil
+}
+
+// parseImportDate 解析日期，支持常见文本格式和Excel日期序列号
+func parseImportDate(value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("消费日期不能为空")
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("无法识别的日期: %s", value)
+}
+
+// importDedupKey 生成文件内去重使用的键
+func importDedupKey(expense *models.Expense) string {
+	return strings.Join([]string{
+		expense.Type,
+		expense.Amount.String(),
+		expense.Date,
+		remarkOrEmpty(expense.Remark),
+	}, "\x00")
+}
+
+// readXLSXRows 读取Excel第一个工作表的所有行
+func readXLSXRows(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("文件中没有工作表")
+	}
+	// 使用原始值读取，日期单元格会得到序列号，由parseImportDate统一处理
+	return f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+}
+
+// readCSVRows 读取CSV文件的所有行
+func readCSVRows(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	// 去掉UTF-8 BOM
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\xEF\xBB\xBF")
+	}
+	return rows, nil
+}
+
+// isBlankRow 判断是否为空行
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IncomeHandler 收入记录处理器
+type IncomeHandler struct {
+	incomeRepo *repository.IncomeRepository
+}
+
+// NewIncomeHandler 创建新的收入记录处理器
+func NewIncomeHandler(incomeRepo *repository.IncomeRepository) *IncomeHandler {
+	return &IncomeHandler{
+		incomeRepo: incomeRepo,
+	}
+}
+
+// incomes 返回只读写当前登录家庭数据的收入记录仓库
+func (h *IncomeHandler) incomes(c *gin.Context) *repository.IncomeRepository {
+	return h.incomeRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// GetIncomes 获取收入记录列表（分页、筛选、排序参数与消费记录一致）
+func (h *IncomeHandler) GetIncomes(c *gin.Context) {
+	query, err := parseExpenseQuery(c)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	incomes, total, err := h.incomes(c).FindWithPagination(query)
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := query.Offset/query.Limit + 1
+	c.JSON(http.StatusOK, gin.H{
+		"data":  incomes,
+		"total": total,
+		"page":  page,
+		"limit": query.Limit,
+	})
+}
+
+// GetIncomeByID 根据ID获取收入记录
+func (h *IncomeHandler) GetIncomeByID(c *gin.Context) {
+	income, err := h.incomes(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if income == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(income))
+}
+
+// CreateIncome 创建收入记录
+func (h *IncomeHandler) CreateIncome(c *gin.Context) {
+	var income models.Income
+	if err := c.ShouldBindJSON(&income); err != nil {
+		utils.ErrorResponseWithStatus(c, "收入类型和金额是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := income.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.incomes(c).Create(&income); err != nil {
+		utils.ErrorResponseWithStatus(c, "无法添加记录", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, income)
+}
+
+// UpdateIncome 更新收入记录
+func (h *IncomeHandler) UpdateIncome(c *gin.Context) {
+	income, err := h.incomes(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if income == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	var updateData models.Income
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	income.Type = updateData.Type
+	income.Remark = updateData.Remark
+	income.Amount = updateData.Amount
+	income.Date = updateData.Date
+	income.AccountID = updateData.AccountID
+	if err := income.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.incomes(c).Update(income); err != nil {
+		utils.ErrorResponseWithStatus(c, "更新记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(income))
+}
+
+// DeleteIncome 删除收入记录
+func (h *IncomeHandler) DeleteIncome(c *gin.Context) {
+	if err := h.incomes(c).Delete(c.Param("id")); err != nil {
+		if err.Error() == "记录不存在" {
+			utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "删除记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecurringExpenseHandler 周期性消费模板处理器
+type RecurringExpenseHandler struct {
+	recurringRepo *repository.RecurringExpenseRepository
+}
+
+// NewRecurringExpenseHandler 创建新的周期性消费模板处理器
+func NewRecurringExpenseHandler(recurringRepo *repository.RecurringExpenseRepository) *RecurringExpenseHandler {
+	return &RecurringExpenseHandler{
+		recurringRepo: recurringRepo,
+	}
+}
+
+// templates 返回只读写当前登录家庭数据的周期性消费模板仓库
+func (h *RecurringExpenseHandler) templates(c *gin.Context) *repository.RecurringExpenseRepository {
+	return h.recurringRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// recurringExpenseRequest 创建/更新周期性消费模板请求参数
+type recurringExpenseRequest struct {
+	Type        string       `json:"type" binding:"required"`
+	Remark      *string      `json:"remark"`
+	Amount      models.Money `json:"amount" binding:"required"`
+	AccountID   *uint        `json:"accountId"`
+	Frequency   string       `json:"frequency" binding:"required"`
+	Interval    int          `json:"interval"`
+	DayOfMonth  int          `json:"dayOfMonth"`
+	DayOfWeek   int          `json:"dayOfWeek"`
+	MonthOfYear int          `json:"monthOfYear"`
+	StartDate   string       `json:"startDate"`
+	EndDate     string       `json:"endDate"`
+	Active      *bool        `json:"active"`
+}
+
+// applyTo 将请求参数应用到模板，未提供的间隔、开始日期和启用状态使用默认值
+func (r *recurringExpenseRequest) applyTo(template *models.RecurringExpense) {
+	template.Type = r.Type
+	template.Remark = r.Remark
+	template.Amount = r.Amount
+	template.AccountID = r.AccountID
+	template.Frequency = r.Frequency
+	template.Interval = r.Interval
+	if template.Interval == 0 {
+		template.Interval = 1
+	}
+	template.DayOfMonth = r.DayOfMonth
+	template.DayOfWeek = r.DayOfWeek
+	template.MonthOfYear = r.MonthOfYear
+	template.StartDate = r.StartDate
+	if template.StartDate == "" {
+		template.StartDate = time.Now().Format("2006-01-02")
+	}
+	template.EndDate = r.EndDate
+	template.Active = r.Active == nil || *r.Active
+}
+
+// GetRecurringExpenses 获取周期性消费模板列表
+func (h *RecurringExpenseHandler) GetRecurringExpenses(c *gin.Context) {
+	templates, err := h.templates(c).FindAll()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(templates))
+}
+
+// GetRecurringExpenseByID 根据ID获取周期性消费模板
+func (h *RecurringExpenseHandler) GetRecurringExpenseByID(c *gin.Context) {
+	template, err := h.templates(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if template == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(template))
+}
+
+// CreateRecurringExpense 创建周期性消费模板
+func (h *RecurringExpenseHandler) CreateRecurringExpense(c *gin.Context) {
+	var request recurringExpenseRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "消费类型、金额和周期规则是必填项", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template := &models.RecurringExpense{}
+	request.applyTo(template)
+	if err := template.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.templates(c).Create(template); err != nil {
+		utils.ErrorResponseWithStatus(c, "无法添加周期模板", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(template))
+}
+
+// UpdateRecurringExpense 更新周期性消费模板，已生成的记录不受影响
+func (h *RecurringExpenseHandler) UpdateRecurringExpense(c *gin.Context) {
+	template, err := h.templates(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if template == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	var request recurringExpenseRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	request.applyTo(template)
+	if err := template.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.templates(c).Update(template); err != nil {
+		utils.ErrorResponseWithStatus(c, "更新周期模板失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(template))
+}
+
+// DeleteRecurringExpense 删除周期性消费模板
+func (h *RecurringExpenseHandler) DeleteRecurringExpense(c *gin.Context) {
+	if err := h.templates(c).Delete(c.Param("id")); err != nil {
+		if err.Error() == "记录不存在" {
+			utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "删除周期模板失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PreviewRecurringExpense 预览模板接下来的count次（默认5次，最多100次）发生日期
+func (h *RecurringExpenseHandler) PreviewRecurringExpense(c *gin.Context) {
+	template, err := h.templates(c).FindByID(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "查找记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if template == nil {
+		utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+		return
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+	if err != nil || count < 1 || count > 100 {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", "count参数必须在1-100之间", http.StatusBadRequest)
+		return
+	}
+
+	// 从已生成的最后日期和昨天中较晚的一个之后开始预览
+	after := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	if template.LastGeneratedDate > after {
+		after = template.LastGeneratedDate
+	}
+	// 预览上限足够覆盖count次发生
+	until := time.Now().AddDate(template.Interval*count+1, 0, 0).Format("2006-01-02")
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{
+		"id":          template.ID,
+		"occurrences": template.Occurrences(after, until, count),
+	}))
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SettlementHandler 共同消费余额与结算处理器
+type SettlementHandler struct {
+	settlementRepo *repository.SettlementRepository
+}
+
+// NewSettlementHandler 创建新的结算处理器
+func NewSettlementHandler(settlementRepo *repository.SettlementRepository) *SettlementHandler {
+	return &SettlementHandler{
+		settlementRepo: settlementRepo,
+	}
+}
+
+// settlements 返回只读写当前登录家庭数据的结算仓库
+func (h *SettlementHandler) settlements(c *gin.Context) *repository.SettlementRepository {
+	return h.settlementRepo.ForHousehold(middleware.HouseholdID(c))
+}
+
+// GetBalances 获取家庭成员之间的余额、两两欠款和建议转账
+func (h *SettlementHandler) GetBalances(c *gin.Context) {
+	report, err := h.settlements(c).GetBalances()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "计算余额失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(report))
+}
+
+// GetSettlements 获取结算记录
+func (h *SettlementHandler) GetSettlements(c *gin.Context) {
+	settlements, err := h.settlements(c).FindAll()
+	if err != nil {
+		utils.ErrorResponseWithStatus(c, "读取数据失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(settlements))
+}
+
+// CreateSettlement 记录一笔成员之间的还款
+func (h *SettlementHandler) CreateSettlement(c *gin.Context) {
+	var settlement models.Settlement
+	if err := c.ShouldBindJSON(&settlement); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if settlement.Date == "" {
+		settlement.Date = time.Now().Format("2006-01-02")
+	}
+	if err := settlement.Validate(); err != nil {
+		utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.settlements(c).Create(&settlement); err != nil {
+		writeSettlementError(c, "记录结算失败", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(settlement))
+}
+
+// SettleAll 按建议转账记录结算，结清全部成员之间的欠款
+func (h *SettlementHandler) SettleAll(c *gin.Context) {
+	var request struct {
+		Date   string  `json:"date"`
+		Remark *string `json:"remark"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.ErrorResponseWithStatus(c, "请求参数错误", err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if request.Date == "" {
+		request.Date = time.Now().Format("2006-01-02")
+	}
+
+	settlements, err := h.settlements(c).SettleAll(request.Date, request.Remark)
+	if err != nil {
+		writeSettlementError(c, "结算失败", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse(settlements))
+}
+
+// DeleteSettlement 删除结算记录，对应的欠款恢复
+func (h *SettlementHandler) DeleteSettlement(c *gin.Context) {
+	if err := h.settlements(c).Delete(c.Param("id")); err != nil {
+		if err.Error() == "记录不存在" {
+			utils.ErrorResponseWithStatus(c, "记录不存在", "", http.StatusNotFound)
+			return
+		}
+		utils.ErrorResponseWithStatus(c, "删除结算记录失败", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeSettlementError 输出结算错误，验证失败和成员不属于本家庭返回400
+func writeSettlementError(c *gin.Context, message string, err error) {
+	if errors.Is(err, repository.ErrMemberNotInHousehold) || strings.Contains(err.Error(), "验证失败") {
+		utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusBadRequest)
+		return
+	}
+	utils.ErrorResponseWithStatus(c, message, err.Error(), http.StatusInternalServerError)
+}
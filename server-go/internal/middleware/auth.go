@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"homemoney/internal/models"
+	"homemoney/internal/service"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextMemberKey 请求上下文中保存当前登录会员的键
+const contextMemberKey = "authMember"
+
+// RequireAuth 要求请求携带有效的 Authorization: Bearer <token> 登录令牌
+func RequireAuth(authService *service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := BearerToken(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.ErrorResponse("未登录", "缺少登录令牌，请在Authorization请求头中提供Bearer令牌"))
+			return
+		}
+
+		member, err := authService.Authenticate(token)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidToken) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, utils.ErrorResponse("未登录", err.Error()))
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, utils.ErrorResponse("校验登录状态失败", err.Error()))
+			return
+		}
+
+		c.Set(contextMemberKey, member)
+		c.Next()
+	}
+}
+
+// BearerToken 从 Authorization 请求头中读取Bearer令牌
+func BearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// CurrentMember 返回当前登录的会员，未经过RequireAuth时返回nil
+func CurrentMember(c *gin.Context) *models.Member {
+	if value, ok := c.Get(contextMemberKey); ok {
+		if member, ok := value.(*models.Member); ok {
+			return member
+		}
+	}
+	return nil
+}
+
+// HouseholdID 返回当前登录会员所属的家庭ID
+func HouseholdID(c *gin.Context) uint {
+	if member := CurrentMember(c); member != nil && member.HouseholdID != nil {
+		return *member.HouseholdID
+	}
+	return 0
+}
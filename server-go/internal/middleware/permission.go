@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"homemoney/internal/models"
+	"homemoney/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 要求当前登录会员拥有指定权限，需在RequireAuth之后使用
+func RequirePermission(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkPermission(c, permission)
+	}
+}
+
+// RequireLedgerAccess 查询请求需要查看账本权限，其余请求需要修改账本权限，需在RequireAuth之后使用
+func RequireLedgerAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			checkPermission(c, models.PermissionReadLedger)
+		default:
+			checkPermission(c, models.PermissionWriteLedger)
+		}
+	}
+}
+
+// checkPermission 校验权限，未登录返回401，权限不足返回403
+func checkPermission(c *gin.Context, permission models.Permission) {
+	member := CurrentMember(c)
+	if member == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, utils.ErrorResponse("未登录", "缺少登录令牌，请在Authorization请求头中提供Bearer令牌"))
+		return
+	}
+	if !member.Can(permission) {
+		c.AbortWithStatusJSON(http.StatusForbidden, utils.ErrorResponse("权限不足", fmt.Sprintf("当前角色%s没有%s权限", member.Role, permission)))
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// 账户类型
+var validAccountTypes = map[string]bool{
+	"cash":        true, // 现金
+	"bank_card":   true, // 银行卡
+	"credit_card": true, // 信用卡
+	"alipay":      true, // 支付宝
+	"wechat":      true, // 微信
+	"other":       true, // 其他
+}
+
+// Account 资金账户（现金、银行卡、支付宝、微信等）
+type Account struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	HouseholdID    *uint     `json:"householdId,omitempty" gorm:"uniqueIndex:idx_account_household_name,priority:1"`
+	Name           string    `json:"name" gorm:"type:string;not null;uniqueIndex:idx_account_household_name,priority:2"`
+	Type           string    `json:"type" gorm:"type:string;not null"`
+	OpeningBalance Money     `json:"openingBalance" gorm:"type:integer;not null;default:0"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (Account) TableName() string {
+	return "accounts"
+}
+
+// Validate 验证字段
+func (a *Account) Validate() error {
+	if a.Name == "" {
+		return errors.New("账户名称不能为空")
+	}
+	if !validAccountTypes[a.Type] {
+		return errors.New("账户类型无效，可选值: cash、bank_card、credit_card、alipay、wechat、other")
+	}
+	return nil
+}
+
+// Transfer 账户间转账记录
+type Transfer struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	FromAccountID uint      `json:"fromAccountId" gorm:"not null;index"`
+	ToAccountID   uint      `json:"toAccountId" gorm:"not null;index"`
+	Amount        Money     `json:"amount" gorm:"type:integer;not null"`
+	Date          string    `json:"date" gorm:"type:string;not null;index"`
+	Remark        *string   `json:"remark,omitempty" gorm:"type:string"`
+	HouseholdID   *uint     `json:"householdId,omitempty" gorm:"index"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// TableName 指定表名
+func (Transfer) TableName() string {
+	return "transfers"
+}
+
+// Validate 验证字段
+func (t *Transfer) Validate() error {
+	if t.FromAccountID == 0 || t.ToAccountID == 0 {
+		return errors.New("转出账户和转入账户不能为空")
+	}
+	if t.FromAccountID == t.ToAccountID {
+		return errors.New("转出账户和转入账户不能相同")
+	}
+	if t.Amount <= 0 {
+		return errors.New("转账金额必须大于0")
+	}
+	if _, err := time.Parse("2006-01-02", t.Date); err != nil {
+		return errors.New("转账日期格式错误，应为yyyy-mm-dd格式")
+	}
+	return nil
+}
+
+// AccountBalanceEntry 账户余额变动明细（按日汇总）
+type AccountBalanceEntry struct {
+	Date    string `json:"date"`
+	Change  Money  `json:"change"`
+	Balance Money  `json:"balance"`
+}
+
+// AccountBalance 账户余额
+type AccountBalance struct {
+	AccountID      uint                  `json:"accountId"`
+	AsOf           string                `json:"asOf"`
+	OpeningBalance Money                 `json:"openingBalance"`
+	TotalIncome    Money                 `json:"totalIncome"`
+	TotalExpense   Money                 `json:"totalExpense"`
+	TransferIn     Money                 `json:"transferIn"`
+	TransferOut    Money                 `json:"transferOut"`
+	Balance        Money                 `json:"balance"`
+	History        []AccountBalanceEntry `json:"history"`
+	// UnconvertedCount 缺少汇率无法折算为本位币、未计入余额的消费记录数
+	UnconvertedCount int `json:"unconvertedCount"`
+}
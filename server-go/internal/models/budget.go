@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Budget 分类月度预算
+// Category 对应 Expense.Type，为空表示全部分类的总预算；
+// Month 为 YYYY-MM 表示仅对该月生效，为空表示每月循环生效
+type Budget struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	HouseholdID *uint     `json:"householdId,omitempty" gorm:"uniqueIndex:idx_budget_household_category_month,priority:1"`
+	Category    string    `json:"category" gorm:"type:string;not null;default:'';uniqueIndex:idx_budget_household_category_month,priority:2"`
+	Month       string    `json:"month" gorm:"type:string;not null;default:'';uniqueIndex:idx_budget_household_category_month,priority:3"`
+	Amount      Money     `json:"amount" gorm:"type:integer;not null"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (Budget) TableName() string {
+	return "budgets"
+}
+
+// IsRecurring 是否为每月循环预算
+func (b *Budget) IsRecurring() bool {
+	return b.Month == ""
+}
+
+// Validate 验证字段
+func (b *Budget) Validate() error {
+	if b.Amount <= 0 {
+		return errors.New("预算金额必须大于0")
+	}
+	if b.Month != "" {
+		if _, err := time.Parse("2006-01", b.Month); err != nil {
+			return errors.New("月份格式错误，期望格式: YYYY-MM")
+		}
+	}
+	return nil
+}
+
+// BudgetStatus 预算执行情况
+type BudgetStatus struct {
+	BudgetID   uint    `json:"budgetId"`
+	Category   string  `json:"category"`
+	Recurring  bool    `json:"recurring"`
+	Budget     Money   `json:"budget"`
+	Spent      Money   `json:"spent"`
+	Remaining  Money   `json:"remaining"`
+	Percent    float64 `json:"percent"`
+	OverBudget bool    `json:"overBudget"`
+	// UnconvertedCount 缺少汇率无法折算为本位币、未计入已用金额的消费记录数
+	UnconvertedCount int `json:"unconvertedCount"`
+}
@@ -0,0 +1,218 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxCategoryNameLength 分类名称的最大字符数
+	maxCategoryNameLength = 20
+	// maxCategoryIconLength 分类图标的最大字符数
+	maxCategoryIconLength = 32
+)
+
+// categoryColorPattern 分类颜色，格式为 #RGB 或 #RRGGBB
+var categoryColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ErrCategoryCycle 上级分类不能是分类自身或其下级分类
+var ErrCategoryCycle = errors.New("上级分类不能是分类自身或其下级分类")
+
+// Category 消费分类，Name 对应 Expense.Type，同一家庭内名称唯一；
+// ParentID 为空表示顶级分类，统计时下级分类的金额会汇总到上级分类
+type Category struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	HouseholdID *uint     `json:"householdId,omitempty" gorm:"uniqueIndex:idx_category_household_name,priority:1"`
+	Name        string    `json:"name" gorm:"type:string;not null;uniqueIndex:idx_category_household_name,priority:2"`
+	ParentID    *uint     `json:"parentId,omitempty" gorm:"index"`
+	Icon        string    `json:"icon" gorm:"type:string;not null;default:''"`
+	Color       string    `json:"color" gorm:"type:string;not null;default:''"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (Category) TableName() string {
+	return "categories"
+}
+
+// Validate 验证字段
+func (c *Category) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("分类名称不能为空")
+	}
+	if utf8.RuneCountInString(c.Name) > maxCategoryNameLength {
+		return fmt.Errorf("分类名称不能超过%d个字符", maxCategoryNameLength)
+	}
+	if utf8.RuneCountInString(c.Icon) > maxCategoryIconLength {
+		return fmt.Errorf("分类图标不能超过%d个字符", maxCategoryIconLength)
+	}
+	if c.Color != "" && !categoryColorPattern.MatchString(c.Color) {
+		return errors.New("分类颜色格式错误，应为#RGB或#RRGGBB格式")
+	}
+	if c.ParentID != nil && c.ID != 0 && *c.ParentID == c.ID {
+		return ErrCategoryCycle
+	}
+	return nil
+}
+
+// CategoryNode 分类树节点
+type CategoryNode struct {
+	Category
+	// ExpenseCount 直接使用该分类的消费记录数，不含下级分类
+	ExpenseCount int            `json:"expenseCount"`
+	Children     []CategoryNode `json:"children"`
+}
+
+// CategoryChangeResult 重命名或合并分类的结果
+type CategoryChangeResult struct {
+	Category         *Category `json:"category"`
+	ExpensesUpdated  int64     `json:"expensesUpdated"`
+	RecurringUpdated int64     `json:"recurringUpdated"`
+	BudgetsUpdated   int64     `json:"budgetsUpdated"`
+}
+
+// CategorySyncResult 根据消费类型同步分类的结果，Skipped 为名称不符合分类规则、未能创建分类的类型
+type CategorySyncResult struct {
+	Created []Category         `json:"created"`
+	Skipped []CategorySyncSkip `json:"skipped"`
+}
+
+// CategorySyncSkip 未能创建分类的消费类型及原因
+type CategorySyncSkip struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// CategoryStatsItem 按分类层级汇总的统计项，金额和笔数包含全部下级分类
+type CategoryStatsItem struct {
+	// CategoryID 为空表示消费类型还没有对应的分类
+	CategoryID *uint               `json:"categoryId,omitempty"`
+	Name       string              `json:"name"`
+	Count      int                 `json:"count"`
+	Amount     Money               `json:"amount"`
+	Percentage int                 `json:"percentage"`
+	Children   []CategoryStatsItem `json:"children,omitempty"`
+}
+
+// IsDescendant 判断 id 是否为 ancestorID 的下级分类（含自身），categories 为同一家庭的全部分类
+func IsDescendant(categories []Category, id, ancestorID uint) bool {
+	parents := make(map[uint]*uint, len(categories))
+	for i := range categories {
+		parents[categories[i].ID] = categories[i].ParentID
+	}
+	// 限制遍历次数，避免脏数据中的循环导致死循环
+	for steps := 0; steps <= len(categories); steps++ {
+		if id == ancestorID {
+			return true
+		}
+		parent, ok := parents[id]
+		if !ok || parent == nil {
+			return false
+		}
+		id = *parent
+	}
+	return true
+}
+
+// BuildCategoryTree 构建分类树，counts 为各分类名称直接对应的消费记录数；
+// 上级分类不存在的分类作为顶级分类，同级按名称排序
+func BuildCategoryTree(categories []Category, counts map[string]int) []CategoryNode {
+	known := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+	children := make(map[uint][]Category)
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID == nil || !known[*category.ParentID] || *category.ParentID == category.ID {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	visited := make(map[uint]bool, len(categories))
+	var build func(items []Category) []CategoryNode
+	build = func(items []Category) []CategoryNode {
+		sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+		nodes := make([]CategoryNode, 0, len(items))
+		for _, item := range items {
+			if visited[item.ID] {
+				continue
+			}
+			visited[item.ID] = true
+			nodes = append(nodes, CategoryNode{
+				Category:     item,
+				ExpenseCount: counts[item.Name],
+				Children:     build(children[item.ID]),
+			})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+// RollupCategoryStats 将类型分布按分类层级汇总，上级分类包含全部下级分类的金额和笔数；
+// 没有对应分类的消费类型作为顶级项，同级按金额从高到低排列
+func RollupCategoryStats(categories []Category, typeDistribution map[string]TypeDistributionItem, totalCount int) []CategoryStatsItem {
+	var rollup func(nodes []CategoryNode) []CategoryStatsItem
+	rollup = func(nodes []CategoryNode) []CategoryStatsItem {
+		items := make([]CategoryStatsItem, 0, len(nodes))
+		for _, node := range nodes {
+			id := node.ID
+			own := typeDistribution[node.Name]
+			item := CategoryStatsItem{
+				CategoryID: &id,
+				Name:       node.Name,
+				Count:      own.Count,
+				Amount:     own.Amount,
+				Children:   rollup(node.Children),
+			}
+			for _, child := range item.Children {
+				item.Count += child.Count
+				item.Amount += child.Amount
+			}
+			if item.Count == 0 {
+				continue
+			}
+			items = append(items, item)
+		}
+		return items
+	}
+
+	items := rollup(BuildCategoryTree(categories, nil))
+	named := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		named[category.Name] = true
+	}
+	for name, dist := range typeDistribution {
+		if !named[name] {
+			items = append(items, CategoryStatsItem{Name: name, Count: dist.Count, Amount: dist.Amount})
+		}
+	}
+
+	var finish func(items []CategoryStatsItem)
+	finish = func(items []CategoryStatsItem) {
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Amount != items[j].Amount {
+				return items[i].Amount > items[j].Amount
+			}
+			return items[i].Name < items[j].Name
+		})
+		for i := range items {
+			if totalCount > 0 {
+				items[i].Percentage = int(math.Round(float64(items[i].Count) * 100.0 / float64(totalCount)))
+			}
+			finish(items[i].Children)
+		}
+	}
+	finish(items)
+	return items
+}
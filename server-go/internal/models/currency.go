@@ -0,0 +1,111 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultBaseCurrency 家庭默认本位币
+const DefaultBaseCurrency = "CNY"
+
+// currencyCodePattern ISO 4217 三位字母币种代码
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency 去除空白并转为大写，不校验格式
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidateCurrency 验证币种代码，须为三位大写字母，如CNY、USD
+func ValidateCurrency(code string) error {
+	if !currencyCodePattern.MatchString(code) {
+		return fmt.Errorf("无效的币种代码: %s，应为三位字母，如CNY、USD", code)
+	}
+	return nil
+}
+
+// ExchangeRate 汇率，表示 Date 当天 1 单位 FromCurrency 可兑换 Rate 单位 ToCurrency；
+// 汇率表不区分家庭，由管理员导入
+type ExchangeRate struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Date         string    `json:"date" gorm:"type:string;not null;uniqueIndex:idx_exchange_rate_date_pair,priority:1"`
+	FromCurrency string    `json:"fromCurrency" gorm:"type:string;not null;uniqueIndex:idx_exchange_rate_date_pair,priority:2"`
+	ToCurrency   string    `json:"toCurrency" gorm:"type:string;not null;uniqueIndex:idx_exchange_rate_date_pair,priority:3"`
+	Rate         float64   `json:"rate" gorm:"type:float;not null"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// Normalize 规范化币种代码
+func (r *ExchangeRate) Normalize() {
+	r.FromCurrency = NormalizeCurrency(r.FromCurrency)
+	r.ToCurrency = NormalizeCurrency(r.ToCurrency)
+	r.Date = strings.TrimSpace(r.Date)
+}
+
+// Validate 验证字段
+func (r *ExchangeRate) Validate() error {
+	if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+		return errors.New("汇率日期格式错误，应为yyyy-mm-dd格式")
+	}
+	if err := ValidateCurrency(r.FromCurrency); err != nil {
+		return err
+	}
+	if err := ValidateCurrency(r.ToCurrency); err != nil {
+		return err
+	}
+	if r.FromCurrency == r.ToCurrency {
+		return errors.New("原币种和目标币种不能相同")
+	}
+	if r.Rate <= 0 {
+		return errors.New("汇率必须大于0")
+	}
+	return nil
+}
+
+// ExchangeRateRowError 汇率导入中被拒绝的行
+type ExchangeRateRowError struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// ExchangeRateImportReport 汇率导入结果，同一日期和币种对已有汇率时覆盖原汇率
+type ExchangeRateImportReport struct {
+	Total    int                    `json:"total"`
+	Imported int                    `json:"imported"`
+	Rejected int                    `json:"rejected"`
+	Errors   []ExchangeRateRowError `json:"errors"`
+}
+
+// BaseAmountExpr 将 expenses.amount 折算为本位币的SQL表达式：币种为空或等于本位币时不折算，
+// 否则使用消费日期当天或之前最近一天的汇率，优先使用直接汇率，没有时使用反向汇率的倒数，
+// 折算结果四舍五入到分；没有可用汇率时表达式为NULL
+func BaseAmountExpr(base string) clause.Expr {
+	return baseAmountExpr("expenses.amount", base)
+}
+
+// SplitBaseAmountExpr 按所属消费记录的币种和日期将 expense_splits.amount 折算为本位币的SQL表达式，
+// 查询须关联 expenses 表，折算规则与 BaseAmountExpr 相同
+func SplitBaseAmountExpr(base string) clause.Expr {
+	return baseAmountExpr("expense_splits.amount", base)
+}
+
+// baseAmountExpr 按 expenses 表的币种和日期将金额列折算为本位币
+func baseAmountExpr(column, base string) clause.Expr {
+	return gorm.Expr("CASE WHEN expenses.currency IN ('', ?) THEN "+column+" ELSE CAST(ROUND("+column+" * COALESCE("+
+		"(SELECT r.rate FROM exchange_rates r WHERE r.from_currency = expenses.currency AND r.to_currency = ? "+
+		"AND r.date <= expenses.date ORDER BY r.date DESC LIMIT 1), "+
+		"(SELECT 1.0 / r.rate FROM exchange_rates r WHERE r.from_currency = ? AND r.to_currency = expenses.currency "+
+		"AND r.date <= expenses.date ORDER BY r.date DESC LIMIT 1))) AS INTEGER) END", base, base, base)
+}
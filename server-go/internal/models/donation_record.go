@@ -1,11 +1,98 @@
 package models
 
+import (
+	"errors"
+	"time"
+)
+
+// 捐赠记录状态
+const (
+	DonationPending  = "pending"
+	DonationSuccess  = "success"
+	DonationFailed   = "failed"
+	DonationRefunded = "refunded"
+)
+
 // DonationRecord 捐赠记录模型
 type DonationRecord struct {
-	ID        string  `json:"id"`
-	Username  string  `json:"username"`
-	Amount    float64 `json:"amount"`
-	Timestamp string  `json:"timestamp"`
-	OrderID   string  `json:"orderId"`
-	Status    string  `json:"status"`
-}
\ No newline at end of file
+	ID       string `json:"id" gorm:"type:varchar(64);primaryKey"`
+	Username string `json:"username" gorm:"type:varchar(255);not null;index"`
+	Amount   Money  `json:"amount" gorm:"type:integer;not null"`
+	// Timestamp 捐赠时间，格式为yyyy-mm-dd hh:mm:ss
+	Timestamp string `json:"timestamp" gorm:"type:varchar(32);not null;index"`
+	OrderID   string `json:"orderId" gorm:"type:varchar(255);index"`
+	Status    string `json:"status" gorm:"type:varchar(50);not null;default:'success';index"`
+}
+
+// TableName 指定表名
+func (DonationRecord) TableName() string {
+	return "donation_records"
+}
+
+// DonationQuery 捐赠记录查询条件
+type DonationQuery struct {
+	Username  string
+	Status    string
+	StartDate string
+	EndDate   string
+	MinAmount *Money
+	MaxAmount *Money
+	// ExcludeUsers 排除的用户，如测试账号
+	ExcludeUsers []string
+	Limit        int
+	Offset       int
+}
+
+// Validate 验证查询参数
+func (q *DonationQuery) Validate() error {
+	if q.Limit < 1 || q.Limit > 100 {
+		return errors.New("limit参数必须在1-100之间")
+	}
+	if q.Offset < 0 {
+		return errors.New("offset参数不能为负数")
+	}
+	switch q.Status {
+	case "", DonationPending, DonationSuccess, DonationFailed, DonationRefunded:
+	default:
+		return errors.New("无效的捐赠状态，可选值: pending、success、failed、refunded")
+	}
+	if q.StartDate != "" {
+		if _, err := time.Parse("2006-01-02", q.StartDate); err != nil {
+			return errors.New("开始日期格式错误，应为yyyy-mm-dd格式")
+		}
+	}
+	if q.EndDate != "" {
+		if _, err := time.Parse("2006-01-02", q.EndDate); err != nil {
+			return errors.New("结束日期格式错误，应为yyyy-mm-dd格式")
+		}
+	}
+	if q.StartDate != "" && q.EndDate != "" && q.StartDate > q.EndDate {
+		return errors.New("开始日期不能晚于结束日期")
+	}
+	if q.MinAmount != nil && q.MaxAmount != nil && *q.MinAmount > *q.MaxAmount {
+		return errors.New("最小金额不能大于最大金额")
+	}
+	return nil
+}
+
+// DonorTotal 单个用户的捐赠合计
+type DonorTotal struct {
+	Rank     int    `json:"rank,omitempty"`
+	Username string `json:"username"`
+	Amount   Money  `json:"amount"`
+	Count    int64  `json:"count"`
+}
+
+// DonationSummary 满足查询条件的捐赠汇总
+type DonationSummary struct {
+	Count       int64        `json:"count"`
+	TotalAmount Money        `json:"totalAmount"`
+	ByUser      []DonorTotal `json:"byUser"`
+}
+
+// DonationImportResult 捐赠记录导入结果
+type DonationImportResult struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+	Invalid    int `json:"invalid"`
+}
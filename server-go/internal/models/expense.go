@@ -11,11 +11,26 @@ import (
 
 // Expense 消费记录 - 与JS版本完全一致
 type Expense struct {
-	ID     uint    `json:"id" gorm:"primaryKey;autoIncrement"`
-	Type   string  `json:"type" gorm:"type:string;not null"`
-	Remark *string `json:"remark,omitempty" gorm:"type:string"`
-	Amount float64 `json:"amount" gorm:"type:float;not null"`
-	Date   string  `json:"date" gorm:"type:string;not null;index"`
+	ID        uint    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type      string  `json:"type" gorm:"type:string;not null"`
+	Remark    *string `json:"remark,omitempty" gorm:"type:string"`
+	Amount    Money   `json:"amount" gorm:"type:integer;not null"`
+	Date      string  `json:"date" gorm:"type:string;not null;index;uniqueIndex:idx_expense_recurring_date,priority:2"`
+	AccountID *uint   `json:"accountId,omitempty" gorm:"index"`
+	// RecurringID 由周期模板生成的记录所属模板ID，与Date组成唯一索引防止重复生成
+	RecurringID *uint `json:"recurringId,omitempty" gorm:"uniqueIndex:idx_expense_recurring_date,priority:1"`
+	// HouseholdID 所属家庭，只有该家庭的成员可以访问
+	HouseholdID *uint `json:"householdId,omitempty" gorm:"index"`
+	// Tags 标签名称，保存在 expense_tags 关联表中，由仓库负责读写
+	Tags []string `json:"tags,omitempty" gorm:"-"`
+	// PayerID 付款成员，为空表示没有记录付款人
+	PayerID *string `json:"payerId,omitempty" gorm:"type:string;index"`
+	// SplitMode 分摊方式：equal、percentage、exact，Splits 为空时不分摊
+	SplitMode string `json:"splitMode,omitempty" gorm:"type:string;not null;default:''"`
+	// Splits 参与人分摊，保存在 expense_splits 表中，由仓库负责读写
+	Splits []ExpenseSplit `json:"splits,omitempty" gorm:"-"`
+	// Currency 币种代码，为空表示家庭本位币，新建记录时由仓库填入本位币
+	Currency string `json:"currency,omitempty" gorm:"type:string;not null;default:''"`
 }
 
 // TableName 指定表名
@@ -32,9 +47,28 @@ type ExpenseQuery struct {
 	EndDate   string   `form:"endDate"`
 	MinAmount *float64 `form:"minAmount"`
 	MaxAmount *float64 `form:"maxAmount"`
-	Limit     int      `form:"limit,default=20"`
-	Offset    int      `form:"offset,default=0"`
-	Sort      string   `form:"sort,default=dateDesc"`
+	AccountID *uint    `form:"accountId"`
+	// Tags 标签筛选，TagMode 为 any 时包含任意一个标签即可，为 all 时必须包含全部标签
+	Tags    []string `form:"tags"`
+	TagMode string   `form:"tagMode"`
+	Limit   int      `form:"limit,default=20"`
+	Offset  int      `form:"offset,default=0"`
+	Sort    string   `form:"sort,default=dateDesc"`
+}
+
+// ExpensePatch 消费记录部分更新参数，未提供的字段保持不变
+type ExpensePatch struct {
+	Type      *string   `json:"type"`
+	Remark    *string   `json:"remark"`
+	Amount    *Money    `json:"amount"`
+	Date      *string   `json:"date"`
+	AccountID *uint     `json:"accountId"`
+	Tags      *[]string `json:"tags"`
+	// PayerID 传空字符串表示清除付款人和分摊
+	PayerID   *string         `json:"payerId"`
+	SplitMode *string         `json:"splitMode"`
+	Splits    *[]ExpenseSplit `json:"splits"`
+	Currency  *string         `json:"currency"`
 }
 
 // ExpenseMeta 元数据
@@ -46,19 +80,28 @@ type ExpenseMeta struct {
 // ExpenseStats 消费统计 - 与JS版本完全兼容
 type ExpenseStats struct {
 	Count            int                             `json:"count" binding:"required"`
-	TotalAmount      float64                         `json:"totalAmount" binding:"required"`
+	TotalAmount      Money                           `json:"totalAmount" binding:"required"`
 	AverageAmount    float64                         `json:"averageAmount" binding:"required"`
-	MedianAmount     float64                         `json:"medianAmount" binding:"required"`
-	MinAmount        float64                         `json:"minAmount" binding:"required"`
-	MaxAmount        float64                         `json:"maxAmount" binding:"required"`
+	MedianAmount     Money                           `json:"medianAmount" binding:"required"`
+	MinAmount        Money                           `json:"minAmount" binding:"required"`
+	MaxAmount        Money                           `json:"maxAmount" binding:"required"`
 	TypeDistribution map[string]TypeDistributionItem `json:"typeDistribution" binding:"required"`
+	// TagDistribution 按标签统计，一条记录可带多个标签，各标签占比之和可能超过100
+	TagDistribution map[string]TypeDistributionItem `json:"tagDistribution"`
+	// CategoryDistribution 按分类层级汇总，上级分类包含下级分类的金额和笔数
+	CategoryDistribution []CategoryStatsItem `json:"categoryDistribution"`
+	// BaseCurrency 金额统计使用的本位币，其他币种按消费日期的汇率折算
+	BaseCurrency string `json:"baseCurrency"`
+	// UnconvertedCount 缺少汇率无法折算、未计入统计的记录数，MissingCurrencies 为缺少汇率的币种
+	UnconvertedCount  int      `json:"unconvertedCount"`
+	MissingCurrencies []string `json:"missingCurrencies"`
 }
 
 // TypeDistributionItem 类型分布统计项
 type TypeDistributionItem struct {
-	Count      int     `json:"count"`
-	Amount     float64 `json:"amount"`
-	Percentage int     `json:"percentage"`
+	Count      int   `json:"count"`
+	Amount     Money `json:"amount"`
+	Percentage int   `json:"percentage"`
 }
 
 // Validate 验证字段
@@ -77,9 +120,71 @@ func (e *Expense) Validate() error {
 	if err != nil {
 		return errors.New("消费日期格式错误，应为yyyy-mm-dd格式")
 	}
+	if _, err := NormalizeTags(e.Tags); err != nil {
+		return err
+	}
+	if _, err := e.SplitShares(); err != nil {
+		return err
+	}
+	if currency := NormalizeCurrency(e.Currency); currency != "" {
+		if err := ValidateCurrency(currency); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// ApplyTo 将部分更新应用到消费记录，remark传空字符串、accountId传0表示清空
+func (p *ExpensePatch) ApplyTo(e *Expense) {
+	if p.Type != nil {
+		e.Type = *p.Type
+	}
+	if p.Remark != nil {
+		if *p.Remark == "" {
+			e.Remark = nil
+		} else {
+			remark := *p.Remark
+			e.Remark = &remark
+		}
+	}
+	if p.Amount != nil {
+		e.Amount = *p.Amount
+	}
+	if p.Date != nil {
+		e.Date = *p.Date
+	}
+	if p.AccountID != nil {
+		if *p.AccountID == 0 {
+			e.AccountID = nil
+		} else {
+			accountID := *p.AccountID
+			e.AccountID = &accountID
+		}
+	}
+	if p.Tags != nil {
+		e.Tags = *p.Tags
+	}
+	if p.PayerID != nil {
+		if *p.PayerID == "" {
+			e.PayerID = nil
+			e.SplitMode = ""
+			e.Splits = nil
+		} else {
+			payerID := *p.PayerID
+			e.PayerID = &payerID
+		}
+	}
+	if p.SplitMode != nil {
+		e.SplitMode = *p.SplitMode
+	}
+	if p.Splits != nil {
+		e.Splits = *p.Splits
+	}
+	if p.Currency != nil {
+		e.Currency = *p.Currency
+	}
+}
+
 // ValidateQuery 验证查询参数
 func (q *ExpenseQuery) Validate() error {
 	// 验证排序参数
@@ -140,6 +245,11 @@ func (q *ExpenseQuery) Validate() error {
 		}
 	}
 
+	// 验证标签筛选方式
+	if err := ValidateTagMode(q.TagMode); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -177,14 +287,31 @@ func (q *ExpenseQuery) ApplyToQuery(db *gorm.DB) *gorm.DB {
 		db = db.Where("date <= ?", q.EndDate)
 	}
 	if q.MinAmount != nil {
-		db = db.Where("amount >= ?", *q.MinAmount)
+		db = db.Where("amount >= ?", NewMoney(*q.MinAmount))
 	}
 	if q.MaxAmount != nil {
-		db = db.Where("amount <= ?", *q.MaxAmount)
+		db = db.Where("amount <= ?", NewMoney(*q.MaxAmount))
+	}
+	if q.AccountID != nil {
+		db = db.Where("account_id = ?", *q.AccountID)
+	}
+	// 标签只属于消费记录，收入等其他表的查询忽略标签条件
+	if _, ok := db.Statement.Model.(*Expense); ok && len(q.Tags) > 0 {
+		db = q.applyTags(db)
 	}
 	return db
 }
 
+// applyTags 按标签筛选消费记录
+func (q *ExpenseQuery) applyTags(db *gorm.DB) *gorm.DB {
+	if q.TagMode == TagModeAll {
+		return db.Where("id IN (SELECT et.expense_id FROM expense_tags et JOIN tags t ON t.id = et.tag_id "+
+			"WHERE t.name IN ? GROUP BY et.expense_id HAVING COUNT(DISTINCT t.name) = ?)", q.Tags, len(q.Tags))
+	}
+	return db.Where("id IN (SELECT et.expense_id FROM expense_tags et JOIN tags t ON t.id = et.tag_id "+
+		"WHERE t.name IN ?)", q.Tags)
+}
+
 // ApplySort 应用排序
 func (q *ExpenseQuery) ApplySort(db *gorm.DB) *gorm.DB {
 	switch q.Sort {
@@ -202,112 +329,148 @@ func (q *ExpenseQuery) ApplySort(db *gorm.DB) *gorm.DB {
 }
 
 // GetStatsWithSQL 使用原生SQL获取统计数据 - 与JS版本完全兼容
-func GetStatsWithSQL(db *gorm.DB, query *ExpenseQuery) (*ExpenseStats, error) {
+// 汇总值、类型分布和中位数均在数据库中计算，不再加载全部记录；
+// 金额按消费日期的汇率折算为 base 本位币，缺少汇率的记录不计入统计，单独返回其数量和币种
+func GetStatsWithSQL(db *gorm.DB, query *ExpenseQuery, base string) (*ExpenseStats, error) {
 	stats := &ExpenseStats{
-		TypeDistribution: make(map[string]TypeDistributionItem),
-	}
-
-	// 构建SQL查询
-	sql := db.Model(&Expense{})
-
-	// 应用查询条件
-	query.ApplyToQuery(sql)
-
-	// 获取总金额和数量
-	var totalAmount float64
-	var count int64
-
-	if err := sql.Select("COALESCE(SUM(amount), 0)").Row().Scan(&totalAmount); err != nil {
-		return nil, fmt.Errorf("获取总金额失败: %w", err)
-	}
-	stats.TotalAmount = totalAmount
-
-	if err := sql.Count(&count).Error; err != nil {
-		return nil, fmt.Errorf("获取总数失败: %w", err)
-	}
-	stats.Count = int(count)
-
-	if count > 0 {
-		stats.AverageAmount = stats.TotalAmount / float64(count)
+		TypeDistribution:  make(map[string]TypeDistributionItem),
+		TagDistribution:   make(map[string]TypeDistributionItem),
+		BaseCurrency:      base,
+		MissingCurrencies: []string{},
+	}
+
+	// converted 满足查询条件的记录及其折算金额，每次查询都基于新的查询构建器
+	converted := func() *gorm.DB {
+		return query.ApplyToQuery(db.Model(&Expense{})).
+			Select("expenses.id, expenses.type, expenses.currency, ? AS base_amount", BaseAmountExpr(base))
+	}
+	from := func() *gorm.DB {
+		return db.Session(&gorm.Session{NewDB: true}).Table("(?) AS converted", converted())
+	}
+
+	// 统计缺少汇率的记录
+	var missingRows []struct {
+		Currency string
+		Count    int
+	}
+	if err := from().
+		Select("currency, COUNT(*) AS count").
+		Where("base_amount IS NULL").
+		Group("currency").
+		Order("currency ASC").
+		Scan(&missingRows).Error; err != nil {
+		return nil, fmt.Errorf("获取缺少汇率的记录失败: %w", err)
+	}
+	for _, row := range missingRows {
+		stats.UnconvertedCount += row.Count
+		stats.MissingCurrencies = append(stats.MissingCurrencies, row.Currency)
+	}
+
+	// 获取数量、总金额、最小值和最大值
+	var totals struct {
+		Count       int64
+		TotalAmount Money
+		MinAmount   Money
+		MaxAmount   Money
+	}
+	if err := from().
+		Select("COUNT(*) AS count, COALESCE(SUM(base_amount), 0) AS total_amount, " +
+			"COALESCE(MIN(base_amount), 0) AS min_amount, COALESCE(MAX(base_amount), 0) AS max_amount").
+		Where("base_amount IS NOT NULL").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("获取汇总数据失败: %w", err)
+	}
+
+	stats.Count = int(totals.Count)
+	stats.TotalAmount = totals.TotalAmount
+	if totals.Count == 0 {
+		// 空数据时中位数、最大值和最小值均为0
+		return stats, nil
 	}
+	stats.AverageAmount = totals.TotalAmount.Float64() / float64(totals.Count)
+	stats.MinAmount = totals.MinAmount
+	stats.MaxAmount = totals.MaxAmount
 
-	// 获取所有记录用于统计（适用于小数据集）
-	var allExpenses []Expense
-	if err := query.ApplyToQuery(db.Model(&Expense{})).Find(&allExpenses).Error; err != nil {
-		return nil, fmt.Errorf("获取消费记录失败: %w", err)
+	// 计算中位数：按金额排序后只取中间的一到两条记录
+	median, err := medianAmount(from, totals.Count)
+	if err != nil {
+		return nil, err
 	}
+	stats.MedianAmount = median
 
-	if len(allExpenses) == 0 {
-		// 空数据时设置默认值
-		stats.MedianAmount = 0
-		stats.MinAmount = 0
-		stats.MaxAmount = 0
-		return stats, nil
+	// 构建类型分布统计 - 与JS版本完全一致
+	var typeRows []struct {
+		Type   string
+		Count  int
+		Amount Money
+	}
+	if err := from().
+		Select("type, COUNT(*) AS count, COALESCE(SUM(base_amount), 0) AS amount").
+		Where("base_amount IS NOT NULL").
+		Group("type").
+		Scan(&typeRows).Error; err != nil {
+		return nil, fmt.Errorf("获取类型分布失败: %w", err)
+	}
+
+	for _, row := range typeRows {
+		stats.TypeDistribution[row.Type] = TypeDistributionItem{
+			Count:      row.Count,
+			Amount:     row.Amount,
+			Percentage: int(math.Round(float64(row.Count) * 100.0 / float64(totals.Count))),
+		}
 	}
 
-	// 计算最大值和最小值
-	minAmount := allExpenses[0].Amount
-	maxAmount := allExpenses[0].Amount
-
-	// 按类型统计
-	typeMap := make(map[string][]float64)
-	for _, expense := range allExpenses {
-		// 更新最大值和最小值
-		if expense.Amount < minAmount {
-			minAmount = expense.Amount
-		}
-		if expense.Amount > maxAmount {
-			maxAmount = expense.Amount
+	// 构建标签分布统计，关联表没有家庭列，满足条件的记录通过子查询限定
+	var tagRows []struct {
+		Tag    string
+		Count  int
+		Amount Money
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Table("expense_tags").
+		Select("tags.name AS tag, COUNT(*) AS count, COALESCE(SUM(converted.base_amount), 0) AS amount").
+		Joins("JOIN tags ON tags.id = expense_tags.tag_id").
+		Joins("JOIN (?) AS converted ON converted.id = expense_tags.expense_id", converted()).
+		Where("converted.base_amount IS NOT NULL").
+		Group("tags.name").
+		Scan(&tagRows).Error; err != nil {
+		return nil, fmt.Errorf("获取标签分布失败: %w", err)
+	}
+
+	for _, row := range tagRows {
+		stats.TagDistribution[row.Tag] = TypeDistributionItem{
+			Count:      row.Count,
+			Amount:     row.Amount,
+			Percentage: int(math.Round(float64(row.Count) * 100.0 / float64(totals.Count))),
 		}
-
-		typeMap[expense.Type] = append(typeMap[expense.Type], expense.Amount)
 	}
 
-	stats.MinAmount = minAmount
-	stats.MaxAmount = maxAmount
+	return stats, nil
+}
 
-	// 计算中位数
-	var amounts []float64
-	for _, expense := range allExpenses {
-		amounts = append(amounts, expense.Amount)
+// medianAmount 使用有序OFFSET获取折算金额的中位数，count为可折算的记录数
+func medianAmount(from func() *gorm.DB, count int64) (Money, error) {
+	offset, limit := int((count-1)/2), 1
+	if count%2 == 0 {
+		limit = 2
 	}
 
-	// 排序
-	for i := 0; i < len(amounts); i++ {
-		for j := i + 1; j < len(amounts); j++ {
-			if amounts[i] > amounts[j] {
-				amounts[i], amounts[j] = amounts[j], amounts[i]
-			}
-		}
+	var amounts []Money
+	if err := from().
+		Where("base_amount IS NOT NULL").
+		Order("base_amount ASC").
+		Offset(offset).
+		Limit(limit).
+		Pluck("base_amount", &amounts).Error; err != nil {
+		return 0, fmt.Errorf("获取中位数失败: %w", err)
 	}
-
-	// 计算中位数
-	if len(amounts) > 0 {
-		if len(amounts)%2 == 0 {
-			stats.MedianAmount = (amounts[len(amounts)/2-1] + amounts[len(amounts)/2]) / 2
-		} else {
-			stats.MedianAmount = amounts[len(amounts)/2]
-		}
+	if len(amounts) == 0 {
+		return 0, nil
 	}
 
-	// 构建类型分布统计 - 与JS版本完全一致
-	for expenseType, amounts := range typeMap {
-		var typeTotal float64
-		for _, amount := range amounts {
-			typeTotal += amount
-		}
-
-		percentage := 0
-		if int(count) > 0 && len(amounts) > 0 {
-			percentage = int(math.Round(float64(len(amounts)) * 100.0 / float64(count)))
-		}
-
-		stats.TypeDistribution[expenseType] = TypeDistributionItem{
-			Count:      len(amounts),
-			Amount:     typeTotal,
-			Percentage: percentage,
-		}
+	var sum Money
+	for _, amount := range amounts {
+		sum += amount
 	}
-
-	return stats, nil
+	return Money(math.Round(float64(sum) / float64(len(amounts)))), nil
 }
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// benchmarkExpenseCount 统计基准测试写入的消费记录数
+const benchmarkExpenseCount = 500000
+
+// seedBenchmarkExpenses 写入 benchmarkExpenseCount 条消费记录：8 种类型分布在两年内，
+// 每 10 条中有 1 条美元消费并打上标签，美元按月提供汇率
+func seedBenchmarkExpenses(b *testing.B, db *gorm.DB) {
+	b.Helper()
+
+	if err := db.AutoMigrate(&Expense{}, &ExchangeRate{}, &Tag{}, &ExpenseTag{}); err != nil {
+		b.Fatalf("迁移数据表失败: %v", err)
+	}
+
+	rates := make([]ExchangeRate, 0, 24)
+	for month := 0; month < 24; month++ {
+		rates = append(rates, ExchangeRate{
+			Date:         fmt.Sprintf("%d-%02d-01", 2025+month/12, month%12+1),
+			FromCurrency: "USD",
+			ToCurrency:   "CNY",
+			Rate:         7 + float64(month)/100,
+		})
+	}
+	if err := db.Create(&rates).Error; err != nil {
+		b.Fatalf("写入汇率失败: %v", err)
+	}
+
+	tags := make([]Tag, 0, 5)
+	for i := 1; i <= 5; i++ {
+		tags = append(tags, Tag{Name: fmt.Sprintf("tag%d", i)})
+	}
+	if err := db.Create(&tags).Error; err != nil {
+		b.Fatalf("写入标签失败: %v", err)
+	}
+
+	// 使用递归CTE在数据库内批量生成记录，避免逐条插入
+	if err := db.Exec("WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < ?) "+
+		"INSERT INTO expenses (type, amount, date, currency, split_mode) "+
+		"SELECT 'type' || (n % 8), (n * 37) % 100000 + 1, date('2025-01-01', '+' || (n % 730) || ' days'), "+
+		"CASE WHEN n % 10 = 0 THEN 'USD' ELSE 'CNY' END, '' FROM seq", benchmarkExpenseCount).Error; err != nil {
+		b.Fatalf("写入消费记录失败: %v", err)
+	}
+	if err := db.Exec("INSERT INTO expense_tags (expense_id, tag_id) " +
+		"SELECT id, 1 + (id / 10) % 5 FROM expenses WHERE id % 10 = 0").Error; err != nil {
+		b.Fatalf("写入消费标签失败: %v", err)
+	}
+}
+
+func BenchmarkGetStatsWithSQL(b *testing.B) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(b.TempDir(), "bench.sqlite")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		b.Fatalf("打开数据库失败: %v", err)
+	}
+	seedBenchmarkExpenses(b, db)
+
+	cases := []struct {
+		name  string
+		query ExpenseQuery
+	}{
+		{name: "all", query: ExpenseQuery{}},
+		{name: "month", query: ExpenseQuery{Month: "2025-06"}},
+		{name: "type", query: ExpenseQuery{Type: "type3"}},
+		{name: "tag", query: ExpenseQuery{Tags: []string{"tag2"}}},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				query := tc.query
+				if _, err := GetStatsWithSQL(db, &query, "CNY"); err != nil {
+					b.Fatalf("获取统计失败: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// 预测参数
+const (
+	// ForecastConfidence 置信区间的置信水平
+	ForecastConfidence = 0.95
+	// forecastZ 95%置信水平对应的正态分布分位数
+	forecastZ = 1.96
+	// forecastSeasonalMinMonths 计算月份季节性所需的最少历史月数
+	forecastSeasonalMinMonths = 12
+	// forecastVolatilityMonths 估计波动时使用的最近月数
+	forecastVolatilityMonths = 12
+)
+
+// ForecastCategory 单个消费类型在某月的预测值
+type ForecastCategory struct {
+	Type     string  `json:"type"`
+	Expected float64 `json:"expected"`
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+}
+
+// ForecastMonth 某月的预测结果，总额为各类型之和，区间按各类型相互独立合并
+type ForecastMonth struct {
+	Month      string             `json:"month"`
+	Expected   float64            `json:"expected"`
+	Lower      float64            `json:"lower"`
+	Upper      float64            `json:"upper"`
+	Categories []ForecastCategory `json:"categories"`
+}
+
+// ExpenseForecast 消费预测结果
+type ExpenseForecast struct {
+	Months      int             `json:"months"`
+	Window      int             `json:"window"`
+	Confidence  float64         `json:"confidence"`
+	HistoryFrom string          `json:"historyFrom"`
+	HistoryTo   string          `json:"historyTo"`
+	Forecast    []ForecastMonth `json:"forecast"`
+}
+
+// BuildForecast 根据按月、按类型汇总的历史支出预测未来各月支出
+// history为 类型 -> 月份(YYYY-MM) -> 金额，historyMonths为按时间排序的完整历史月份（缺失月份视为0）
+// 每个类型先用月份季节性指数去除季节影响，再取最近window个月的移动平均作为基线，
+// 预测值 = 基线 × 目标月份的季节性指数；区间为 ± z × 去季节后最近月份的标准差 × 季节性指数
+func BuildForecast(history map[string]map[string]float64, historyMonths, targetMonths []string, window int) []ForecastMonth {
+	type categoryModel struct {
+		baseline float64
+		stddev   float64
+		seasonal map[time.Month]float64
+	}
+
+	modelsByType := make(map[string]categoryModel, len(history))
+	for expenseType, amounts := range history {
+		series := make([]float64, len(historyMonths))
+		for i, month := range historyMonths {
+			series[i] = amounts[month]
+		}
+
+		seasonal := seasonalIndex(series, historyMonths)
+		deseasonalized := make([]float64, 0, len(series))
+		for i, month := range historyMonths {
+			index := seasonal[monthOf(month)]
+			if index > 0 {
+				deseasonalized = append(deseasonalized, series[i]/index)
+			}
+		}
+
+		baseline := mean(series)
+		if len(deseasonalized) > 0 {
+			baseline = mean(tail(deseasonalized, window))
+		}
+
+		modelsByType[expenseType] = categoryModel{
+			baseline: baseline,
+			stddev:   stddev(tail(deseasonalized, forecastVolatilityMonths)),
+			seasonal: seasonal,
+		}
+	}
+
+	forecast := make([]ForecastMonth, 0, len(targetMonths))
+	for _, month := range targetMonths {
+		item := ForecastMonth{
+			Month:      month,
+			Categories: make([]ForecastCategory, 0, len(modelsByType)),
+		}
+
+		var variance float64
+		for expenseType, model := range modelsByType {
+			index := model.seasonal[monthOf(month)]
+			expected := model.baseline * index
+			margin := forecastZ * model.stddev * index
+
+			item.Categories = append(item.Categories, ForecastCategory{
+				Type:     expenseType,
+				Expected: roundForecast(expected),
+				Lower:    roundForecast(math.Max(expected-margin, 0)),
+				Upper:    roundForecast(expected + margin),
+			})
+			item.Expected += expected
+			variance += (model.stddev * index) * (model.stddev * index)
+		}
+
+		margin := forecastZ * math.Sqrt(variance)
+		item.Lower = roundForecast(math.Max(item.Expected-margin, 0))
+		item.Upper = roundForecast(item.Expected + margin)
+		item.Expected = roundForecast(item.Expected)
+
+		sort.Slice(item.Categories, func(i, j int) bool {
+			if item.Categories[i].Expected != item.Categories[j].Expected {
+				return item.Categories[i].Expected > item.Categories[j].Expected
+			}
+			return item.Categories[i].Type < item.Categories[j].Type
+		})
+		forecast = append(forecast, item)
+	}
+
+	return forecast
+}
+
+// seasonalIndex 计算各月份的季节性指数（该月平均支出 / 全部月份平均支出）
+// 历史不足一年或没有支出时所有月份的指数均为1
+func seasonalIndex(series []float64, months []string) map[time.Month]float64 {
+	index := make(map[time.Month]float64, 12)
+	for month := time.January; month <= time.December; month++ {
+		index[month] = 1
+	}
+
+	overall := mean(series)
+	if len(series) < forecastSeasonalMinMonths || overall == 0 {
+		return index
+	}
+
+	sums := make(map[time.Month]float64, 12)
+	counts := make(map[time.Month]int, 12)
+	for i, month := range months {
+		sums[monthOf(month)] += series[i]
+		counts[monthOf(month)]++
+	}
+	for month, count := range counts {
+		index[month] = sums[month] / float64(count) / overall
+	}
+	return index
+}
+
+// monthOf 返回YYYY-MM字符串对应的月份
+func monthOf(month string) time.Month {
+	t, err := time.Parse("2006-01", month)
+	if err != nil {
+		return 0
+	}
+	return t.Month()
+}
+
+// tail 返回切片的最后n个元素
+func tail(values []float64, n int) []float64 {
+	if n <= 0 || len(values) <= n {
+		return values
+	}
+	return values[len(values)-n:]
+}
+
+// mean 计算平均值，空切片返回0
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// stddev 计算样本标准差，少于两个值时返回0
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	avg := mean(values)
+	var sum float64
+	for _, value := range values {
+		sum += (value - avg) * (value - avg)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// roundForecast 预测金额保留两位小数
+func roundForecast(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
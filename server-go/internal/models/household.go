@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Household 家庭，账本数据按家庭隔离，成员通过邀请码加入
+type Household struct {
+	ID         uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name       string `json:"name" gorm:"type:string;not null"`
+	InviteCode string `json:"inviteCode" gorm:"type:string;not null;uniqueIndex"`
+	// BaseCurrency 本位币，统计时其他币种的消费按汇率折算为本位币
+	BaseCurrency string    `json:"baseCurrency" gorm:"type:string;not null;default:'CNY'"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (Household) TableName() string {
+	return "households"
+}
+
+// Validate 验证字段
+func (h *Household) Validate() error {
+	if strings.TrimSpace(h.Name) == "" {
+		return errors.New("家庭名称不能为空")
+	}
+	if h.InviteCode == "" {
+		return errors.New("邀请码不能为空")
+	}
+	if h.BaseCurrency != "" {
+		if err := ValidateCurrency(h.BaseCurrency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AuthSession 登录会话，只保存令牌的SHA-256摘要
+type AuthSession struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TokenHash string    `json:"-" gorm:"type:string;not null;uniqueIndex"`
+	MemberID  string    `json:"memberId" gorm:"type:string;not null;index"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"not null;index"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 指定表名
+func (AuthSession) TableName() string {
+	return "auth_sessions"
+}
+
+// IsExpired 会话是否已过期
+func (s *AuthSession) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// MemberClaim 尚未设置密码的会员的一次性认领码，由家庭所有者或系统管理员生成，
+// 只保存认领码的SHA-256摘要，使用后即删除
+type MemberClaim struct {
+	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	MemberID string `json:"memberId" gorm:"type:string;not null;uniqueIndex"`
+	CodeHash string `json:"-" gorm:"type:string;not null;uniqueIndex"`
+	// HouseholdID 认领后会员所属的家庭
+	HouseholdID uint      `json:"householdId" gorm:"not null"`
+	CreatedBy   string    `json:"createdBy" gorm:"type:string;not null"`
+	ExpiresAt   time.Time `json:"expiresAt" gorm:"not null"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// TableName 指定表名
+func (MemberClaim) TableName() string {
+	return "member_claims"
+}
+
+// IsExpired 认领码是否已过期
+func (c *MemberClaim) IsExpired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// ClaimCodeResult 生成认领码的结果，认领码只在生成时返回一次
+type ClaimCodeResult struct {
+	Username  string    `json:"username"`
+	ClaimCode string    `json:"claimCode"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AuthResult 注册或登录成功后返回的令牌信息
+type AuthResult struct {
+	Token     string     `json:"token"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	Member    *Member    `json:"member"`
+	Household *Household `json:"household"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Income 收入记录 - 字段与Expense保持一致，以便复用ExpenseQuery的筛选语法
+type Income struct {
+	ID        uint    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type      string  `json:"type" gorm:"type:string;not null"`
+	Remark    *string `json:"remark,omitempty" gorm:"type:string"`
+	Amount    Money   `json:"amount" gorm:"type:integer;not null"`
+	Date      string  `json:"date" gorm:"type:string;not null;index"`
+	AccountID *uint   `json:"accountId,omitempty" gorm:"index"`
+	// HouseholdID 所属家庭，只有该家庭的成员可以访问
+	HouseholdID *uint `json:"householdId,omitempty" gorm:"index"`
+}
+
+// TableName 指定表名
+func (Income) TableName() string {
+	return "incomes"
+}
+
+// Validate 验证字段
+func (i *Income) Validate() error {
+	if i.Type == "" {
+		return errors.New("收入类型不能为空")
+	}
+	if i.Amount <= 0 {
+		return errors.New("收入金额必须大于0")
+	}
+	if i.Date == "" {
+		return errors.New("收入日期不能为空")
+	}
+	// 验证日期格式是否为yyyy-mm-dd
+	if _, err := time.Parse("2006-01-02", i.Date); err != nil {
+		return errors.New("收入日期格式错误，应为yyyy-mm-dd格式")
+	}
+	return nil
+}
+
+// CashflowItem 单个周期的现金流
+type CashflowItem struct {
+	Period  string `json:"period"`
+	Income  Money  `json:"income"`
+	Expense Money  `json:"expense"`
+	Net     Money  `json:"net"`
+}
+
+// CashflowReport 现金流报表
+type CashflowReport struct {
+	Granularity  string         `json:"granularity"`
+	From         string         `json:"from"`
+	To           string         `json:"to"`
+	TotalIncome  Money          `json:"totalIncome"`
+	TotalExpense Money          `json:"totalExpense"`
+	Net          Money          `json:"net"`
+	Items        []CashflowItem `json:"items"`
+}
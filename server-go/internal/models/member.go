@@ -12,6 +12,16 @@ type Member struct {
 	ID        string         `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	Username  string         `json:"username" gorm:"type:varchar(255);not null;uniqueIndex;comment:'用户名'"`
 	IsActive  bool           `json:"isActive" gorm:"default:false;comment:'是否激活'"`
+	// PasswordHash 登录密码的bcrypt摘要，为空表示尚未设置密码的旧会员
+	PasswordHash string `json:"-" gorm:"type:varchar(255)"`
+	// HouseholdID 所属家庭
+	HouseholdID *uint `json:"householdId,omitempty" gorm:"index"`
+	// Role 角色：owner、member、viewer、admin
+	Role string `json:"role" gorm:"type:varchar(20);not null;default:'member'"`
+	// Email 接收邮件通知的地址，为空时不发送邮件通知；只能通过通知设置接口查看
+	Email string `json:"-" gorm:"type:varchar(255)"`
+	// Locale 通知语言：zh、en，为空时使用默认语言
+	Locale string `json:"locale,omitempty" gorm:"type:varchar(10)"`
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -43,9 +53,17 @@ func (m *Member) BeforeCreate(tx *gorm.DB) error {
 	if m.UpdatedAt.IsZero() {
 		m.UpdatedAt = time.Now()
 	}
+	if m.Role == "" {
+		m.Role = RoleMember
+	}
 	return nil
 }
 
+// Can 会员是否拥有指定权限
+func (m *Member) Can(permission Permission) bool {
+	return HasPermission(m.Role, permission)
+}
+
 // BeforeUpdate 更新前钩子
 func (m *Member) BeforeUpdate(tx *gorm.DB) error {
 	m.UpdatedAt = time.Now()
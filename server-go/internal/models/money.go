@@ -0,0 +1,115 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money 金额，以最小货币单位（分）的整数保存，避免浮点数累加产生误差；
+// JSON中仍以元为单位的十进制数表示，兼容旧版客户端
+type Money int64
+
+// NewMoney 将以元为单位的金额四舍五入到分
+func NewMoney(amount float64) Money {
+	return Money(math.Round(amount * 100))
+}
+
+// ParseMoney 解析以元为单位的十进制金额，如 "12.34"，超过两位的小数四舍五入到分
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("金额格式错误: %s", s)
+	}
+	if math.Abs(amount) > math.MaxInt64/100 {
+		return 0, fmt.Errorf("金额超出范围: %s", s)
+	}
+	return NewMoney(amount), nil
+}
+
+// Float64 返回以元为单位的金额，用于预测等需要浮点运算的场景
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// String 返回保留两位小数的金额，如 "12.30"
+func (m Money) String() string {
+	sign, units, cents := m.parts()
+	return fmt.Sprintf("%s%d.%02d", sign, units, cents)
+}
+
+// MarshalJSON 序列化为以元为单位的十进制数，省略末尾的0，如 12.3、100
+func (m Money) MarshalJSON() ([]byte, error) {
+	sign, units, cents := m.parts()
+	switch {
+	case cents == 0:
+		return []byte(fmt.Sprintf("%s%d", sign, units)), nil
+	case cents%10 == 0:
+		return []byte(fmt.Sprintf("%s%d.%d", sign, units, cents/10)), nil
+	default:
+		return []byte(fmt.Sprintf("%s%d.%02d", sign, units, cents)), nil
+	}
+}
+
+// UnmarshalJSON 解析以元为单位的十进制数，也接受字符串形式的金额
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	amount, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
+
+// Scan 从数据库读取以分为单位的金额，汇率折算等表达式得到的小数四舍五入到分
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	case float64:
+		*m = Money(math.Round(v))
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为金额", value)
+	}
+	return nil
+}
+
+// Value 以分为单位写入数据库
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// scanString 解析数据库以文本返回的分
+func (m *Money) scanString(s string) error {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return fmt.Errorf("无法将 %q 转换为金额", s)
+	}
+	*m = Money(math.Round(v))
+	return nil
+}
+
+// parts 拆分为符号、元和分
+func (m Money) parts() (string, int64, int64) {
+	sign, v := "", int64(m)
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return sign, v / 100, v % 100
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// 通知类型
+const (
+	// NotificationSubscriptionExpiring 订阅即将到期
+	NotificationSubscriptionExpiring = "subscription_expiring"
+	// NotificationRenewalFailed 自动续费扣款失败
+	NotificationRenewalFailed = "renewal_failed"
+	// NotificationBudgetOverrun 预算超支
+	NotificationBudgetOverrun = "budget_overrun"
+)
+
+// 通知渠道
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelInbox   = "inbox"
+)
+
+// 通知发送状态
+const (
+	NotificationSent   = "sent"
+	NotificationFailed = "failed"
+)
+
+// 通知语言
+const (
+	LocaleZh = "zh"
+	LocaleEn = "en"
+)
+
+// ValidateLocale 验证通知语言
+func ValidateLocale(locale string) error {
+	switch locale {
+	case LocaleZh, LocaleEn:
+		return nil
+	default:
+		return errors.New("无效的语言，可选值: zh、en")
+	}
+}
+
+// NotificationRecord 通知发送记录，同一通知（DedupKey）在同一渠道对同一会员只成功发送一次，失败的下次重试
+type NotificationRecord struct {
+	ID uint `json:"id" gorm:"primaryKey;autoIncrement"`
+	// DedupKey 通知的去重键，如 subscription_expiring:<订阅ID>:<到期日>
+	DedupKey string `json:"dedupKey" gorm:"type:varchar(255);not null;uniqueIndex:idx_notification_dedup,priority:1"`
+	Channel  string `json:"channel" gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_dedup,priority:2"`
+	MemberID string `json:"memberId" gorm:"type:uuid;not null;uniqueIndex:idx_notification_dedup,priority:3"`
+	Username string `json:"username" gorm:"type:varchar(255);not null;index"`
+	Kind     string `json:"kind" gorm:"type:varchar(50);not null;index"`
+	Locale   string `json:"locale" gorm:"type:varchar(10);not null"`
+	Subject  string `json:"subject" gorm:"type:varchar(255);not null"`
+	Status   string `json:"status" gorm:"type:varchar(20);not null;index"`
+	Error    string `json:"error,omitempty" gorm:"type:text"`
+	// Attempts 发送次数，包括失败的次数
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	SentAt    *time.Time `json:"sentAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (NotificationRecord) TableName() string {
+	return "notification_records"
+}
+
+// InboxMessage 站内信
+type InboxMessage struct {
+	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	MemberID string `json:"memberId" gorm:"type:uuid;not null;index"`
+	Kind     string `json:"kind" gorm:"type:varchar(50);not null"`
+	Subject  string `json:"subject" gorm:"type:varchar(255);not null"`
+	Body     string `json:"body" gorm:"type:text;not null"`
+	// ReadAt 阅读时间，为空表示未读
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName 指定表名
+func (InboxMessage) TableName() string {
+	return "inbox_messages"
+}
+
+// NotificationQuery 通知发送记录查询条件
+type NotificationQuery struct {
+	Username string
+	Kind     string
+	Channel  string
+	Status   string
+	Limit    int
+	Offset   int
+}
+
+// Validate 验证查询参数
+func (q *NotificationQuery) Validate() error {
+	if q.Limit < 1 || q.Limit > 100 {
+		return errors.New("limit参数必须在1-100之间")
+	}
+	if q.Offset < 0 {
+		return errors.New("offset参数不能为负数")
+	}
+	switch q.Kind {
+	case "", NotificationSubscriptionExpiring, NotificationRenewalFailed, NotificationBudgetOverrun:
+	default:
+		return errors.New("无效的通知类型，可选值: subscription_expiring、renewal_failed、budget_overrun")
+	}
+	switch q.Channel {
+	case "", NotificationChannelEmail, NotificationChannelWebhook, NotificationChannelInbox:
+	default:
+		return errors.New("无效的通知渠道，可选值: email、webhook、inbox")
+	}
+	switch q.Status {
+	case "", NotificationSent, NotificationFailed:
+	default:
+		return errors.New("无效的发送状态，可选值: sent、failed")
+	}
+	return nil
+}
+
+// NotificationSettings 会员的通知设置
+type NotificationSettings struct {
+	// Email 接收邮件通知的地址，为空时不发送邮件
+	Email *string `json:"email"`
+	// Locale 通知语言，zh或en
+	Locale *string `json:"locale"`
+}
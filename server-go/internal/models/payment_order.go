@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 支付订单状态
+const (
+	PaymentOrderPending  = "pending"
+	PaymentOrderPaid     = "paid"
+	PaymentOrderFailed   = "failed"
+	PaymentOrderRefunded = "refunded"
+)
+
+// 支付订单类型
+const (
+	PaymentKindDonation     = "donation"
+	PaymentKindSubscription = "subscription"
+)
+
+// PaymentOrder 支付订单，创建支付时为pending，由支付网关回调改为paid或failed
+type PaymentOrder struct {
+	ID string `json:"id" gorm:"type:uuid;primaryKey"`
+	// OrderID 支付网关返回的订单号
+	OrderID string `json:"orderId" gorm:"type:varchar(255);not null;uniqueIndex"`
+	// IdempotencyKey 创建支付时使用的幂等键
+	IdempotencyKey string `json:"idempotencyKey" gorm:"type:varchar(255);index"`
+	Kind           string `json:"kind" gorm:"type:varchar(50);not null"`
+	Username       string `json:"username" gorm:"type:varchar(255);not null;index"`
+	MemberID       string `json:"memberId,omitempty" gorm:"type:uuid;index"`
+	PlanID         string `json:"planId,omitempty" gorm:"type:uuid"`
+	Amount         Money  `json:"amount" gorm:"type:integer;not null"`
+	Status         string `json:"status" gorm:"type:varchar(50);not null;default:'pending';index"`
+	// TransactionID 支付网关回调中的交易号
+	TransactionID string `json:"transactionId,omitempty" gorm:"type:varchar(255)"`
+	// SubscriptionID 支付成功后创建或延长的订阅，自动续费订单创建时即指定
+	SubscriptionID string `json:"subscriptionId,omitempty" gorm:"type:uuid;index"`
+	// Renewal 是否为自动续费扣款，支付成功后从订阅原结束时间起延长
+	Renewal bool       `json:"renewal" gorm:"not null;default:false"`
+	PaidAt  *time.Time `json:"paidAt,omitempty"`
+	// RefundedAmount 已退款金额
+	RefundedAmount Money     `json:"refundedAmount" gorm:"type:integer;not null;default:0"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (PaymentOrder) TableName() string {
+	return "payment_orders"
+}
+
+// BeforeCreate 创建前钩子
+func (o *PaymentOrder) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	if o.Status == "" {
+		o.Status = PaymentOrderPending
+	}
+	return nil
+}
+
+// ValidatePaymentOrderStatus 验证支付订单状态
+func ValidatePaymentOrderStatus(status string) error {
+	switch status {
+	case PaymentOrderPending, PaymentOrderPaid, PaymentOrderFailed, PaymentOrderRefunded:
+		return nil
+	default:
+		return fmt.Errorf("无效的订单状态: %s，可选值: pending、paid、failed、refunded", status)
+	}
+}
+
+// PaymentCallback 支付网关回调内容
+type PaymentCallback struct {
+	OrderID       string `json:"orderId" binding:"required"`
+	Status        string `json:"status" binding:"required"`
+	TransactionID string `json:"transactionId"`
+	Amount        Money  `json:"amount"`
+}
+
+// 退款方式
+const (
+	// RefundFull 全额退款，订阅扣除该订单购买的全部天数
+	RefundFull = "full"
+	// RefundProrated 按订阅剩余天数比例退款，订阅扣除退款对应的天数
+	RefundProrated = "prorated"
+	// RefundChargeback 支付网关通知的拒付，按全额退款处理
+	RefundChargeback = "chargeback"
+)
+
+// PaymentRefund 退款流水，每笔退款一条记录
+type PaymentRefund struct {
+	ID             string `json:"id" gorm:"type:uuid;primaryKey"`
+	PaymentOrderID string `json:"paymentOrderId" gorm:"type:uuid;not null;index"`
+	// OrderID 支付网关订单号
+	OrderID string `json:"orderId" gorm:"type:varchar(255);not null;index"`
+	// RefundID 支付网关退款单号
+	RefundID string `json:"refundId" gorm:"type:varchar(255)"`
+	Mode     string `json:"mode" gorm:"type:varchar(50);not null"`
+	Amount   Money  `json:"amount" gorm:"type:integer;not null"`
+	// Days 订阅扣除的天数，捐赠订单为0
+	Days           int       `json:"days"`
+	SubscriptionID string    `json:"subscriptionId,omitempty" gorm:"type:uuid"`
+	Reason         string    `json:"reason" gorm:"type:text"`
+	Operator       string    `json:"operator" gorm:"type:varchar(255)"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// TableName 指定表名
+func (PaymentRefund) TableName() string {
+	return "payment_refunds"
+}
+
+// BeforeCreate 创建前钩子
+func (r *PaymentRefund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ValidateRefundMode 验证退款方式
+func ValidateRefundMode(mode string) error {
+	switch mode {
+	case RefundFull, RefundProrated:
+		return nil
+	default:
+		return fmt.Errorf("无效的退款方式: %s，可选值: full、prorated", mode)
+	}
+}
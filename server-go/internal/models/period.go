@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// 统计周期粒度
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+	GranularityYear  = "year"
+)
+
+// ValidateGranularity 验证统计周期粒度
+func ValidateGranularity(granularity string) error {
+	switch granularity {
+	case GranularityDay, GranularityWeek, GranularityMonth, GranularityYear:
+		return nil
+	default:
+		return fmt.Errorf("无效的统计粒度: %s，可选值: day、week、month、year", granularity)
+	}
+}
+
+// PeriodExpr 返回按粒度对date字符串列分桶的SQL表达式（SQLite）
+// 周以周一为起始，桶键为该周周一的日期
+func PeriodExpr(granularity string) string {
+	switch granularity {
+	case GranularityDay:
+		return "date"
+	case GranularityWeek:
+		return "date(date, 'weekday 0', '-6 days')"
+	case GranularityYear:
+		return "SUBSTR(date, 1, 4)"
+	default:
+		return "SUBSTR(date, 1, 7)"
+	}
+}
+
+// PeriodKey 返回日期所在周期的桶键，与PeriodExpr的结果一致
+func PeriodKey(t time.Time, granularity string) string {
+	switch granularity {
+	case GranularityDay:
+		return t.Format("2006-01-02")
+	case GranularityWeek:
+		return weekStart(t).Format("2006-01-02")
+	case GranularityYear:
+		return t.Format("2006")
+	default:
+		return t.Format("2006-01")
+	}
+}
+
+// PeriodKeys 返回[from, to]范围内的所有周期桶键，用于补零
+func PeriodKeys(from, to time.Time, granularity string) []string {
+	var keys []string
+	if from.After(to) {
+		return keys
+	}
+
+	cursor := periodStart(from, granularity)
+	for !cursor.After(to) {
+		keys = append(keys, PeriodKey(cursor, granularity))
+		switch granularity {
+		case GranularityDay:
+			cursor = cursor.AddDate(0, 0, 1)
+		case GranularityWeek:
+			cursor = cursor.AddDate(0, 0, 7)
+		case GranularityYear:
+			cursor = cursor.AddDate(1, 0, 0)
+		default:
+			cursor = cursor.AddDate(0, 1, 0)
+		}
+	}
+	return keys
+}
+
+// periodStart 返回日期所在周期的起始日期
+func periodStart(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case GranularityWeek:
+		return weekStart(t)
+	case GranularityYear:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// weekStart 返回日期所在周的周一
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// 周期规则
+const (
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// RecurringExpense 周期性消费模板（房租、水电、订阅等），由后台任务按规则生成消费记录
+// 规则类似RRULE：每Interval周的DayOfWeek、每Interval月的DayOfMonth、每Interval年的MonthOfYear月DayOfMonth日
+// DayOfMonth大于当月天数时取当月最后一天
+type RecurringExpense struct {
+	ID          uint    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type        string  `json:"type" gorm:"type:string;not null"`
+	Remark      *string `json:"remark,omitempty" gorm:"type:string"`
+	Amount      Money   `json:"amount" gorm:"type:integer;not null"`
+	AccountID   *uint   `json:"accountId,omitempty"`
+	Frequency   string  `json:"frequency" gorm:"type:string;not null"`
+	Interval    int     `json:"interval" gorm:"not null;default:1"`
+	DayOfMonth  int     `json:"dayOfMonth"`
+	DayOfWeek   int     `json:"dayOfWeek"`
+	MonthOfYear int     `json:"monthOfYear"`
+	StartDate   string  `json:"startDate" gorm:"type:string;not null"`
+	EndDate     string  `json:"endDate" gorm:"type:string"`
+	Active      bool    `json:"active" gorm:"default:true"`
+	HouseholdID *uint   `json:"householdId,omitempty" gorm:"index"`
+	// LastGeneratedDate 最近一次已生成消费记录的日期，用于保证重启后不重复生成
+	LastGeneratedDate string    `json:"lastGeneratedDate" gorm:"type:string"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (RecurringExpense) TableName() string {
+	return "recurring_expenses"
+}
+
+// Validate 验证字段
+func (r *RecurringExpense) Validate() error {
+	if r.Type == "" {
+		return errors.New("消费类型不能为空")
+	}
+	if r.Amount <= 0 {
+		return errors.New("消费金额必须大于0")
+	}
+	if r.Interval < 1 {
+		return errors.New("间隔必须大于等于1")
+	}
+	start, err := time.Parse("2006-01-02", r.StartDate)
+	if err != nil {
+		return errors.New("开始日期格式错误，应为yyyy-mm-dd格式")
+	}
+	if r.EndDate != "" {
+		end, err := time.Parse("2006-01-02", r.EndDate)
+		if err != nil {
+			return errors.New("结束日期格式错误，应为yyyy-mm-dd格式")
+		}
+		if end.Before(start) {
+			return errors.New("开始日期不能晚于结束日期")
+		}
+	}
+
+	switch r.Frequency {
+	case FrequencyWeekly:
+		if r.DayOfWeek < 0 || r.DayOfWeek > 6 {
+			return errors.New("dayOfWeek必须在0-6之间（0为周日）")
+		}
+	case FrequencyMonthly:
+		if r.DayOfMonth < 1 || r.DayOfMonth > 31 {
+			return errors.New("dayOfMonth必须在1-31之间")
+		}
+	case FrequencyYearly:
+		if r.DayOfMonth < 1 || r.DayOfMonth > 31 {
+			return errors.New("dayOfMonth必须在1-31之间")
+		}
+		if r.MonthOfYear < 1 || r.MonthOfYear > 12 {
+			return errors.New("monthOfYear必须在1-12之间")
+		}
+	default:
+		return errors.New("无效的周期规则，可选值: weekly、monthly、yearly")
+	}
+	return nil
+}
+
+// Occurrences 返回晚于after且不晚于until的发生日期（yyyy-mm-dd），最多limit个
+// after为空表示从开始日期算起，limit<=0表示不限制数量
+func (r *RecurringExpense) Occurrences(after, until string, limit int) []string {
+	var dates []string
+
+	start, err := time.Parse("2006-01-02", r.StartDate)
+	if err != nil {
+		return dates
+	}
+	end, err := time.Parse("2006-01-02", until)
+	if err != nil {
+		return dates
+	}
+	if r.EndDate != "" {
+		if templateEnd, err := time.Parse("2006-01-02", r.EndDate); err == nil && templateEnd.Before(end) {
+			end = templateEnd
+		}
+	}
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	for k := 0; ; k++ {
+		var next time.Time
+		switch r.Frequency {
+		case FrequencyWeekly:
+			offset := (r.DayOfWeek - int(start.Weekday()) + 7) % 7
+			next = start.AddDate(0, 0, offset+7*interval*k)
+		case FrequencyMonthly:
+			first := time.Date(start.Year(), start.Month()+time.Month(interval*k), 1, 0, 0, 0, 0, time.UTC)
+			next = clampDay(first, r.DayOfMonth)
+		case FrequencyYearly:
+			first := time.Date(start.Year()+interval*k, time.Month(r.MonthOfYear), 1, 0, 0, 0, 0, time.UTC)
+			next = clampDay(first, r.DayOfMonth)
+		default:
+			return dates
+		}
+
+		if next.After(end) {
+			return dates
+		}
+		date := next.Format("2006-01-02")
+		if next.Before(start) || (after != "" && date <= after) {
+			continue
+		}
+
+		dates = append(dates, date)
+		if limit > 0 && len(dates) >= limit {
+			return dates
+		}
+	}
+}
+
+// ToExpense 按发生日期生成消费记录
+func (r *RecurringExpense) ToExpense(date string) Expense {
+	recurringID := r.ID
+	return Expense{
+		Type:        r.Type,
+		Remark:      r.Remark,
+		Amount:      r.Amount,
+		Date:        date,
+		AccountID:   r.AccountID,
+		RecurringID: &recurringID,
+		HouseholdID: r.HouseholdID,
+	}
+}
+
+// clampDay 返回first所在月份的第day天，超过当月天数时取最后一天
+func clampDay(first time.Time, day int) time.Time {
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
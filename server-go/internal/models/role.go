@@ -0,0 +1,56 @@
+package models
+
+import "fmt"
+
+// 会员角色
+const (
+	// RoleOwner 家庭所有者：读写账本并管理家庭成员和订阅
+	RoleOwner = "owner"
+	// RoleMember 家庭成员：读写账本
+	RoleMember = "member"
+	// RoleViewer 只读成员：只能查看账本
+	RoleViewer = "viewer"
+	// RoleAdmin 系统管理员：拥有全部权限，可访问管理、维护和日志接口
+	RoleAdmin = "admin"
+)
+
+// Permission 操作权限
+type Permission string
+
+// 操作权限
+const (
+	// PermissionReadLedger 查看账本和会员信息
+	PermissionReadLedger Permission = "ledger:read"
+	// PermissionWriteLedger 修改账本
+	PermissionWriteLedger Permission = "ledger:write"
+	// PermissionManageHousehold 管理家庭成员角色和订阅
+	PermissionManageHousehold Permission = "household:manage"
+	// PermissionAdmin 管理订阅计划、执行维护任务、查看和清理日志
+	PermissionAdmin Permission = "system:admin"
+)
+
+// rolePermissions 各角色拥有的权限
+var rolePermissions = map[string][]Permission{
+	RoleViewer: {PermissionReadLedger},
+	RoleMember: {PermissionReadLedger, PermissionWriteLedger},
+	RoleOwner:  {PermissionReadLedger, PermissionWriteLedger, PermissionManageHousehold},
+	RoleAdmin:  {PermissionReadLedger, PermissionWriteLedger, PermissionManageHousehold, PermissionAdmin},
+}
+
+// ValidateRole 验证角色
+func ValidateRole(role string) error {
+	if _, ok := rolePermissions[role]; !ok {
+		return fmt.Errorf("无效的角色: %s，可选值: owner、member、viewer、admin", role)
+	}
+	return nil
+}
+
+// HasPermission 角色是否拥有指定权限
+func HasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,325 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// 分摊方式
+const (
+	// SplitEqual 参与人平均分摊
+	SplitEqual = "equal"
+	// SplitPercentage 按百分比分摊，百分比之和为100
+	SplitPercentage = "percentage"
+	// SplitExact 按指定金额分摊，金额之和等于消费金额
+	SplitExact = "exact"
+)
+
+// splitTolerance 百分比之和、分摊金额之和允许的误差
+const splitTolerance = 0.005
+
+// ExpenseSplit 消费记录的参与人分摊，Amount 为该参与人应承担的金额
+type ExpenseSplit struct {
+	ExpenseID uint   `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	MemberID  string `json:"memberId" gorm:"primaryKey;type:string;index"`
+	// Percentage 按百分比分摊时该参与人的百分比
+	Percentage *float64 `json:"percentage,omitempty" gorm:"type:float"`
+	Amount     Money    `json:"amount" gorm:"type:integer;not null"`
+}
+
+// TableName 指定表名
+func (ExpenseSplit) TableName() string {
+	return "expense_splits"
+}
+
+// ValidateSplitMode 验证分摊方式
+func ValidateSplitMode(mode string) error {
+	switch mode {
+	case SplitEqual, SplitPercentage, SplitExact:
+		return nil
+	default:
+		return fmt.Errorf("无效的分摊方式: %s，可选值为 equal、percentage 或 exact", mode)
+	}
+}
+
+// IsShared 是否为需要分摊的共同消费
+func (e *Expense) IsShared() bool {
+	return len(e.Splits) > 0
+}
+
+// SplitShares 按分摊方式计算每个参与人应承担的金额，不修改消费记录；
+// 按百分比分摊时按实际百分比之和归一化，除不尽的零头（分）依次分给排在前面的参与人，保证合计等于消费金额
+func (e *Expense) SplitShares() ([]ExpenseSplit, error) {
+	if !e.IsShared() {
+		return nil, nil
+	}
+	if e.PayerID == nil || *e.PayerID == "" {
+		return nil, errors.New("共同消费必须指定付款人")
+	}
+	if err := ValidateSplitMode(e.SplitMode); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(e.Splits))
+	for _, split := range e.Splits {
+		if split.MemberID == "" {
+			return nil, errors.New("分摊参与人不能为空")
+		}
+		if seen[split.MemberID] {
+			return nil, fmt.Errorf("分摊参与人重复: %s", split.MemberID)
+		}
+		seen[split.MemberID] = true
+	}
+
+	total := e.Amount
+	cents := make([]Money, len(e.Splits))
+	switch e.SplitMode {
+	case SplitEqual:
+		for i := range cents {
+			cents[i] = total / Money(len(cents))
+		}
+	case SplitPercentage:
+		var sum float64
+		for _, split := range e.Splits {
+			if split.Percentage == nil || *split.Percentage < 0 {
+				return nil, errors.New("按百分比分摊时每个参与人都必须提供不小于0的百分比")
+			}
+			sum += *split.Percentage
+		}
+		if math.Abs(sum-100) > splitTolerance {
+			return nil, fmt.Errorf("分摊百分比之和必须为100，当前为%g", sum)
+		}
+		// 百分比之和允许有舍入误差，按实际之和计算占比，避免合计超过消费金额
+		for i, split := range e.Splits {
+			cents[i] = Money(math.Floor(float64(total) * *split.Percentage / sum))
+		}
+	case SplitExact:
+		var sum Money
+		for i, split := range e.Splits {
+			if split.Amount < 0 {
+				return nil, errors.New("分摊金额不能为负数")
+			}
+			cents[i] = split.Amount
+			sum += cents[i]
+		}
+		if sum != total {
+			return nil, fmt.Errorf("分摊金额之和%s与消费金额%s不一致", sum, total)
+		}
+	}
+
+	// 分配除不尽的零头，按百分比分摊时百分比为0的参与人不分配
+	var assigned Money
+	for _, c := range cents {
+		assigned += c
+	}
+	var eligible []int
+	for i, split := range e.Splits {
+		if e.SplitMode != SplitPercentage || *split.Percentage > 0 {
+			eligible = append(eligible, i)
+		}
+	}
+	for i := 0; assigned < total && len(eligible) > 0; i = (i + 1) % len(eligible) {
+		cents[eligible[i]]++
+		assigned++
+	}
+	// 浮点误差导致合计超出时，从排在后面的参与人依次扣回
+	for i := len(eligible) - 1; assigned > total && i >= 0; i-- {
+		if cents[eligible[i]] > 0 {
+			cents[eligible[i]]--
+			assigned--
+		}
+	}
+
+	shares := make([]ExpenseSplit, len(e.Splits))
+	for i, split := range e.Splits {
+		shares[i] = ExpenseSplit{
+			ExpenseID: e.ID,
+			MemberID:  split.MemberID,
+			Amount:    cents[i],
+		}
+		if e.SplitMode == SplitPercentage {
+			percentage := *split.Percentage
+			shares[i].Percentage = &percentage
+		}
+	}
+	return shares, nil
+}
+
+// Settlement 成员之间的结算记录，表示 FromMemberID 向 ToMemberID 支付了 Amount
+type Settlement struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	FromMemberID string    `json:"fromMemberId" gorm:"type:string;not null;index"`
+	ToMemberID   string    `json:"toMemberId" gorm:"type:string;not null;index"`
+	Amount       Money     `json:"amount" gorm:"type:integer;not null"`
+	Date         string    `json:"date" gorm:"type:string;not null;index"`
+	Remark       *string   `json:"remark,omitempty" gorm:"type:string"`
+	HouseholdID  *uint     `json:"householdId,omitempty" gorm:"index"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// TableName 指定表名
+func (Settlement) TableName() string {
+	return "settlements"
+}
+
+// Validate 验证字段
+func (s *Settlement) Validate() error {
+	if s.FromMemberID == "" || s.ToMemberID == "" {
+		return errors.New("付款成员和收款成员不能为空")
+	}
+	if s.FromMemberID == s.ToMemberID {
+		return errors.New("付款成员和收款成员不能相同")
+	}
+	if s.Amount <= 0 {
+		return errors.New("结算金额必须大于0")
+	}
+	if _, err := time.Parse("2006-01-02", s.Date); err != nil {
+		return errors.New("结算日期格式错误，应为yyyy-mm-dd格式")
+	}
+	return nil
+}
+
+// MemberBalance 成员的共同消费余额，Net 大于0表示其他成员欠该成员，小于0表示该成员欠其他成员
+type MemberBalance struct {
+	MemberID string `json:"memberId"`
+	Username string `json:"username"`
+	// Paid 为其他成员垫付的金额，Owed 其他成员为该成员垫付的金额
+	Paid Money `json:"paid"`
+	Owed Money `json:"owed"`
+	// SettledPaid 已结算付出的金额，SettledReceived 已结算收到的金额
+	SettledPaid     Money `json:"settledPaid"`
+	SettledReceived Money `json:"settledReceived"`
+	Net             Money `json:"net"`
+}
+
+// Debt 成员之间的欠款，From 应向 To 支付 Amount
+type Debt struct {
+	FromMemberID string `json:"fromMemberId"`
+	FromUsername string `json:"fromUsername"`
+	ToMemberID   string `json:"toMemberId"`
+	ToUsername   string `json:"toUsername"`
+	Amount       Money  `json:"amount"`
+}
+
+// BalanceReport 家庭共同消费余额报告
+type BalanceReport struct {
+	Balances []MemberBalance `json:"balances"`
+	// Transfers 结清全部欠款的建议转账，即抵消后谁应向谁付多少，笔数不超过有余额的成员数减一
+	Transfers []Debt `json:"transfers"`
+	// UnconvertedCount 缺少汇率无法折算为本位币、未计入余额的分摊记录数
+	UnconvertedCount int `json:"unconvertedCount"`
+}
+
+// PairAmount 两个成员之间的金额，From 应向 To 支付 Amount
+type PairAmount struct {
+	From   string
+	To     string
+	Amount Money
+}
+
+// BuildBalanceReport 根据分摊欠款和结算记录计算余额报告，members 为成员ID到用户名的映射
+func BuildBalanceReport(members map[string]string, shares, settlements []PairAmount) *BalanceReport {
+	balances := make(map[string]*MemberBalance, len(members))
+	balance := func(id string) *MemberBalance {
+		if balances[id] == nil {
+			balances[id] = &MemberBalance{MemberID: id, Username: members[id]}
+		}
+		return balances[id]
+	}
+	for id := range members {
+		balance(id)
+	}
+
+	// net 为每个成员的净余额，欠款减少欠款人的余额、增加付款人的余额，结算相反
+	net := make(map[string]Money, len(members))
+	for _, share := range shares {
+		balance(share.From).Owed += share.Amount
+		balance(share.To).Paid += share.Amount
+		net[share.From] -= share.Amount
+		net[share.To] += share.Amount
+	}
+	for _, settlement := range settlements {
+		balance(settlement.From).SettledPaid += settlement.Amount
+		balance(settlement.To).SettledReceived += settlement.Amount
+		net[settlement.From] += settlement.Amount
+		net[settlement.To] -= settlement.Amount
+	}
+
+	report := &BalanceReport{
+		Balances: make([]MemberBalance, 0, len(balances)),
+	}
+	for id, b := range balances {
+		b.Net = net[id]
+		report.Balances = append(report.Balances, *b)
+	}
+
+	sort.Slice(report.Balances, func(i, j int) bool {
+		if report.Balances[i].Net != report.Balances[j].Net {
+			return report.Balances[i].Net > report.Balances[j].Net
+		}
+		return report.Balances[i].Username < report.Balances[j].Username
+	})
+	report.Transfers = SuggestTransfers(members, net)
+	return report
+}
+
+// SuggestTransfers 根据每个成员的净余额给出结清全部欠款的转账建议：
+// 每次让欠款最多的成员向应收最多的成员转账，至少结清其中一方，因此转账笔数不超过有余额的成员数减一
+func SuggestTransfers(members map[string]string, net map[string]Money) []Debt {
+	type entry struct {
+		id    string
+		cents Money
+	}
+	var creditors, debtors []entry
+	for id, cents := range net {
+		switch {
+		case cents > 0:
+			creditors = append(creditors, entry{id, cents})
+		case cents < 0:
+			debtors = append(debtors, entry{id, -cents})
+		}
+	}
+	byAmount := func(items []entry) {
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].cents != items[j].cents {
+				return items[i].cents > items[j].cents
+			}
+			return items[i].id < items[j].id
+		})
+	}
+
+	transfers := []Debt{}
+	for len(creditors) > 0 && len(debtors) > 0 {
+		byAmount(creditors)
+		byAmount(debtors)
+		amount := creditors[0].cents
+		if debtors[0].cents < amount {
+			amount = debtors[0].cents
+		}
+		transfers = append(transfers, newDebt(members, debtors[0].id, creditors[0].id, amount))
+
+		creditors[0].cents -= amount
+		debtors[0].cents -= amount
+		if creditors[0].cents == 0 {
+			creditors = creditors[1:]
+		}
+		if debtors[0].cents == 0 {
+			debtors = debtors[1:]
+		}
+	}
+	return transfers
+}
+
+// newDebt 创建欠款记录
+func newDebt(members map[string]string, from, to string, amount Money) Debt {
+	return Debt{
+		FromMemberID: from,
+		FromUsername: members[from],
+		ToMemberID:   to,
+		ToUsername:   members[to],
+		Amount:       amount,
+	}
+}
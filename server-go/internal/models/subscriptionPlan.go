@@ -13,7 +13,7 @@ type SubscriptionPlan struct {
 	Name        string         `json:"name" gorm:"type:varchar(255);not null;uniqueIndex;comment:'计划名称'"`
 	Description string         `json:"description" gorm:"type:text;comment:'计划描述'"`
 	Duration    int           `json:"duration" gorm:"not null;comment:'订阅时长（天数）'"`
-	Price       float64       `json:"price" gorm:"type:decimal(10,2);not null;comment:'价格'"`
+	Price       Money         `json:"price" gorm:"type:integer;not null;comment:'价格（分）'"`
 	Period      string        `json:"period" gorm:"type:varchar(50);not null;comment:'订阅周期类型'"`
 	IsActive    bool          `json:"isActive" gorm:"default:true;comment:'是否激活'"`
 	CreatedAt   time.Time     `json:"createdAt"`
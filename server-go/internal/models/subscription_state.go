@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 订阅状态
+const (
+	// SubscriptionPending 已创建，等待支付
+	SubscriptionPending = "pending"
+	// SubscriptionTrialing 试用中
+	SubscriptionTrialing = "trialing"
+	// SubscriptionActive 生效中
+	SubscriptionActive = "active"
+	// SubscriptionPastDue 续费扣款失败，处于宽限期
+	SubscriptionPastDue = "past_due"
+	// SubscriptionCanceled 已取消，终态
+	SubscriptionCanceled = "canceled"
+	// SubscriptionExpired 已过期，终态
+	SubscriptionExpired = "expired"
+)
+
+// ErrInvalidSubscriptionTransition 不允许的订阅状态流转
+var ErrInvalidSubscriptionTransition = errors.New("不允许的订阅状态变更")
+
+// subscriptionTransitions 各状态允许流转到的状态，空字符串表示新建订阅；
+// active到active表示续费或延长，不改变状态但同样记录事件
+var subscriptionTransitions = map[string][]string{
+	"":                   {SubscriptionPending, SubscriptionTrialing, SubscriptionActive},
+	SubscriptionPending:  {SubscriptionTrialing, SubscriptionActive, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionTrialing: {SubscriptionActive, SubscriptionPastDue, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionActive:   {SubscriptionActive, SubscriptionPastDue, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionPastDue:  {SubscriptionActive, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionCanceled: {},
+	SubscriptionExpired:  {},
+}
+
+// SubscriptionAccessStatuses 可以使用会员权益的订阅状态
+var SubscriptionAccessStatuses = []string{SubscriptionTrialing, SubscriptionActive, SubscriptionPastDue}
+
+// ValidateSubscriptionStatus 验证订阅状态
+func ValidateSubscriptionStatus(status string) error {
+	if _, ok := subscriptionTransitions[status]; !ok || status == "" {
+		return fmt.Errorf("无效的订阅状态: %s，可选值: pending、trialing、active、past_due、canceled、expired", status)
+	}
+	return nil
+}
+
+// CanTransitionSubscription 检查订阅能否从from流转到to
+func CanTransitionSubscription(from, to string) error {
+	for _, allowed := range subscriptionTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	if from == "" {
+		from = "新建"
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidSubscriptionTransition, from, to)
+}
+
+// SubscriptionGrantsAccess 订阅状态是否可以使用会员权益
+func SubscriptionGrantsAccess(status string) bool {
+	for _, s := range SubscriptionAccessStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// RenewalPolicy 自动续费扣款失败后的重试计划
+type RenewalPolicy struct {
+	// RetryDelays 第n次扣款失败后，在订阅原结束时间加RetryDelays[n-1]时重试
+	RetryDelays []time.Duration
+	// GracePeriod 订阅原结束时间之后保留会员权益的宽限期，期满仍未续费成功则订阅过期
+	GracePeriod time.Duration
+	// PendingTimeout 续费订单等待支付网关回调的最长时间，超时仍未回调的订单视为扣款失败
+	PendingTimeout time.Duration
+}
+
+// DefaultRenewalPolicy 默认在到期后第1、3、5天重试扣款，宽限期7天，续费订单12小时未回调视为扣款失败
+var DefaultRenewalPolicy = RenewalPolicy{
+	RetryDelays:    []time.Duration{24 * time.Hour, 3 * 24 * time.Hour, 5 * 24 * time.Hour},
+	GracePeriod:    7 * 24 * time.Hour,
+	PendingTimeout: 12 * time.Hour,
+}
+
+// NextRetryAt 第attempts次扣款失败后的重试时间，没有剩余重试次数或重试时间超出宽限期时返回nil
+func (p RenewalPolicy) NextRetryAt(endDate time.Time, attempts int, now time.Time) *time.Time {
+	graceEndsAt := p.GraceEndsAt(endDate)
+	for i := attempts - 1; i >= 0 && i < len(p.RetryDelays); i++ {
+		next := endDate.Add(p.RetryDelays[i])
+		if !next.Before(graceEndsAt) {
+			return nil
+		}
+		// 扣款比计划晚时（如服务停机），跳过已经错过的重试时间点
+		if next.After(now) {
+			return &next
+		}
+	}
+	return nil
+}
+
+// GraceEndsAt 宽限期结束时间
+func (p RenewalPolicy) GraceEndsAt(endDate time.Time) time.Time {
+	return endDate.Add(p.GracePeriod)
+}
+
+// SubscriptionEvent 订阅状态变更记录
+type SubscriptionEvent struct {
+	ID             uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	SubscriptionID string `json:"subscriptionId" gorm:"type:uuid;not null;index"`
+	MemberID       string `json:"memberId" gorm:"type:uuid;index"`
+	// FromStatus 变更前的状态，新建订阅时为空
+	FromStatus string `json:"fromStatus" gorm:"type:varchar(50)"`
+	ToStatus   string `json:"toStatus" gorm:"type:varchar(50);not null"`
+	// Actor 操作人用户名，后台任务为system，支付网关回调为gateway
+	Actor     string    `json:"actor" gorm:"type:varchar(255)"`
+	Reason    string    `json:"reason" gorm:"type:text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 指定表名
+func (SubscriptionEvent) TableName() string {
+	return "subscription_events"
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 标签筛选方式
+const (
+	// TagModeAny 包含任意一个指定标签
+	TagModeAny = "any"
+	// TagModeAll 包含全部指定标签
+	TagModeAll = "all"
+)
+
+const (
+	// maxTagNameLength 标签名称的最大字符数
+	maxTagNameLength = 20
+	// maxTagsPerExpense 每条消费记录最多的标签数
+	maxTagsPerExpense = 10
+)
+
+// Tag 消费标签，同一家庭内名称唯一
+type Tag struct {
+	ID   uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name string `json:"name" gorm:"type:string;not null;uniqueIndex:idx_tag_household_name,priority:2"`
+	// HouseholdID 所属家庭，只有该家庭的成员可以访问
+	HouseholdID *uint `json:"householdId,omitempty" gorm:"index;uniqueIndex:idx_tag_household_name,priority:1"`
+}
+
+// TableName 指定表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// ExpenseTag 消费记录与标签的关联
+type ExpenseTag struct {
+	ExpenseID uint `gorm:"primaryKey;autoIncrement:false"`
+	TagID     uint `gorm:"primaryKey;autoIncrement:false;index"`
+}
+
+// TableName 指定表名
+func (ExpenseTag) TableName() string {
+	return "expense_tags"
+}
+
+// TagUsage 标签及其使用次数
+type TagUsage struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTags 去除标签名称首尾空白并去重，忽略空名称，保持原有顺序
+func NormalizeTags(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if utf8.RuneCountInString(name) > maxTagNameLength {
+			return nil, fmt.Errorf("标签名称不能超过%d个字符: %s", maxTagNameLength, name)
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	if len(normalized) > maxTagsPerExpense {
+		return nil, fmt.Errorf("标签数量不能超过%d个", maxTagsPerExpense)
+	}
+	return normalized, nil
+}
+
+// ParseTagList 解析逗号分隔的标签列表，如 "旅行,餐饮"
+func ParseTagList(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	return NormalizeTags(strings.Split(value, ","))
+}
+
+// ValidateTagMode 验证标签筛选方式，空值按 any 处理
+func ValidateTagMode(mode string) error {
+	switch mode {
+	case "", TagModeAny, TagModeAll:
+		return nil
+	default:
+		return fmt.Errorf("无效的标签筛选方式: %s，可选值为 any 或 all", mode)
+	}
+}
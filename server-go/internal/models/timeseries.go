@@ -0,0 +1,55 @@
+package models
+
+import "fmt"
+
+// 时间序列分组方式
+const (
+	GroupByNone = ""
+	GroupByType = "type"
+)
+
+// TimeSeriesTotalName 不分组时唯一序列的名称
+const TimeSeriesTotalName = "total"
+
+// ValidateGroupBy 验证时间序列分组方式
+func ValidateGroupBy(groupBy string) error {
+	switch groupBy {
+	case GroupByNone, GroupByType:
+		return nil
+	default:
+		return fmt.Errorf("无效的分组方式: %s，可选值: type", groupBy)
+	}
+}
+
+// PeriodTotal 单个周期（及分组）的汇总结果
+type PeriodTotal struct {
+	Period string
+	Group  string
+	Total  Money
+	Count  int
+}
+
+// TimeSeriesPoint 时间序列中的一个数据点
+type TimeSeriesPoint struct {
+	Period string `json:"period"`
+	Amount Money  `json:"amount"`
+	Count  int    `json:"count"`
+}
+
+// TimeSeries 一条时间序列，不分组时名称为total，按类型分组时为消费类型
+type TimeSeries struct {
+	Name   string            `json:"name"`
+	Total  Money             `json:"total"`
+	Count  int               `json:"count"`
+	Points []TimeSeriesPoint `json:"points"`
+}
+
+// TimeSeriesReport 时间序列统计结果，所有序列的数据点与Periods一一对应
+type TimeSeriesReport struct {
+	Granularity string       `json:"granularity"`
+	GroupBy     string       `json:"groupBy"`
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	Periods     []string     `json:"periods"`
+	Series      []TimeSeries `json:"series"`
+}
@@ -17,6 +17,12 @@ type UserSubscription struct {
 	Status     string        `json:"status" gorm:"type:varchar(50);default:'active';comment:'订阅状态'"`
 	PaymentID  string        `json:"paymentId" gorm:"type:varchar(255);comment:'支付平台的交易ID'"`
 	AutoRenew  bool          `json:"autoRenew" gorm:"default:false;comment:'是否自动续费'"`
+	// RenewalAttempts 本期自动续费已失败的次数，续费成功后清零
+	RenewalAttempts int        `json:"renewalAttempts" gorm:"default:0"`
+	// NextRenewalAt 扣款失败后下一次重试扣款的时间，没有剩余重试次数时为空
+	NextRenewalAt   *time.Time `json:"nextRenewalAt,omitempty"`
+	// GraceEndsAt 扣款失败后宽限期的结束时间，宽限期内仍可使用会员权益，结束后订阅过期
+	GraceEndsAt     *time.Time `json:"graceEndsAt,omitempty"`
 	CreatedAt  time.Time     `json:"createdAt"`
 	UpdatedAt  time.Time     `json:"updatedAt"`
 	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
@@ -43,7 +49,7 @@ func (us *UserSubscription) BeforeCreate(tx *gorm.DB) error {
 		us.UpdatedAt = time.Now()
 	}
 	if us.Status == "" {
-		us.Status = "active"
+		us.Status = SubscriptionActive
 	}
 	return nil
 }
@@ -54,15 +60,25 @@ func (us *UserSubscription) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
-// IsActiveSubscription 检查订阅是否处于活跃状态
+// IsActiveSubscription 检查订阅当前是否可以使用会员权益（试用、生效或宽限期内）
 func (us *UserSubscription) IsActiveSubscription() bool {
-	if us.Status != "active" {
+	if !SubscriptionGrantsAccess(us.Status) {
 		return false
 	}
 	now := time.Now()
+	if us.Status == SubscriptionPastDue && us.GraceEndsAt != nil {
+		return now.After(us.StartDate) && now.Before(*us.GraceEndsAt)
+	}
 	return now.After(us.StartDate) && now.Before(us.EndDate)
 }
 
+// ClearDunning 清除自动续费失败后的重试和宽限期信息
+func (us *UserSubscription) ClearDunning() {
+	us.RenewalAttempts = 0
+	us.NextRenewalAt = nil
+	us.GraceEndsAt = nil
+}
+
 // ToJSON 转换为JSON格式
 func (us *UserSubscription) ToJSON() map[string]interface{} {
 	return map[string]interface{}{
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAccountInUse 账户已被消费、收入或转账记录引用
+var ErrAccountInUse = errors.New("账户已被记录引用，无法删除")
+
+// AccountRepository 资金账户数据仓库
+type AccountRepository struct {
+	db *gorm.DB
+	// householdID 不为空时仓库只读写该家庭的数据，新建记录自动归属该家庭
+	householdID *uint
+}
+
+// NewAccountRepository 创建新的资金账户仓库
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{
+		db: db,
+	}
+}
+
+// ForHousehold 返回只读写指定家庭数据的账户仓库
+func (r *AccountRepository) ForHousehold(householdID uint) *AccountRepository {
+	return &AccountRepository{
+		db:          householdScope(r.db, householdID),
+		householdID: &householdID,
+	}
+}
+
+// Create 创建账户
+func (r *AccountRepository) Create(account *models.Account) error {
+	if err := account.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	if r.householdID != nil {
+		account.HouseholdID = r.householdID
+	}
+	return r.db.Create(account).Error
+}
+
+// FindByID 根据ID查找账户
+func (r *AccountRepository) FindByID(id string) (*models.Account, error) {
+	var account models.Account
+	if err := r.db.First(&account, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindAll 获取所有账户
+func (r *AccountRepository) FindAll() ([]models.Account, error) {
+	var accounts []models.Account
+	if err := r.db.Order("id ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// Update 更新账户
+func (r *AccountRepository) Update(account *models.Account) error {
+	if err := account.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	return r.db.Save(account).Error
+}
+
+// Delete 删除账户，账户已被引用时返回ErrAccountInUse
+func (r *AccountRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var expenseCount, incomeCount, transferCount int64
+		if err := tx.Model(&models.Expense{}).Where("account_id = ?", id).Count(&expenseCount).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Income{}).Where("account_id = ?", id).Count(&incomeCount).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Transfer{}).
+			Where("from_account_id = ? OR to_account_id = ?", id, id).
+			Count(&transferCount).Error; err != nil {
+			return err
+		}
+		if expenseCount+incomeCount+transferCount > 0 {
+			return ErrAccountInUse
+		}
+
+		result := tx.Delete(&models.Account{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("记录不存在")
+		}
+		return nil
+	})
+}
+
+// CreateTransfer 在一个事务中校验双方账户并记录转账
+func (r *AccountRepository) CreateTransfer(transfer *models.Transfer) error {
+	if err := transfer.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	if r.householdID != nil {
+		transfer.HouseholdID = r.householdID
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Account{}).
+			Where("id IN ?", []uint{transfer.FromAccountID, transfer.ToAccountID}).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 2 {
+			return fmt.Errorf("转出账户或转入账户不存在")
+		}
+		return tx.Create(transfer).Error
+	})
+}
+
+// FindTransfers 获取转账记录，accountID不为0时只返回与该账户相关的记录
+func (r *AccountRepository) FindTransfers(accountID uint) ([]models.Transfer, error) {
+	var transfers []models.Transfer
+	query := r.db.Model(&models.Transfer{})
+	if accountID != 0 {
+		query = query.Where("from_account_id = ? OR to_account_id = ?", accountID, accountID)
+	}
+	if err := query.Order("date DESC, id DESC").Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// GetBalance 计算账户截至asOf（含当天）的余额及按日的余额变动
+func (r *AccountRepository) GetBalance(account *models.Account, asOf string) (*models.AccountBalance, error) {
+	balance := &models.AccountBalance{
+		AccountID:      account.ID,
+		AsOf:           asOf,
+		OpeningBalance: account.OpeningBalance,
+		History:        []models.AccountBalanceEntry{},
+	}
+
+	base, err := householdBaseCurrency(r.db, r.householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 收入和转账按本位币记账，消费记录按各自币种折算为本位币，金额为NULL的记录计入未折算数
+	changes := make(map[string]models.Money)
+	collect := func(model interface{}, amount clause.Expr, where string, sign models.Money, total *models.Money) error {
+		var rows []struct {
+			Date        string
+			Total       models.Money
+			Unconverted int
+		}
+		if err := r.db.Model(model).
+			Select("date, COALESCE(SUM(?), 0) AS total, COUNT(*) - COUNT(?) AS unconverted", amount, amount).
+			Where(where, account.ID).
+			Where("date <= ?", asOf).
+			Group("date").
+			Scan(&rows).Error; err != nil {
+			return fmt.Errorf("汇总账户流水失败: %w", err)
+		}
+		for _, row := range rows {
+			changes[row.Date] += sign * row.Total
+			*total += row.Total
+			balance.UnconvertedCount += row.Unconverted
+		}
+		return nil
+	}
+
+	plain := gorm.Expr("amount")
+	if err := collect(&models.Income{}, plain, "account_id = ?", 1, &balance.TotalIncome); err != nil {
+		return nil, err
+	}
+	if err := collect(&models.Expense{}, models.BaseAmountExpr(base), "account_id = ?", -1, &balance.TotalExpense); err != nil {
+		return nil, err
+	}
+	if err := collect(&models.Transfer{}, plain, "to_account_id = ?", 1, &balance.TransferIn); err != nil {
+		return nil, err
+	}
+	if err := collect(&models.Transfer{}, plain, "from_account_id = ?", -1, &balance.TransferOut); err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, len(changes))
+	for date := range changes {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	running := account.OpeningBalance
+	for _, date := range dates {
+		running += changes[date]
+		balance.History = append(balance.History, models.AccountBalanceEntry{
+			Date:    date,
+			Change:  changes[date],
+			Balance: running,
+		})
+	}
+	balance.Balance = running
+
+	return balance, nil
+}
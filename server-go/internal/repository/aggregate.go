@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"fmt"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sumByPeriod 按统计周期汇总金额，model 可以是任何具有 type/remark/amount/date 列的表，
+// amount 为参与汇总的金额表达式，值为NULL的记录不计入
+func sumByPeriod(db *gorm.DB, model interface{}, query *models.ExpenseQuery, granularity string, amount clause.Expr) (map[string]models.Money, error) {
+	var rows []struct {
+		Period string
+		Total  models.Money
+	}
+
+	periodExpr := models.PeriodExpr(granularity)
+	if err := query.ApplyToQuery(db.Model(model)).
+		Select(periodExpr+" AS period, COALESCE(SUM(?), 0) AS total", amount).
+		Group(periodExpr).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("按周期汇总失败: %w", err)
+	}
+
+	totals := make(map[string]models.Money, len(rows))
+	for _, row := range rows {
+		totals[row.Period] = row.Total
+	}
+	return totals, nil
+}
+
+// sumByPeriodGrouped 按统计周期和分组列汇总金额与笔数，groupBy为空时不分组，amount 同 sumByPeriod
+func sumByPeriodGrouped(db *gorm.DB, model interface{}, query *models.ExpenseQuery, granularity, groupBy string, amount clause.Expr) ([]models.PeriodTotal, error) {
+	if err := models.ValidateGroupBy(groupBy); err != nil {
+		return nil, err
+	}
+
+	periodExpr := models.PeriodExpr(granularity)
+	selectExpr := periodExpr + " AS period, '' AS `group`, COALESCE(SUM(?), 0) AS total, COUNT(?) AS count"
+	groupExpr := periodExpr
+	if groupBy != models.GroupByNone {
+		selectExpr = periodExpr + " AS period, " + groupBy + " AS `group`, COALESCE(SUM(?), 0) AS total, COUNT(?) AS count"
+		groupExpr = periodExpr + ", " + groupBy
+	}
+
+	var rows []models.PeriodTotal
+	if err := query.ApplyToQuery(db.Model(model)).
+		Select(selectExpr, amount, amount).
+		Group(groupExpr).
+		Order("period ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("按周期汇总失败: %w", err)
+	}
+	return rows, nil
+}
+
+// dateBounds 获取满足查询条件的最早和最晚日期
+func dateBounds(db *gorm.DB, model interface{}, query *models.ExpenseQuery) (string, string, error) {
+	var bounds struct {
+		MinDate *string
+		MaxDate *string
+	}
+	if err := query.ApplyToQuery(db.Model(model)).
+		Select("MIN(date) AS min_date, MAX(date) AS max_date").
+		Scan(&bounds).Error; err != nil {
+		return "", "", fmt.Errorf("获取日期范围失败: %w", err)
+	}
+
+	var minDate, maxDate string
+	if bounds.MinDate != nil {
+		minDate = *bounds.MinDate
+	}
+	if bounds.MaxDate != nil {
+		maxDate = *bounds.MaxDate
+	}
+	return minDate, maxDate, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BudgetRepository 预算数据仓库
+type BudgetRepository struct {
+	db *gorm.DB
+	// householdID 不为空时仓库只读写该家庭的数据，新建记录自动归属该家庭
+	householdID *uint
+}
+
+// NewBudgetRepository 创建新的预算仓库
+func NewBudgetRepository(db *gorm.DB) *BudgetRepository {
+	return &BudgetRepository{
+		db: db,
+	}
+}
+
+// ForHousehold 返回只读写指定家庭数据的预算仓库
+func (r *BudgetRepository) ForHousehold(householdID uint) *BudgetRepository {
+	return &BudgetRepository{
+		db:          householdScope(r.db, householdID),
+		householdID: &householdID,
+	}
+}
+
+// Create 创建预算
+func (r *BudgetRepository) Create(budget *models.Budget) error {
+	if err := budget.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	if r.householdID != nil {
+		budget.HouseholdID = r.householdID
+	}
+	return r.db.Create(budget).Error
+}
+
+// FindByID 根据ID查找预算
+func (r *BudgetRepository) FindByID(id string) (*models.Budget, error) {
+	var budget models.Budget
+	if err := r.db.First(&budget, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// FindAll 获取预算列表，month不为空时只返回对该月生效的预算
+func (r *BudgetRepository) FindAll(month string) ([]models.Budget, error) {
+	var budgets []models.Budget
+	query := r.db.Model(&models.Budget{})
+	if month != "" {
+		query = query.Where("month = ? OR month = ''", month)
+	}
+	if err := query.Order("category ASC, month ASC").Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// Update 更新预算
+func (r *BudgetRepository) Update(budget *models.Budget) error {
+	if err := budget.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	return r.db.Save(budget).Error
+}
+
+// Delete 删除预算
+func (r *BudgetRepository) Delete(id string) error {
+	result := r.db.Delete(&models.Budget{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("记录不存在")
+	}
+	return nil
+}
+
+// GetStatus 获取指定月份的预算执行情况
+// 同一分类同时存在当月预算和循环预算时，以当月预算为准
+func (r *BudgetRepository) GetStatus(month string) ([]models.BudgetStatus, error) {
+	monthQuery := &models.ExpenseQuery{Month: month}
+	startDate, endDate, err := monthQuery.ToMonthRange()
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := r.FindAll(month)
+	if err != nil {
+		return nil, fmt.Errorf("获取预算失败: %w", err)
+	}
+
+	effective := make(map[string]models.Budget)
+	for _, budget := range budgets {
+		if current, ok := effective[budget.Category]; ok && !current.IsRecurring() {
+			continue
+		}
+		effective[budget.Category] = budget
+	}
+
+	base, err := householdBaseCurrency(r.db, r.householdID)
+	if err != nil {
+		return nil, err
+	}
+	amount := models.BaseAmountExpr(base)
+
+	// 按类型汇总当月支出，金额折算为家庭本位币
+	var rows []struct {
+		Type        string
+		Total       models.Money
+		Unconverted int
+	}
+	if err := r.db.Model(&models.Expense{}).
+		Select("type, COALESCE(SUM(?), 0) AS total, COUNT(*) - COUNT(?) AS unconverted", amount, amount).
+		Where("date BETWEEN ? AND ?", startDate, endDate).
+		Group("type").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("汇总支出失败: %w", err)
+	}
+
+	spentByType := make(map[string]models.Money)
+	unconvertedByType := make(map[string]int)
+	var totalSpent models.Money
+	var totalUnconverted int
+	for _, row := range rows {
+		spentByType[row.Type] = row.Total
+		unconvertedByType[row.Type] = row.Unconverted
+		totalSpent += row.Total
+		totalUnconverted += row.Unconverted
+	}
+
+	statuses := make([]models.BudgetStatus, 0, len(effective))
+	for category, budget := range effective {
+		spent, unconverted := spentByType[category], unconvertedByType[category]
+		if category == "" {
+			spent, unconverted = totalSpent, totalUnconverted
+		}
+
+		statuses = append(statuses, models.BudgetStatus{
+			BudgetID:         budget.ID,
+			Category:         category,
+			Recurring:        budget.IsRecurring(),
+			Budget:           budget.Amount,
+			Spent:            spent,
+			Remaining:        budget.Amount - spent,
+			Percent:          math.Round(float64(spent)*10000/float64(budget.Amount)) / 100,
+			OverBudget:       spent > budget.Amount,
+			UnconvertedCount: unconverted,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Category < statuses[j].Category
+	})
+
+	return statuses, nil
+}
@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCategoryNameExists 同一家庭内已有同名分类
+	ErrCategoryNameExists = errors.New("分类名称已存在，如需合并请使用合并接口")
+	// ErrCategoryHasChildren 分类下还有下级分类
+	ErrCategoryHasChildren = errors.New("分类下还有下级分类，无法删除")
+	// ErrParentCategoryNotFound 上级分类不存在
+	ErrParentCategoryNotFound = errors.New("上级分类不存在")
+	// ErrMergeIntoDescendant 不能合并到自身或下级分类
+	ErrMergeIntoDescendant = errors.New("不能合并到分类自身或其下级分类")
+)
+
+// CategoryRepository 消费分类数据仓库
+type CategoryRepository struct {
+	db *gorm.DB
+	// householdID 不为空时仓库只读写该家庭的数据，新建记录自动归属该家庭
+	householdID *uint
+}
+
+// NewCategoryRepository 创建新的消费分类仓库
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{
+		db: db,
+	}
+}
+
+// ForHousehold 返回只读写指定家庭数据的分类仓库
+func (r *CategoryRepository) ForHousehold(householdID uint) *CategoryRepository {
+	return &CategoryRepository{
+		db:          householdScope(r.db, householdID),
+		householdID: &householdID,
+	}
+}
+
+// FindAll 获取全部分类
+func (r *CategoryRepository) FindAll() ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// FindByID 根据ID查找分类
+func (r *CategoryRepository) FindByID(id string) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.First(&category, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetTree 获取分类树及各分类直接对应的消费记录数
+func (r *CategoryRepository) GetTree() ([]models.CategoryNode, error) {
+	categories, err := r.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Type  string
+		Count int
+	}
+	if err := r.db.Model(&models.Expense{}).
+		Select("type, COUNT(*) AS count").
+		Group("type").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return models.BuildCategoryTree(categories, counts), nil
+}
+
+// Create 创建分类
+func (r *CategoryRepository) Create(category *models.Category) error {
+	if err := category.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	if r.householdID != nil {
+		category.HouseholdID = r.householdID
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryName(tx, category.Name, 0); err != nil {
+			return err
+		}
+		if err := checkCategoryParent(tx, category); err != nil {
+			return err
+		}
+		return tx.Create(category).Error
+	})
+}
+
+// Update 更新分类的上级分类、图标和颜色，修改名称请使用 Rename
+func (r *CategoryRepository) Update(category *models.Category) error {
+	if err := category.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryParent(tx, category); err != nil {
+			return err
+		}
+		return tx.Model(category).Select("parent_id", "icon", "color", "updated_at").Updates(category).Error
+	})
+}
+
+// Delete 删除分类，消费记录保留原类型；还有下级分类时返回ErrCategoryHasChildren
+func (r *CategoryRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrCategoryHasChildren
+		}
+
+		result := tx.Delete(&models.Category{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("记录不存在")
+		}
+		return nil
+	})
+}
+
+// SyncFromExpenses 为还没有分类的消费类型和周期模板类型创建顶级分类，
+// 返回新建的分类和因名称不符合分类规则而跳过的类型
+func (r *CategoryRepository) SyncFromExpenses() (*models.CategorySyncResult, error) {
+	result := &models.CategorySyncResult{
+		Created: []models.Category{},
+		Skipped: []models.CategorySyncSkip{},
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var names []string
+		if err := tx.Model(&models.Category{}).Pluck("name", &names).Error; err != nil {
+			return err
+		}
+		existing := make(map[string]bool, len(names))
+		for _, name := range names {
+			existing[name] = true
+		}
+
+		var types, recurringTypes []string
+		if err := tx.Model(&models.Expense{}).Distinct().Order("type ASC").Pluck("type", &types).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RecurringExpense{}).Distinct().Order("type ASC").Pluck("type", &recurringTypes).Error; err != nil {
+			return err
+		}
+
+		for _, name := range append(types, recurringTypes...) {
+			if existing[name] {
+				continue
+			}
+			existing[name] = true
+			category := models.Category{Name: name, HouseholdID: r.householdID}
+			if err := category.Validate(); err != nil {
+				result.Skipped = append(result.Skipped, models.CategorySyncSkip{Name: name, Reason: err.Error()})
+				continue
+			}
+			if err := tx.Create(&category).Error; err != nil {
+				return err
+			}
+			result.Created = append(result.Created, category)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Rename 在一个事务中重命名分类，并改写使用旧名称的消费记录、周期模板和预算
+func (r *CategoryRepository) Rename(category *models.Category, name string) (*models.CategoryChangeResult, error) {
+	renamed := *category
+	renamed.Name = name
+	if err := renamed.Validate(); err != nil {
+		return nil, fmt.Errorf("验证失败: %w", err)
+	}
+
+	result := &models.CategoryChangeResult{Category: category}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryName(tx, name, category.ID); err != nil {
+			return err
+		}
+		if err := moveCategoryReferences(tx, category.Name, name, result); err != nil {
+			return err
+		}
+		return tx.Model(category).Update("name", name).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	category.Name = name
+	return result, nil
+}
+
+// Merge 在一个事务中将 source 合并到 target：消费记录、周期模板和预算改用 target 的名称，
+// source 的下级分类移到 target 下，然后删除 source
+func (r *CategoryRepository) Merge(source, target *models.Category) (*models.CategoryChangeResult, error) {
+	result := &models.CategoryChangeResult{Category: target}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var categories []models.Category
+		if err := tx.Find(&categories).Error; err != nil {
+			return err
+		}
+		if models.IsDescendant(categories, target.ID, source.ID) {
+			return ErrMergeIntoDescendant
+		}
+
+		if err := moveCategoryReferences(tx, source.Name, target.Name, result); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Category{}).
+			Where("parent_id = ?", source.ID).
+			Update("parent_id", target.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Category{}, "id = ?", source.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkCategoryName 检查分类名称在家庭内是否已被其他分类使用，tx 需带有家庭条件
+func checkCategoryName(tx *gorm.DB, name string, excludeID uint) error {
+	var count int64
+	if err := tx.Model(&models.Category{}).
+		Where("name = ? AND id <> ?", name, excludeID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryNameExists
+	}
+	return nil
+}
+
+// checkCategoryParent 检查上级分类存在于同一家庭且不会形成循环，tx 需带有家庭条件
+func checkCategoryParent(tx *gorm.DB, category *models.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+
+	var categories []models.Category
+	if err := tx.Find(&categories).Error; err != nil {
+		return err
+	}
+	found := false
+	for _, item := range categories {
+		if item.ID == *category.ParentID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrParentCategoryNotFound
+	}
+	if category.ID != 0 && models.IsDescendant(categories, *category.ParentID, category.ID) {
+		return models.ErrCategoryCycle
+	}
+	return nil
+}
+
+// moveCategoryReferences 将消费记录、周期模板和预算的分类从 from 改为 to，tx 需带有家庭条件；
+// 预算与目标分类同月份冲突时金额相加并删除原预算
+func moveCategoryReferences(tx *gorm.DB, from, to string, result *models.CategoryChangeResult) error {
+	if from == to {
+		return nil
+	}
+
+	expenses := tx.Model(&models.Expense{}).Where("type = ?", from).Update("type", to)
+	if expenses.Error != nil {
+		return fmt.Errorf("更新消费记录失败: %w", expenses.Error)
+	}
+	result.ExpensesUpdated = expenses.RowsAffected
+
+	recurring := tx.Model(&models.RecurringExpense{}).Where("type = ?", from).Update("type", to)
+	if recurring.Error != nil {
+		return fmt.Errorf("更新周期模板失败: %w", recurring.Error)
+	}
+	result.RecurringUpdated = recurring.RowsAffected
+
+	var budgets []models.Budget
+	if err := tx.Where("category = ?", from).Find(&budgets).Error; err != nil {
+		return err
+	}
+	for _, budget := range budgets {
+		var existing models.Budget
+		err := tx.Where("category = ? AND month = ?", to, budget.Month).First(&existing).Error
+		switch {
+		case err == nil:
+			if err := tx.Model(&existing).Update("amount", existing.Amount+budget.Amount).Error; err != nil {
+				return fmt.Errorf("合并预算失败: %w", err)
+			}
+			if err := tx.Delete(&models.Budget{}, "id = ?", budget.ID).Error; err != nil {
+				return fmt.Errorf("合并预算失败: %w", err)
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Model(&budget).Update("category", to).Error; err != nil {
+				return fmt.Errorf("更新预算失败: %w", err)
+			}
+		default:
+			return err
+		}
+		result.BudgetsUpdated++
+	}
+	return nil
+}
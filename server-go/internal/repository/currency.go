@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRateRepository 汇率数据仓库，汇率表不区分家庭
+type ExchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository 创建新的汇率仓库
+func NewExchangeRateRepository(db *gorm.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		db: db,
+	}
+}
+
+// FindAll 获取汇率，currency 不为空时只返回原币种或目标币种为该币种的汇率，按日期从新到旧排列
+func (r *ExchangeRateRepository) FindAll(currency string) ([]models.ExchangeRate, error) {
+	rates := []models.ExchangeRate{}
+	query := r.db.Order("date DESC, from_currency ASC, to_currency ASC")
+	if currency != "" {
+		query = query.Where("from_currency = ? OR to_currency = ?", currency, currency)
+	}
+	if err := query.Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// Upsert 在一个事务中保存汇率，同一日期和币种对已有汇率时覆盖原汇率
+func (r *ExchangeRateRepository) Upsert(rates []models.ExchangeRate) error {
+	for i := range rates {
+		rates[i].Normalize()
+		if err := rates[i].Validate(); err != nil {
+			return fmt.Errorf("第%d条汇率验证失败: %w", i+1, err)
+		}
+	}
+	if len(rates) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range rates {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "date"}, {Name: "from_currency"}, {Name: "to_currency"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"rate":       rates[i].Rate,
+					"updated_at": time.Now(),
+				}),
+			}).Create(&rates[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete 删除汇率
+func (r *ExchangeRateRepository) Delete(id string) error {
+	result := r.db.Delete(&models.ExchangeRate{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("记录不存在")
+	}
+	return nil
+}
+
+// householdBaseCurrency 获取家庭本位币，未启用登录或家庭未设置时使用默认本位币
+func householdBaseCurrency(db *gorm.DB, householdID *uint) (string, error) {
+	if householdID == nil {
+		return models.DefaultBaseCurrency, nil
+	}
+
+	var currencies []string
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Household{}).
+		Where("id = ?", *householdID).
+		Pluck("base_currency", &currencies).Error; err != nil {
+		return "", fmt.Errorf("获取家庭本位币失败: %w", err)
+	}
+	if len(currencies) == 0 || currencies[0] == "" {
+		return models.DefaultBaseCurrency, nil
+	}
+	return currencies[0], nil
+}
+
+// fillCurrency 规范化消费记录的币种，为空时使用本位币，须在保存消费记录之前调用
+func fillCurrency(expense *models.Expense, base string) {
+	expense.Currency = models.NormalizeCurrency(expense.Currency)
+	if expense.Currency == "" {
+		expense.Currency = base
+	}
+}
+
+// baseCurrency 获取仓库所属家庭的本位币
+func (r *ExpenseRepository) baseCurrency() (string, error) {
+	return householdBaseCurrency(r.db, r.householdID)
+}
+
+// baseAmount 获取折算为家庭本位币的消费金额表达式
+func (r *ExpenseRepository) baseAmount() (clause.Expr, error) {
+	base, err := r.baseCurrency()
+	if err != nil {
+		return clause.Expr{}, err
+	}
+	return models.BaseAmountExpr(base), nil
+}
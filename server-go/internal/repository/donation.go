@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DonationRepository 捐赠记录数据仓库
+type DonationRepository struct {
+	db *gorm.DB
+}
+
+// NewDonationRepository 创建新的捐赠记录仓库
+func NewDonationRepository(db *gorm.DB) *DonationRepository {
+	return &DonationRepository{
+		db: db,
+	}
+}
+
+// Create 创建捐赠记录，ID已存在时忽略
+func (r *DonationRepository) Create(record *models.DonationRecord) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record).Error
+}
+
+// Import 批量导入捐赠记录，跳过ID已存在的记录，返回实际写入的条数
+func (r *DonationRepository) Import(records []models.DonationRecord) (int, error) {
+	imported := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range records {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&records[i])
+			if result.Error != nil {
+				return result.Error
+			}
+			imported += int(result.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return imported, nil
+}
+
+// FindWithPagination 分页查找捐赠记录，按捐赠时间倒序
+func (r *DonationRepository) FindWithPagination(query *models.DonationQuery) ([]models.DonationRecord, int64, error) {
+	var records []models.DonationRecord
+	var total int64
+
+	baseQuery := applyDonationQuery(r.db.Model(&models.DonationRecord{}), query)
+	if err := baseQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := baseQuery.Order("timestamp DESC").Offset(query.Offset).Limit(query.Limit).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+// Summarize 汇总满足条件的捐赠笔数、金额以及每个用户的合计
+func (r *DonationRepository) Summarize(query *models.DonationQuery) (*models.DonationSummary, error) {
+	summary := &models.DonationSummary{}
+	var totals struct {
+		Count int64
+		Total models.Money
+	}
+	if err := applyDonationQuery(r.db.Model(&models.DonationRecord{}), query).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS total").
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	summary.Count = totals.Count
+	summary.TotalAmount = totals.Total
+
+	byUser, err := r.donorTotals(query, 0)
+	if err != nil {
+		return nil, err
+	}
+	summary.ByUser = byUser
+	return summary, nil
+}
+
+// Leaderboard 按捐赠总额排名，只统计成功的捐赠
+func (r *DonationRepository) Leaderboard(query *models.DonationQuery, limit int) ([]models.DonorTotal, error) {
+	scoped := *query
+	scoped.Status = models.DonationSuccess
+	donors, err := r.donorTotals(&scoped, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range donors {
+		donors[i].Rank = i + 1
+	}
+	return donors, nil
+}
+
+// donorTotals 按用户汇总捐赠金额，limit为0时不限制条数
+func (r *DonationRepository) donorTotals(query *models.DonationQuery, limit int) ([]models.DonorTotal, error) {
+	donors := []models.DonorTotal{}
+	db := applyDonationQuery(r.db.Model(&models.DonationRecord{}), query).
+		Select("username, SUM(amount) AS amount, COUNT(*) AS count").
+		Group("username").
+		Order("amount DESC, username ASC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if err := db.Scan(&donors).Error; err != nil {
+		return nil, err
+	}
+	return donors, nil
+}
+
+// applyDonationQuery 应用捐赠记录筛选条件（不含分页）
+func applyDonationQuery(db *gorm.DB, query *models.DonationQuery) *gorm.DB {
+	if query.Username != "" {
+		db = db.Where("username = ?", query.Username)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.StartDate != "" {
+		db = db.Where("SUBSTR(timestamp, 1, 10) >= ?", query.StartDate)
+	}
+	if query.EndDate != "" {
+		db = db.Where("SUBSTR(timestamp, 1, 10) <= ?", query.EndDate)
+	}
+	if query.MinAmount != nil {
+		db = db.Where("amount >= ?", *query.MinAmount)
+	}
+	if query.MaxAmount != nil {
+		db = db.Where("amount <= ?", *query.MaxAmount)
+	}
+	if len(query.ExcludeUsers) > 0 {
+		db = db.Where("username NOT IN ?", query.ExcludeUsers)
+	}
+	return db
+}
+
+// updateDonationStatus 更新支付订单对应的捐赠记录状态，可在事务中调用
+func updateDonationStatus(db *gorm.DB, orderID, status string) error {
+	return db.Model(&models.DonationRecord{}).Where("order_id = ?", orderID).Update("status", status).Error
+}
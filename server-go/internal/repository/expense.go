@@ -11,6 +11,8 @@ import (
 // ExpenseRepository 消费记录数据仓库
 type ExpenseRepository struct {
 	db *gorm.DB
+	// householdID 不为空时仓库只读写该家庭的数据，新建记录自动归属该家庭
+	householdID *uint
 }
 
 // NewExpenseRepository 创建新的消费记录仓库
@@ -20,12 +22,34 @@ func NewExpenseRepository(db *gorm.DB) *ExpenseRepository {
 	}
 }
 
+// ForHousehold 返回只读写指定家庭数据的消费记录仓库
+func (r *ExpenseRepository) ForHousehold(householdID uint) *ExpenseRepository {
+	return &ExpenseRepository{
+		db:          householdScope(r.db, householdID),
+		householdID: &householdID,
+	}
+}
+
 // Create 创建消费记录
 func (r *ExpenseRepository) Create(expense *models.Expense) error {
 	if err := expense.Validate(); err != nil {
 		return fmt.Errorf("验证失败: %w", err)
 	}
-	return r.db.Create(expense).Error
+	if r.householdID != nil {
+		expense.HouseholdID = r.householdID
+	}
+	base, err := r.baseCurrency()
+	if err != nil {
+		return err
+	}
+	fillCurrency(expense, base)
+	normalizeSplit(expense)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(expense).Error; err != nil {
+			return err
+		}
+		return saveExpenseDetails(tx, expense)
+	})
 }
 
 // FindByID 根据ID查找消费记录
@@ -37,7 +61,11 @@ func (r *ExpenseRepository) FindByID(id string) (*models.Expense, error) {
 		}
 		return nil, err
 	}
-	return &expense, nil
+	expenses := []models.Expense{expense}
+	if err := loadExpenseDetails(r.db, expenses); err != nil {
+		return nil, err
+	}
+	return &expenses[0], nil
 }
 
 // FindWithPagination 分页查找消费记录
@@ -69,20 +97,52 @@ func (r *ExpenseRepository) FindWithPagination(query *models.ExpenseQuery) ([]mo
 	if err := baseQuery.Find(&expenses).Error; err != nil {
 		return nil, 0, err
 	}
+	if err := loadExpenseDetails(r.db, expenses); err != nil {
+		return nil, 0, err
+	}
 
 	return expenses, total, nil
 }
 
-// Delete 删除消费记录
-func (r *ExpenseRepository) Delete(id string) error {
-	result := r.db.Delete(&models.Expense{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
+// ForEach 按查询条件逐行遍历消费记录（不分页，用于导出等大批量场景）
+func (r *ExpenseRepository) ForEach(query *models.ExpenseQuery, fn func(expense *models.Expense) error) error {
+	baseQuery := query.ApplyToQuery(r.db.Model(&models.Expense{}))
+	baseQuery = query.ApplySort(baseQuery)
+
+	rows, err := baseQuery.Rows()
+	if err != nil {
+		return fmt.Errorf("查询消费记录失败: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("记录不存在")
+	defer rows.Close()
+
+	for rows.Next() {
+		var expense models.Expense
+		if err := r.db.ScanRows(rows, &expense); err != nil {
+			return fmt.Errorf("读取消费记录失败: %w", err)
+		}
+		if err := fn(&expense); err != nil {
+			return err
+		}
 	}
-	return nil
+	return rows.Err()
+}
+
+// Delete 删除消费记录及其标签关联和分摊
+func (r *ExpenseRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Expense{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("记录不存在")
+		}
+		detail := tx.Session(&gorm.Session{NewDB: true})
+		if err := detail.Where("expense_id = ?", id).Delete(&models.ExpenseTag{}).Error; err != nil {
+			return err
+		}
+		return detail.Where("expense_id = ?", id).Delete(&models.ExpenseSplit{}).Error
+	})
 }
 
 // GetStatistics 获取统计数据
@@ -92,7 +152,44 @@ func (r *ExpenseRepository) GetStatistics(query *models.ExpenseQuery) (*models.E
 			return nil, fmt.Errorf("查询参数验证失败: %w", err)
 		}
 	}
-	return models.GetStatsWithSQL(r.db, query)
+	base, err := r.baseCurrency()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := models.GetStatsWithSQL(r.db, query, base)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []models.Category
+	if err := r.db.Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("获取分类失败: %w", err)
+	}
+	stats.CategoryDistribution = models.RollupCategoryStats(categories, stats.TypeDistribution, stats.Count)
+	return stats, nil
+}
+
+// SumByPeriod 按统计周期汇总支出金额，金额折算为家庭本位币，缺少汇率的记录不计入
+func (r *ExpenseRepository) SumByPeriod(query *models.ExpenseQuery, granularity string) (map[string]models.Money, error) {
+	amount, err := r.baseAmount()
+	if err != nil {
+		return nil, err
+	}
+	return sumByPeriod(r.db, &models.Expense{}, query, granularity, amount)
+}
+
+// SumByPeriodGrouped 按统计周期和分组汇总消费金额与笔数，金额折算为家庭本位币，缺少汇率的记录不计入
+func (r *ExpenseRepository) SumByPeriodGrouped(query *models.ExpenseQuery, granularity, groupBy string) ([]models.PeriodTotal, error) {
+	amount, err := r.baseAmount()
+	if err != nil {
+		return nil, err
+	}
+	return sumByPeriodGrouped(r.db, &models.Expense{}, query, granularity, groupBy, amount)
+}
+
+// DateBounds 获取满足查询条件的最早和最晚消费日期
+func (r *ExpenseRepository) DateBounds(query *models.ExpenseQuery) (string, string, error) {
+	return dateBounds(r.db, &models.Expense{}, query)
 }
 
 // GetMeta 获取元数据
@@ -128,41 +225,89 @@ func (r *ExpenseRepository) Exists(id string) (bool, error) {
 	return count > 0, nil
 }
 
+// IsDuplicate 检查是否已存在类型、金额、日期、备注完全相同的记录
+func (r *ExpenseRepository) IsDuplicate(expense *models.Expense) (bool, error) {
+	remark := ""
+	if expense.Remark != nil {
+		remark = *expense.Remark
+	}
+
+	var count int64
+	if err := r.db.Model(&models.Expense{}).
+		Where("type = ? AND amount = ? AND date = ? AND COALESCE(remark, '') = ?",
+			expense.Type, expense.Amount, expense.Date, remark).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // BatchCreate 批量创建消费记录
 func (r *ExpenseRepository) BatchCreate(expenses []models.Expense) error {
 	if len(expenses) == 0 {
 		return nil
 	}
 
+	base, err := r.baseCurrency()
+	if err != nil {
+		return err
+	}
+
 	// 验证所有记录
 	for i, expense := range expenses {
 		if err := expense.Validate(); err != nil {
 			return fmt.Errorf("第%d条记录验证失败: %w", i+1, err)
 		}
+		if r.householdID != nil {
+			expenses[i].HouseholdID = r.householdID
+		}
+		fillCurrency(&expenses[i], base)
+		normalizeSplit(&expenses[i])
 	}
 
-	// 分批处理，每批50条记录
+	// 分批处理，每批50条记录，带标签或分摊的记录在同一事务中保存标签和分摊
 	batchSize := 50
-	for i := 0; i < len(expenses); i += batchSize {
-		end := i + batchSize
-		if end > len(expenses) {
-			end = len(expenses)
-		}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := 0; i < len(expenses); i += batchSize {
+			end := i + batchSize
+			if end > len(expenses) {
+				end = len(expenses)
+			}
 
-		batch := expenses[i:end]
-		if err := r.db.CreateInBatches(batch, batchSize).Error; err != nil {
-			return fmt.Errorf("第%d批数据创建失败: %w", i/batchSize+1, err)
+			batch := expenses[i:end]
+			if err := tx.CreateInBatches(batch, batchSize).Error; err != nil {
+				return fmt.Errorf("第%d批数据创建失败: %w", i/batchSize+1, err)
+			}
+			for j := range batch {
+				if len(batch[j].Tags) == 0 && batch[j].PayerID == nil {
+					continue
+				}
+				if err := saveExpenseDetails(tx, &batch[j]); err != nil {
+					return fmt.Errorf("第%d条记录保存标签或分摊失败: %w", i+j+1, err)
+				}
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
-// Update 更新消费记录
+// Update 更新消费记录，标签和分摊替换为expense.Tags、expense.Splits
 func (r *ExpenseRepository) Update(expense *models.Expense) error {
 	if err := expense.Validate(); err != nil {
 		return fmt.Errorf("验证失败: %w", err)
 	}
-	return r.db.Save(expense).Error
+	base, err := r.baseCurrency()
+	if err != nil {
+		return err
+	}
+	fillCurrency(expense, base)
+	normalizeSplit(expense)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(expense).Error; err != nil {
+			return err
+		}
+		return saveExpenseDetails(tx, expense)
+	})
 }
 
 // FindAll 获取所有消费记录（用于迁移测试）
@@ -172,5 +317,8 @@ func (r *ExpenseRepository) FindAll() ([]models.Expense, error) {
 	if err := r.db.Order("date DESC").Find(&expenses).Error; err != nil {
 		return nil, err
 	}
+	if err := loadExpenseDetails(r.db, expenses); err != nil {
+		return nil, err
+	}
 	return expenses, nil
 }
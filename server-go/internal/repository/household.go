@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 家庭与登录相关错误
+var (
+	// ErrAdminExists 已有系统管理员时不能再次初始化
+	ErrAdminExists = errors.New("系统管理员已存在，不能重复初始化")
+	// ErrClaimUsed 认领码已被使用
+	ErrClaimUsed = errors.New("认领码已被使用")
+)
+
+// householdTables 按家庭隔离的账本数据表
+var householdTables = []interface{}{
+	&models.Expense{},
+	&models.Income{},
+	&models.Budget{},
+	&models.Account{},
+	&models.Transfer{},
+	&models.RecurringExpense{},
+	&models.Tag{},
+	&models.Category{},
+	&models.Settlement{},
+}
+
+// householdScope 返回只作用于指定家庭数据的查询，返回值可在多次查询间安全复用
+func householdScope(db *gorm.DB, householdID uint) *gorm.DB {
+	return db.Where("household_id = ?", householdID).Session(&gorm.Session{})
+}
+
+// AuthRepository 家庭与登录会话数据仓库
+type AuthRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthRepository 创建新的家庭与登录会话仓库
+func NewAuthRepository(db *gorm.DB) *AuthRepository {
+	return &AuthRepository{
+		db: db,
+	}
+}
+
+// FindHouseholdByID 根据ID查找家庭
+func (r *AuthRepository) FindHouseholdByID(id uint) (*models.Household, error) {
+	var household models.Household
+	if err := r.db.First(&household, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &household, nil
+}
+
+// FindHouseholdByInviteCode 根据邀请码查找家庭
+func (r *AuthRepository) FindHouseholdByInviteCode(inviteCode string) (*models.Household, error) {
+	var household models.Household
+	if err := r.db.First(&household, "invite_code = ?", inviteCode).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &household, nil
+}
+
+// RegisterMember 在一个事务中保存会员及其家庭
+// household.ID为0时创建新家庭，会员成为该家庭的所有者；加入已有家庭的会员为普通成员
+func (r *AuthRepository) RegisterMember(member *models.Member, household *models.Household) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		member.Role = models.RoleMember
+		if household.ID == 0 {
+			member.Role = models.RoleOwner
+			if err := createHousehold(tx, household); err != nil {
+				return err
+			}
+		}
+
+		member.HouseholdID = &household.ID
+		return saveMember(tx, member)
+	})
+}
+
+// BootstrapAdmin 在一个事务中创建系统管理员及其家庭，该家庭接管启用登录之前没有归属的账本数据；
+// 已有系统管理员时返回ErrAdminExists。member.ID不为空时表示为尚未设置密码的旧会员设置密码
+func (r *AuthRepository) BootstrapAdmin(member *models.Member, household *models.Household) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var admins int64
+		if err := tx.Model(&models.Member{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			return ErrAdminExists
+		}
+
+		if err := createHousehold(tx, household); err != nil {
+			return err
+		}
+		if err := claimLegacyData(tx, household.ID); err != nil {
+			return err
+		}
+
+		member.Role = models.RoleAdmin
+		member.HouseholdID = &household.ID
+		return saveMember(tx, member)
+	})
+}
+
+// SaveClaim 保存会员的认领码，同一会员已有的认领码被替换
+func (r *AuthRepository) SaveClaim(claim *models.MemberClaim) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.MemberClaim{}, "member_id = ?", claim.MemberID).Error; err != nil {
+			return err
+		}
+		return tx.Create(claim).Error
+	})
+}
+
+// FindClaimByMemberID 查找会员的认领码
+func (r *AuthRepository) FindClaimByMemberID(memberID string) (*models.MemberClaim, error) {
+	var claim models.MemberClaim
+	if err := r.db.First(&claim, "member_id = ?", memberID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// ClaimMember 在一个事务中使用认领码为会员设置密码并加入认领码指定的家庭，认领码只能使用一次；
+// 认领码已被使用时返回ErrClaimUsed
+func (r *AuthRepository) ClaimMember(member *models.Member, claim *models.MemberClaim) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.MemberClaim{}, "id = ?", claim.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrClaimUsed
+		}
+
+		member.HouseholdID = &claim.HouseholdID
+		return saveMember(tx, member)
+	})
+}
+
+// UpdateBaseCurrency 在一个事务中修改家庭本位币；
+// 币种为空的消费记录原本按旧本位币记账，先填入旧本位币，避免改为按新本位币统计
+func (r *AuthRepository) UpdateBaseCurrency(household *models.Household, currency string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		previous := household.BaseCurrency
+		if previous == "" {
+			previous = models.DefaultBaseCurrency
+		}
+		if err := tx.Model(&models.Expense{}).
+			Where("household_id = ? AND currency = ''", household.ID).
+			Update("currency", previous).Error; err != nil {
+			return fmt.Errorf("更新消费记录币种失败: %w", err)
+		}
+		return tx.Model(household).Update("base_currency", currency).Error
+	})
+}
+
+// createHousehold 验证并创建家庭
+func createHousehold(tx *gorm.DB, household *models.Household) error {
+	if err := household.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	if err := tx.Create(household).Error; err != nil {
+		return fmt.Errorf("创建家庭失败: %w", err)
+	}
+	return nil
+}
+
+// saveMember 创建会员，member.ID不为空时只更新已有会员的密码、家庭和角色
+func saveMember(tx *gorm.DB, member *models.Member) error {
+	if member.ID != "" {
+		return tx.Model(member).Updates(map[string]interface{}{
+			"password_hash": member.PasswordHash,
+			"household_id":  member.HouseholdID,
+			"role":          member.Role,
+		}).Error
+	}
+	return tx.Create(member).Error
+}
+
+// claimLegacyData 将没有归属的账本数据归入指定家庭
+func claimLegacyData(tx *gorm.DB, householdID uint) error {
+	for _, table := range householdTables {
+		if err := tx.Model(table).
+			Where("household_id IS NULL").
+			Update("household_id", householdID).Error; err != nil {
+			return fmt.Errorf("迁移历史数据失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateSession 创建登录会话
+func (r *AuthRepository) CreateSession(session *models.AuthSession) error {
+	return r.db.Create(session).Error
+}
+
+// FindSessionByTokenHash 根据令牌摘要查找登录会话
+func (r *AuthRepository) FindSessionByTokenHash(tokenHash string) (*models.AuthSession, error) {
+	var session models.AuthSession
+	if err := r.db.First(&session, "token_hash = ?", tokenHash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// DeleteSessionByTokenHash 删除登录会话
+func (r *AuthRepository) DeleteSessionByTokenHash(tokenHash string) error {
+	return r.db.Delete(&models.AuthSession{}, "token_hash = ?", tokenHash).Error
+}
+
+// DeleteExpiredSessions 删除已过期的登录会话，返回删除的数量
+func (r *AuthRepository) DeleteExpiredSessions(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", now).Delete(&models.AuthSession{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"fmt"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// IncomeRepository 收入记录数据仓库
+type IncomeRepository struct {
+	db *gorm.DB
+	// householdID 不为空时仓库只读写该家庭的数据，新建记录自动归属该家庭
+	householdID *uint
+}
+
+// NewIncomeRepository 创建新的收入记录仓库
+func NewIncomeRepository(db *gorm.DB) *IncomeRepository {
+	return &IncomeRepository{
+		db: db,
+	}
+}
+
+// ForHousehold 返回只读写指定家庭数据的收入记录仓库
+func (r *IncomeRepository) ForHousehold(householdID uint) *IncomeRepository {
+	return &IncomeRepository{
+		db:          householdScope(r.db, householdID),
+		householdID: &householdID,
+	}
+}
+
+// Create 创建收入记录
+func (r *IncomeRepository) Create(income *models.Income) error {
+	if err := income.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	if r.householdID != nil {
+		income.HouseholdID = r.householdID
+	}
+	return r.db.Create(income).Error
+}
+
+// FindByID 根据ID查找收入记录
+func (r *IncomeRepository) FindByID(id string) (*models.Income, error) {
+	var income models.Income
+	if err := r.db.First(&income, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &income, nil
+}
+
+// FindWithPagination 分页查找收入记录，筛选语法与消费记录一致
+func (r *IncomeRepository) FindWithPagination(query *models.ExpenseQuery) ([]models.Income, int64, error) {
+	var incomes []models.Income
+	var total int64
+
+	// 验证查询参数
+	if err := query.Validate(); err != nil {
+		return nil, 0, fmt.Errorf("查询参数验证失败: %w", err)
+	}
+
+	baseQuery := query.ApplyToQuery(r.db.Model(&models.Income{}))
+
+	// 计算总数
+	if err := baseQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 应用排序和分页
+	baseQuery = query.ApplySort(baseQuery).Offset(query.Offset).Limit(query.Limit)
+
+	if err := baseQuery.Find(&incomes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return incomes, total, nil
+}
+
+// Update 更新收入记录
+func (r *IncomeRepository) Update(income *models.Income) error {
+	if err := income.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	return r.db.Save(income).Error
+}
+
+// Delete 删除收入记录
+func (r *IncomeRepository) Delete(id string) error {
+	result := r.db.Delete(&models.Income{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("记录不存在")
+	}
+	return nil
+}
+
+// SumByPeriod 按统计周期汇总收入金额
+func (r *IncomeRepository) SumByPeriod(query *models.ExpenseQuery, granularity string) (map[string]models.Money, error) {
+	return sumByPeriod(r.db, &models.Income{}, query, granularity, gorm.Expr("amount"))
+}
+
+// DateBounds 获取满足查询条件的最早和最晚收入日期
+func (r *IncomeRepository) DateBounds(query *models.ExpenseQuery) (string, string, error) {
+	return dateBounds(r.db, &models.Income{}, query)
+}
@@ -51,8 +51,8 @@ func (r *MemberRepository) FindByID(id string) (*models.Member, error) {
 	return &member, nil
 }
 
-// GetOrCreate 获取或创建会员
-func (r *MemberRepository) GetOrCreate(username string) (*models.Member, error) {
+// GetOrCreate 获取或创建会员，新会员属于householdID指定的家庭
+func (r *MemberRepository) GetOrCreate(username string, householdID *uint) (*models.Member, error) {
 	if username == "" {
 		return nil, fmt.Errorf("用户名不能为空")
 	}
@@ -70,6 +70,7 @@ func (r *MemberRepository) GetOrCreate(username string) (*models.Member, error)
 	member = &models.Member{
 		Username: username,
 		IsActive: false,
+		HouseholdID: householdID,
 	}
 	if err := r.Create(member); err != nil {
 		return nil, err
@@ -165,8 +166,8 @@ func (r *MemberRepository) GetMemberWithActiveSubscription(username string) (*mo
 	// 获取当前活跃订阅
 	var activeSubscription models.UserSubscription
 	now := r.db.NowFunc()
-	err := r.db.Where("member_id = ? AND status = ? AND start_date <= ? AND end_date >= ?",
-		member.ID, "active", now, now).
+	err := r.db.Where("member_id = ? AND status IN ? AND start_date <= ? AND (end_date >= ? OR grace_ends_at >= ?)",
+		member.ID, models.SubscriptionAccessStatuses, now, now, now).
 		Preload("Plan").
 		First(&activeSubscription).Error
 
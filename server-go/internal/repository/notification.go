@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"time"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository 通知发送记录和站内信数据仓库
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建新的通知仓库
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// WasSent 检查通知是否已通过该渠道成功发送给会员
+func (r *NotificationRepository) WasSent(dedupKey, channel, memberID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.NotificationRecord{}).
+		Where("dedup_key = ? AND channel = ? AND member_id = ? AND status = ?", dedupKey, channel, memberID, models.NotificationSent).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SaveRecord 保存一次发送结果，同一通知、渠道和会员只保留一条记录，重试时更新状态并累加发送次数
+func (r *NotificationRepository) SaveRecord(record *models.NotificationRecord) error {
+	record.Attempts = 1
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "dedup_key"}, {Name: "channel"}, {Name: "member_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":     record.Status,
+			"error":      record.Error,
+			"subject":    record.Subject,
+			"locale":     record.Locale,
+			"sent_at":    record.SentAt,
+			"attempts":   gorm.Expr("notification_records.attempts + 1"),
+			"updated_at": time.Now(),
+		}),
+	}).Create(record).Error
+}
+
+// FindRecords 分页查询通知发送记录，按时间倒序
+func (r *NotificationRepository) FindRecords(query *models.NotificationQuery) ([]models.NotificationRecord, int64, error) {
+	db := r.db.Model(&models.NotificationRecord{})
+	if query.Username != "" {
+		db = db.Where("username = ?", query.Username)
+	}
+	if query.Kind != "" {
+		db = db.Where("kind = ?", query.Kind)
+	}
+	if query.Channel != "" {
+		db = db.Where("channel = ?", query.Channel)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	records := []models.NotificationRecord{}
+	if err := db.Order("updated_at DESC, id DESC").
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+// CreateInboxMessage 写入站内信
+func (r *NotificationRepository) CreateInboxMessage(message *models.InboxMessage) error {
+	return r.db.Create(message).Error
+}
+
+// FindInboxMessages 分页查询会员的站内信，按时间倒序，unreadOnly为true时只返回未读
+func (r *NotificationRepository) FindInboxMessages(memberID string, unreadOnly bool, limit, offset int) ([]models.InboxMessage, int64, int64, error) {
+	db := r.db.Model(&models.InboxMessage{}).Where("member_id = ?", memberID)
+
+	var unread int64
+	if err := db.Session(&gorm.Session{}).Where("read_at IS NULL").Count(&unread).Error; err != nil {
+		return nil, 0, 0, err
+	}
+	if unreadOnly {
+		db = db.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	messages := []models.InboxMessage{}
+	if err := db.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&messages).Error; err != nil {
+		return nil, 0, 0, err
+	}
+	return messages, total, unread, nil
+}
+
+// MarkInboxRead 将会员的站内信标记为已读，id为0时标记全部，返回标记的数量
+func (r *NotificationRepository) MarkInboxRead(memberID string, id uint) (int64, error) {
+	db := r.db.Model(&models.InboxMessage{}).Where("member_id = ? AND read_at IS NULL", memberID)
+	if id != 0 {
+		db = db.Where("id = ?", id)
+	}
+	result := db.Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// FindInboxMessage 查找会员的站内信
+func (r *NotificationRepository) FindInboxMessage(memberID string, id uint) (*models.InboxMessage, error) {
+	var message models.InboxMessage
+	if err := r.db.First(&message, "id = ? AND member_id = ?", id, memberID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+// FindHouseholdsWithBudgets 查找设置了预算的家庭
+func (r *NotificationRepository) FindHouseholdsWithBudgets() ([]uint, error) {
+	var householdIDs []uint
+	err := r.db.Model(&models.Budget{}).
+		Where("household_id IS NOT NULL").
+		Distinct().
+		Pluck("household_id", &householdIDs).Error
+	return householdIDs, err
+}
+
+// FindHouseholdMembers 查找家庭的全部成员
+func (r *NotificationRepository) FindHouseholdMembers(householdID uint) ([]models.Member, error) {
+	var members []models.Member
+	err := r.db.Where("household_id = ?", householdID).
+		Order("username ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// FindPastDueSubscriptions 查找自动续费失败、处于宽限期的订阅
+func (r *NotificationRepository) FindPastDueSubscriptions() ([]models.UserSubscription, error) {
+	var subscriptions []models.UserSubscription
+	err := r.db.Where("status = ? AND renewal_attempts > 0", models.SubscriptionPastDue).
+		Preload("Plan").
+		Preload("Member").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// UpdateSettings 更新会员的通知邮箱和语言
+func (r *NotificationRepository) UpdateSettings(memberID, email, locale string) error {
+	return r.db.Model(&models.Member{}).
+		Where("id = ?", memberID).
+		Updates(map[string]interface{}{"email": email, "locale": locale}).Error
+}
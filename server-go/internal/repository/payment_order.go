@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 支付订单状态变更错误
+var (
+	ErrPaymentOrderNotFound = errors.New("支付订单不存在")
+	ErrPaymentOrderSettled  = errors.New("支付订单已处理，状态不可变更")
+	ErrPaymentAmountInvalid = errors.New("回调金额与订单金额不一致")
+	ErrOrderNotRefundable   = errors.New("只有已支付的订单可以退款")
+	ErrProratedUnsupported  = errors.New("只有订阅订单支持按剩余天数退款")
+	ErrNothingToRefund      = errors.New("订阅已无剩余天数，无可退金额")
+)
+
+// PaymentOrderRepository 支付订单数据仓库
+type PaymentOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentOrderRepository 创建新的支付订单仓库
+func NewPaymentOrderRepository(db *gorm.DB) *PaymentOrderRepository {
+	return &PaymentOrderRepository{
+		db: db,
+	}
+}
+
+// Create 创建支付订单，网关订单号已存在时（相同幂等键重试）返回已有订单
+func (r *PaymentOrderRepository) Create(order *models.PaymentOrder) (*models.PaymentOrder, error) {
+	existing, err := r.FindByOrderID(order.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	if err := r.db.Create(order).Error; err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CreateDonationOrder 在一个事务中创建捐赠支付订单和对应的待支付捐赠记录，
+// 网关订单号已存在时返回已有订单且不重复创建捐赠记录
+func (r *PaymentOrderRepository) CreateDonationOrder(order *models.PaymentOrder, record *models.DonationRecord) (*models.PaymentOrder, error) {
+	existing, err := r.FindByOrderID(order.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		record.ID = order.ID
+		record.OrderID = order.OrderID
+		record.Status = models.DonationPending
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// FindByOrderID 根据网关订单号查找支付订单
+func (r *PaymentOrderRepository) FindByOrderID(orderID string) (*models.PaymentOrder, error) {
+	var order models.PaymentOrder
+	if err := r.db.First(&order, "order_id = ?", orderID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// MarkFailed 将待支付订单标记为失败，捐赠订单的捐赠记录同时标记为失败
+func (r *PaymentOrderRepository) MarkFailed(orderID, transactionID string) (*models.PaymentOrder, error) {
+	var order models.PaymentOrder
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := loadPendingOrder(tx, orderID, &order); err != nil {
+			return err
+		}
+		if order.Kind == models.PaymentKindDonation {
+			if err := updateDonationStatus(tx, order.OrderID, models.DonationFailed); err != nil {
+				return err
+			}
+		}
+		return transitionOrder(tx, &order, models.PaymentOrderPending, map[string]interface{}{
+			"status":         models.PaymentOrderFailed,
+			"transaction_id": transactionID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindStalePendingRenewals 查找创建时间不晚于before且仍在等待支付网关回调的自动续费订单
+func (r *PaymentOrderRepository) FindStalePendingRenewals(before time.Time) ([]models.PaymentOrder, error) {
+	var orders []models.PaymentOrder
+	err := r.db.Where("kind = ? AND renewal = ? AND status = ? AND created_at <= ?",
+		models.PaymentKindSubscription, true, models.PaymentOrderPending, before).
+		Order("created_at ASC").
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// MarkPaid 在一个事务中将待支付订单标记为已支付；订阅订单同时创建订阅，
+// 已有未到期订阅时从其结束时间起延长，自动续费订单延长订单指定的订阅，订阅的PaymentID记为网关交易号；
+// 捐赠订单同时将捐赠记录标记为成功
+func (r *PaymentOrderRepository) MarkPaid(orderID, transactionID string, amount models.Money) (*models.PaymentOrder, error) {
+	var order models.PaymentOrder
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := loadPendingOrder(tx, orderID, &order); err != nil {
+			return err
+		}
+		if order.Amount != amount {
+			return ErrPaymentAmountInvalid
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":         models.PaymentOrderPaid,
+			"transaction_id": transactionID,
+			"paid_at":        now,
+		}
+		switch order.Kind {
+		case models.PaymentKindSubscription:
+			activate := activateSubscription
+			if order.Renewal {
+				activate = renewSubscriptionFromOrder
+			}
+			subscription, err := activate(tx, &order, transactionID, now)
+			if err != nil {
+				return err
+			}
+			updates["subscription_id"] = subscription.ID
+		case models.PaymentKindDonation:
+			if err := updateDonationStatus(tx, order.OrderID, models.DonationSuccess); err != nil {
+				return err
+			}
+		}
+		return transitionOrder(tx, &order, models.PaymentOrderPending, updates)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// QuoteRefund 计算订单的退款金额和订阅需要扣除的天数，不修改数据
+// 全额退款扣除订单购买的全部天数；按比例退款按订阅剩余天数（不超过计划天数）折算金额
+func (r *PaymentOrderRepository) QuoteRefund(order *models.PaymentOrder, mode string, now time.Time) (*models.PaymentRefund, error) {
+	if order.Status != models.PaymentOrderPaid {
+		return nil, ErrOrderNotRefundable
+	}
+
+	refund := &models.PaymentRefund{
+		PaymentOrderID: order.ID,
+		OrderID:        order.OrderID,
+		Mode:           mode,
+		Amount:         order.Amount,
+	}
+	if order.Kind != models.PaymentKindSubscription {
+		if mode == models.RefundProrated {
+			return nil, ErrProratedUnsupported
+		}
+		return refund, nil
+	}
+
+	var plan models.SubscriptionPlan
+	if err := r.db.Unscoped().First(&plan, "id = ?", order.PlanID).Error; err != nil {
+		return nil, fmt.Errorf("获取订阅计划失败: %w", err)
+	}
+	var subscription models.UserSubscription
+	if err := r.db.First(&subscription, "id = ?", order.SubscriptionID).Error; err != nil {
+		return nil, fmt.Errorf("获取订阅失败: %w", err)
+	}
+	refund.SubscriptionID = subscription.ID
+	refund.Days = plan.Duration
+
+	if mode == models.RefundProrated {
+		remaining := 0
+		if models.SubscriptionGrantsAccess(subscription.Status) && subscription.EndDate.After(now) {
+			remaining = int(math.Ceil(subscription.EndDate.Sub(now).Hours() / 24))
+		}
+		if remaining > plan.Duration {
+			remaining = plan.Duration
+		}
+		if remaining == 0 || plan.Duration <= 0 {
+			return nil, ErrNothingToRefund
+		}
+		refund.Days = remaining
+		refund.Amount = models.Money(math.Round(float64(order.Amount) * float64(remaining) / float64(plan.Duration)))
+	}
+	return refund, nil
+}
+
+// ApplyRefund 在一个事务中记录退款流水、将订单标记为已退款，并扣除订阅天数或将捐赠记录标记为已退款；
+// 扣除后订阅不再有剩余时间时订阅被取消
+func (r *PaymentOrderRepository) ApplyRefund(refund *models.PaymentRefund, now time.Time) (*models.PaymentOrder, error) {
+	var order models.PaymentOrder
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&order, "id = ?", refund.PaymentOrderID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrPaymentOrderNotFound
+			}
+			return err
+		}
+		if order.Status != models.PaymentOrderPaid {
+			return ErrOrderNotRefundable
+		}
+
+		switch order.Kind {
+		case models.PaymentKindSubscription:
+			if err := shortenSubscription(tx, refund, now); err != nil {
+				return err
+			}
+		case models.PaymentKindDonation:
+			if err := updateDonationStatus(tx, order.OrderID, models.DonationRefunded); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+		return transitionOrder(tx, &order, models.PaymentOrderPaid, map[string]interface{}{
+			"status":          models.PaymentOrderRefunded,
+			"refunded_amount": refund.Amount,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindRefunds 获取订单的退款流水
+func (r *PaymentOrderRepository) FindRefunds(paymentOrderID string) ([]models.PaymentRefund, error) {
+	var refunds []models.PaymentRefund
+	if err := r.db.Where("payment_order_id = ?", paymentOrderID).Order("created_at ASC").Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// shortenSubscription 从订阅结束时间中扣除退款对应的天数，扣除后已到期则取消订阅；
+// 已取消或已过期的订阅不再调整
+func shortenSubscription(tx *gorm.DB, refund *models.PaymentRefund, now time.Time) error {
+	var subscription models.UserSubscription
+	if err := tx.First(&subscription, "id = ?", refund.SubscriptionID).Error; err != nil {
+		return fmt.Errorf("获取订阅失败: %w", err)
+	}
+	if subscription.Status == models.SubscriptionCanceled || subscription.Status == models.SubscriptionExpired {
+		return nil
+	}
+
+	reason := fmt.Sprintf("退款扣除%d天", refund.Days)
+	if refund.Reason != "" {
+		reason += ": " + refund.Reason
+	}
+
+	subscription.EndDate = subscription.EndDate.AddDate(0, 0, -refund.Days)
+	to := subscription.Status
+	if !subscription.EndDate.After(now) {
+		subscription.EndDate = now
+		to = models.SubscriptionCanceled
+	}
+	return transitionSubscription(tx, &subscription, to, refund.Operator, reason)
+}
+
+// loadPendingOrder 读取订单并确认仍为待支付状态
+func loadPendingOrder(tx *gorm.DB, orderID string, order *models.PaymentOrder) error {
+	if err := tx.First(order, "order_id = ?", orderID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrPaymentOrderNotFound
+		}
+		return err
+	}
+	if order.Status != models.PaymentOrderPending {
+		return ErrPaymentOrderSettled
+	}
+	return nil
+}
+
+// transitionOrder 仅当订单仍为from状态时更新，防止并发回调或重复退款
+func transitionOrder(tx *gorm.DB, order *models.PaymentOrder, from string, updates map[string]interface{}) error {
+	result := tx.Model(&models.PaymentOrder{}).
+		Where("id = ? AND status = ?", order.ID, from).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPaymentOrderSettled
+	}
+	return tx.First(order, "id = ?", order.ID).Error
+}
+
+// activateSubscription 为已支付的订阅订单创建或延长订阅；
+// 试用中、生效中或宽限期内的订阅从其结束时间（已过则从当前时间）起延长并转为生效
+func activateSubscription(tx *gorm.DB, order *models.PaymentOrder, transactionID string, now time.Time) (*models.UserSubscription, error) {
+	var plan models.SubscriptionPlan
+	if err := tx.First(&plan, "id = ?", order.PlanID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("订阅计划不存在")
+		}
+		return nil, err
+	}
+
+	var current models.UserSubscription
+	err := tx.Where("member_id = ? AND status IN ? AND (end_date >= ? OR status = ?)",
+		order.MemberID, models.SubscriptionAccessStatuses, now, models.SubscriptionPastDue).
+		Order("end_date DESC").
+		First(&current).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	reason := "支付订单" + order.OrderID
+	if err == nil {
+		base := current.EndDate
+		if base.Before(now) {
+			base = now
+		}
+		current.PlanID = plan.ID
+		current.EndDate = base.AddDate(0, 0, plan.Duration)
+		current.PaymentID = transactionID
+		if err := transitionSubscription(tx, &current, models.SubscriptionActive, ActorGateway, reason); err != nil {
+			return nil, err
+		}
+		return &current, nil
+	}
+
+	subscription := &models.UserSubscription{
+		MemberID:  order.MemberID,
+		PlanID:    plan.ID,
+		StartDate: now,
+		EndDate:   now.AddDate(0, 0, plan.Duration),
+		Status:    models.SubscriptionActive,
+		PaymentID: transactionID,
+	}
+	if err := createSubscription(tx, subscription, ActorGateway, reason); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"fmt"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecurringExpenseRepository 周期性消费模板数据仓库
+type RecurringExpenseRepository struct {
+	db *gorm.DB
+	// householdID 不为空时仓库只读写该家庭的数据，新建记录自动归属该家庭
+	householdID *uint
+}
+
+// NewRecurringExpenseRepository 创建新的周期性消费模板仓库
+func NewRecurringExpenseRepository(db *gorm.DB) *RecurringExpenseRepository {
+	return &RecurringExpenseRepository{
+		db: db,
+	}
+}
+
+// ForHousehold 返回只读写指定家庭数据的周期性消费模板仓库
+func (r *RecurringExpenseRepository) ForHousehold(householdID uint) *RecurringExpenseRepository {
+	return &RecurringExpenseRepository{
+		db:          householdScope(r.db, householdID),
+		householdID: &householdID,
+	}
+}
+
+// Create 创建周期性消费模板
+func (r *RecurringExpenseRepository) Create(template *models.RecurringExpense) error {
+	if err := template.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	if r.householdID != nil {
+		template.HouseholdID = r.householdID
+	}
+	return r.db.Create(template).Error
+}
+
+// FindByID 根据ID查找周期性消费模板
+func (r *RecurringExpenseRepository) FindByID(id string) (*models.RecurringExpense, error) {
+	var template models.RecurringExpense
+	if err := r.db.First(&template, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// FindAll 获取所有周期性消费模板
+func (r *RecurringExpenseRepository) FindAll() ([]models.RecurringExpense, error) {
+	var templates []models.RecurringExpense
+	if err := r.db.Order("id ASC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Update 更新周期性消费模板
+func (r *RecurringExpenseRepository) Update(template *models.RecurringExpense) error {
+	if err := template.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	return r.db.Save(template).Error
+}
+
+// Delete 删除周期性消费模板，已生成的消费记录保留
+func (r *RecurringExpenseRepository) Delete(id string) error {
+	result := r.db.Delete(&models.RecurringExpense{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("记录不存在")
+	}
+	return nil
+}
+
+// MaterializeDue 为所有启用的模板生成截至today（含当天）到期的消费记录，返回新生成的条数
+// 每个模板在一个事务中写入记录并推进LastGeneratedDate；
+// 同时依赖 (recurring_id, date) 唯一索引，即使重启或并发执行也不会重复生成
+func (r *RecurringExpenseRepository) MaterializeDue(today string) (int, error) {
+	var templates []models.RecurringExpense
+	if err := r.db.Where("active = ?", true).Find(&templates).Error; err != nil {
+		return 0, fmt.Errorf("获取周期模板失败: %w", err)
+	}
+
+	created := 0
+	for i := range templates {
+		template := &templates[i]
+		dates := template.Occurrences(template.LastGeneratedDate, today, 0)
+		if len(dates) == 0 {
+			continue
+		}
+
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			for _, date := range dates {
+				expense := template.ToExpense(date)
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&expense)
+				if result.Error != nil {
+					return result.Error
+				}
+				created += int(result.RowsAffected)
+			}
+			return tx.Model(template).Update("last_generated_date", dates[len(dates)-1]).Error
+		})
+		if err != nil {
+			return created, fmt.Errorf("周期模板%d生成消费记录失败: %w", template.ID, err)
+		}
+	}
+
+	return created, nil
+}
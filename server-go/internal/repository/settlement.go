@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"fmt"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SettlementRepository 共同消费结算数据仓库
+type SettlementRepository struct {
+	db *gorm.DB
+	// householdID 不为空时仓库只读写该家庭的数据，新建记录自动归属该家庭
+	householdID *uint
+}
+
+// NewSettlementRepository 创建新的结算仓库
+func NewSettlementRepository(db *gorm.DB) *SettlementRepository {
+	return &SettlementRepository{
+		db: db,
+	}
+}
+
+// ForHousehold 返回只读写指定家庭数据的结算仓库
+func (r *SettlementRepository) ForHousehold(householdID uint) *SettlementRepository {
+	return &SettlementRepository{
+		db:          householdScope(r.db, householdID),
+		householdID: &householdID,
+	}
+}
+
+// FindAll 获取结算记录，按日期从新到旧排列
+func (r *SettlementRepository) FindAll() ([]models.Settlement, error) {
+	settlements := []models.Settlement{}
+	if err := r.db.Order("date DESC, id DESC").Find(&settlements).Error; err != nil {
+		return nil, err
+	}
+	return settlements, nil
+}
+
+// Create 记录一笔结算，双方都必须是本家庭成员
+func (r *SettlementRepository) Create(settlement *models.Settlement) error {
+	if err := settlement.Validate(); err != nil {
+		return fmt.Errorf("验证失败: %w", err)
+	}
+	if r.householdID != nil {
+		settlement.HouseholdID = r.householdID
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkHouseholdMembers(tx, r.householdID, []string{settlement.FromMemberID, settlement.ToMemberID}); err != nil {
+			return err
+		}
+		return tx.Create(settlement).Error
+	})
+}
+
+// SettleAll 在一个事务中按建议转账记录结算，使全部成员的余额归零，返回新建的结算记录
+func (r *SettlementRepository) SettleAll(date string, remark *string) ([]models.Settlement, error) {
+	created := []models.Settlement{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		report, err := buildBalanceReport(tx, r.householdID)
+		if err != nil {
+			return err
+		}
+		for _, transfer := range report.Transfers {
+			settlement := models.Settlement{
+				FromMemberID: transfer.FromMemberID,
+				ToMemberID:   transfer.ToMemberID,
+				Amount:       transfer.Amount,
+				Date:         date,
+				Remark:       remark,
+				HouseholdID:  r.householdID,
+			}
+			if err := settlement.Validate(); err != nil {
+				return fmt.Errorf("验证失败: %w", err)
+			}
+			if err := tx.Create(&settlement).Error; err != nil {
+				return err
+			}
+			created = append(created, settlement)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Delete 删除结算记录
+func (r *SettlementRepository) Delete(id string) error {
+	result := r.db.Delete(&models.Settlement{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("记录不存在")
+	}
+	return nil
+}
+
+// GetBalances 计算家庭成员之间的共同消费余额、两两欠款和建议转账
+func (r *SettlementRepository) GetBalances() (*models.BalanceReport, error) {
+	return buildBalanceReport(r.db, r.householdID)
+}
+
+// buildBalanceReport 汇总分摊欠款和结算记录，db 需带有家庭条件，分摊金额按消费记录的币种折算为家庭本位币
+func buildBalanceReport(db *gorm.DB, householdID *uint) (*models.BalanceReport, error) {
+	base, err := householdBaseCurrency(db, householdID)
+	if err != nil {
+		return nil, err
+	}
+	amount := models.SplitBaseAmountExpr(base)
+
+	var members []models.Member
+	if err := db.Order("username ASC").Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("获取家庭成员失败: %w", err)
+	}
+	names := make(map[string]string, len(members))
+	for _, member := range members {
+		names[member.ID] = member.Username
+	}
+
+	// 每个参与人欠付款人的金额，付款人自己承担的部分不产生欠款
+	var shareRows []struct {
+		FromID      string
+		ToID        string
+		Amount      models.Money
+		Unconverted int
+	}
+	expenseIDs := db.Model(&models.Expense{}).Select("id")
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Table("expense_splits").
+		Select("expense_splits.member_id AS from_id, expenses.payer_id AS to_id, COALESCE(SUM(?), 0) AS amount, COUNT(*) - COUNT(?) AS unconverted", amount, amount).
+		Joins("JOIN expenses ON expenses.id = expense_splits.expense_id").
+		Where("expense_splits.expense_id IN (?)", expenseIDs).
+		Where("expenses.payer_id IS NOT NULL AND expense_splits.member_id <> expenses.payer_id").
+		Group("expense_splits.member_id, expenses.payer_id").
+		Scan(&shareRows).Error; err != nil {
+		return nil, fmt.Errorf("汇总分摊失败: %w", err)
+	}
+
+	var settlementRows []struct {
+		FromID string
+		ToID   string
+		Amount models.Money
+	}
+	if err := db.Model(&models.Settlement{}).
+		Select("from_member_id AS from_id, to_member_id AS to_id, COALESCE(SUM(amount), 0) AS amount").
+		Group("from_member_id, to_member_id").
+		Scan(&settlementRows).Error; err != nil {
+		return nil, fmt.Errorf("汇总结算失败: %w", err)
+	}
+
+	shares := make([]models.PairAmount, len(shareRows))
+	unconverted := 0
+	for i, row := range shareRows {
+		shares[i] = models.PairAmount{From: row.FromID, To: row.ToID, Amount: row.Amount}
+		unconverted += row.Unconverted
+	}
+	settlements := make([]models.PairAmount, len(settlementRows))
+	for i, row := range settlementRows {
+		settlements[i] = models.PairAmount{From: row.FromID, To: row.ToID, Amount: row.Amount}
+	}
+	report := models.BuildBalanceReport(names, shares, settlements)
+	report.UnconvertedCount = unconverted
+	return report, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrMemberNotInHousehold 付款人或分摊参与人不是本家庭成员
+var ErrMemberNotInHousehold = errors.New("付款人和分摊参与人必须是本家庭成员")
+
+// checkHouseholdMembers 检查成员都属于指定家庭，未启用登录时只检查成员存在
+func checkHouseholdMembers(tx *gorm.DB, householdID *uint, memberIDs []string) error {
+	unique := make(map[string]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		unique[id] = true
+	}
+	ids := make([]string, 0, len(unique))
+	for id := range unique {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var count int64
+	query := tx.Session(&gorm.Session{NewDB: true}).Model(&models.Member{}).Where("id IN ?", ids)
+	if householdID != nil {
+		query = query.Where("household_id = ?", *householdID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		return ErrMemberNotInHousehold
+	}
+	return nil
+}
+
+// setExpenseSplits 将消费记录的分摊替换为按 expense.Splits 计算的结果
+func setExpenseSplits(tx *gorm.DB, expense *models.Expense) error {
+	shares, err := expense.SplitShares()
+	if err != nil {
+		return err
+	}
+	if expense.PayerID != nil {
+		memberIDs := []string{*expense.PayerID}
+		for _, share := range shares {
+			memberIDs = append(memberIDs, share.MemberID)
+		}
+		if err := checkHouseholdMembers(tx, expense.HouseholdID, memberIDs); err != nil {
+			return err
+		}
+	}
+
+	// 分摊表没有家庭列，不能沿用仓库的家庭条件
+	tx = tx.Session(&gorm.Session{NewDB: true})
+	if err := tx.Where("expense_id = ?", expense.ID).Delete(&models.ExpenseSplit{}).Error; err != nil {
+		return fmt.Errorf("清除分摊失败: %w", err)
+	}
+	if len(shares) > 0 {
+		if err := tx.Create(&shares).Error; err != nil {
+			return fmt.Errorf("保存分摊失败: %w", err)
+		}
+	}
+	expense.Splits = shares
+	return nil
+}
+
+// loadExpenseSplits 一次查询填充多条消费记录的分摊
+func loadExpenseSplits(db *gorm.DB, expenses []models.Expense) error {
+	ids := make([]uint, 0, len(expenses))
+	for i := range expenses {
+		if expenses[i].SplitMode != "" {
+			ids = append(ids, expenses[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var splits []models.ExpenseSplit
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Where("expense_id IN ?", ids).
+		Order("expense_id ASC, amount DESC, member_id ASC").
+		Find(&splits).Error; err != nil {
+		return fmt.Errorf("读取分摊失败: %w", err)
+	}
+
+	byExpense := make(map[uint][]models.ExpenseSplit, len(ids))
+	for _, split := range splits {
+		byExpense[split.ExpenseID] = append(byExpense[split.ExpenseID], split)
+	}
+	for i := range expenses {
+		expenses[i].Splits = byExpense[expenses[i].ID]
+	}
+	return nil
+}
+
+// normalizeSplit 没有分摊参与人时清除分摊方式，须在保存消费记录之前调用
+func normalizeSplit(expense *models.Expense) {
+	if !expense.IsShared() {
+		expense.SplitMode = ""
+	}
+}
+
+// saveExpenseDetails 保存消费记录的标签和分摊，须在保存消费记录之后调用
+func saveExpenseDetails(tx *gorm.DB, expense *models.Expense) error {
+	if err := setExpenseTags(tx, expense); err != nil {
+		return err
+	}
+	return setExpenseSplits(tx, expense)
+}
+
+// loadExpenseDetails 填充消费记录的标签和分摊
+func loadExpenseDetails(db *gorm.DB, expenses []models.Expense) error {
+	if err := loadExpenseTags(db, expenses); err != nil {
+		return err
+	}
+	return loadExpenseSplits(db, expenses)
+}
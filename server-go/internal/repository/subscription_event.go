@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 事件中的系统操作人
+const (
+	ActorSystem  = "system"
+	ActorGateway = "gateway"
+)
+
+// ErrSubscriptionChanged 订阅状态在读取后已被其他请求修改
+var ErrSubscriptionChanged = errors.New("订阅状态已被修改，请重试")
+
+// createSubscription 创建订阅并记录新建事件，调用方负责事务
+func createSubscription(tx *gorm.DB, subscription *models.UserSubscription, actor, reason string) error {
+	if err := models.CanTransitionSubscription("", subscription.Status); err != nil {
+		return err
+	}
+	if err := tx.Omit(clause.Associations).Create(subscription).Error; err != nil {
+		return err
+	}
+	return recordSubscriptionEvent(tx, subscription, "", actor, reason)
+}
+
+// transitionSubscription 校验状态流转后保存订阅（包括调用方修改的其他字段）并记录事件，调用方负责事务；
+// 只有数据库中的状态仍为读取时的状态才会更新，防止并发修改；转为生效时清除续费失败的重试信息
+func transitionSubscription(tx *gorm.DB, subscription *models.UserSubscription, to, actor, reason string) error {
+	from := subscription.Status
+	if err := models.CanTransitionSubscription(from, to); err != nil {
+		return err
+	}
+	if to == models.SubscriptionActive {
+		subscription.ClearDunning()
+	}
+
+	subscription.Status = to
+	result := tx.Model(subscription).
+		Where("status = ?", from).
+		Omit(clause.Associations).
+		Select("*").
+		Updates(subscription)
+	if result.Error != nil {
+		subscription.Status = from
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		subscription.Status = from
+		return ErrSubscriptionChanged
+	}
+	return recordSubscriptionEvent(tx, subscription, from, actor, reason)
+}
+
+// recordSubscriptionEvent 写入订阅状态变更记录
+func recordSubscriptionEvent(tx *gorm.DB, subscription *models.UserSubscription, from, actor, reason string) error {
+	return tx.Create(&models.SubscriptionEvent{
+		SubscriptionID: subscription.ID,
+		MemberID:       subscription.MemberID,
+		FromStatus:     from,
+		ToStatus:       subscription.Status,
+		Actor:          actor,
+		Reason:         reason,
+	}).Error
+}
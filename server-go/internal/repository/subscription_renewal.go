@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FindDueRenewals 查找需要自动续费扣款的订阅：开启自动续费且已到期的试用或生效订阅，
+// 以及到了重试时间的宽限期订阅；已有待支付续费订单的订阅跳过，避免重复扣款
+func (r *UserSubscriptionRepository) FindDueRenewals(now time.Time) ([]models.UserSubscription, error) {
+	pendingRenewal := r.db.Model(&models.PaymentOrder{}).
+		Select("1").
+		Where("payment_orders.subscription_id = \"UserSubscriptions\".id AND payment_orders.renewal = ? AND payment_orders.status = ?",
+			true, models.PaymentOrderPending)
+
+	var subscriptions []models.UserSubscription
+	err := r.db.Where("auto_renew = ? AND end_date <= ?", true, now).
+		Where("(status IN ? AND next_renewal_at IS NULL) OR (status = ? AND next_renewal_at <= ?)",
+			[]string{models.SubscriptionTrialing, models.SubscriptionActive}, models.SubscriptionPastDue, now).
+		Where("NOT EXISTS (?)", pendingRenewal).
+		Preload("Plan").
+		Preload("Member").
+		Order("end_date ASC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// RecordRenewalFailure 记录一次自动续费扣款失败：订阅转为宽限期（已在宽限期则只更新重试信息），
+// 按续费策略安排下一次重试，宽限期结束时间从订阅原结束时间起算
+func (r *UserSubscriptionRepository) RecordRenewalFailure(subscriptionID string, policy models.RenewalPolicy, reason string, now time.Time) (*models.UserSubscription, error) {
+	var subscription models.UserSubscription
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&subscription, "id = ?", subscriptionID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("订阅记录不存在")
+			}
+			return err
+		}
+
+		graceEndsAt := policy.GraceEndsAt(subscription.EndDate)
+		subscription.RenewalAttempts++
+		subscription.NextRenewalAt = policy.NextRetryAt(subscription.EndDate, subscription.RenewalAttempts, now)
+		subscription.GraceEndsAt = &graceEndsAt
+
+		reason = fmt.Sprintf("第%d次自动续费扣款失败: %s", subscription.RenewalAttempts, reason)
+		if subscription.Status != models.SubscriptionPastDue {
+			return transitionSubscription(tx, &subscription, models.SubscriptionPastDue, ActorSystem, reason)
+		}
+		result := tx.Model(&subscription).
+			Where("status = ?", models.SubscriptionPastDue).
+			Omit(clause.Associations).
+			Select("renewal_attempts", "next_renewal_at", "grace_ends_at").
+			Updates(&subscription)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSubscriptionChanged
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// renewSubscriptionFromOrder 自动续费订单支付成功后，从订阅原结束时间起延长一个计划周期并转为生效；
+// 订阅已取消或已过期时按普通订阅订单处理
+func renewSubscriptionFromOrder(tx *gorm.DB, order *models.PaymentOrder, transactionID string, now time.Time) (*models.UserSubscription, error) {
+	var subscription models.UserSubscription
+	if err := tx.First(&subscription, "id = ?", order.SubscriptionID).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if subscription.ID == "" || !models.SubscriptionGrantsAccess(subscription.Status) {
+		return activateSubscription(tx, order, transactionID, now)
+	}
+
+	var plan models.SubscriptionPlan
+	if err := tx.Unscoped().First(&plan, "id = ?", order.PlanID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("订阅计划不存在")
+		}
+		return nil, err
+	}
+
+	subscription.PlanID = plan.ID
+	subscription.EndDate = subscription.EndDate.AddDate(0, 0, plan.Duration)
+	subscription.PaymentID = transactionID
+	if err := transitionSubscription(tx, &subscription, models.SubscriptionActive, ActorGateway, "自动续费，支付订单"+order.OrderID); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
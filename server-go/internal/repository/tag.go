@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"fmt"
+
+	"homemoney/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// tagHouseholdScope 限定标签所属家庭，未启用登录时标签没有所属家庭
+func tagHouseholdScope(db *gorm.DB, householdID *uint) *gorm.DB {
+	if householdID == nil {
+		return db.Where("household_id IS NULL")
+	}
+	return db.Where("household_id = ?", *householdID)
+}
+
+// findOrCreateTags 按名称查找家庭的标签，不存在的标签自动创建
+func findOrCreateTags(tx *gorm.DB, householdID *uint, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		var tag models.Tag
+		if err := tagHouseholdScope(tx, householdID).
+			Where("name = ?", name).
+			FirstOrCreate(&tag, models.Tag{Name: name, HouseholdID: householdID}).Error; err != nil {
+			return nil, fmt.Errorf("保存标签失败: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// setExpenseTags 将消费记录的标签替换为expense.Tags
+func setExpenseTags(tx *gorm.DB, expense *models.Expense) error {
+	names, err := models.NormalizeTags(expense.Tags)
+	if err != nil {
+		return err
+	}
+	// 关联表没有家庭列，不能沿用仓库的家庭条件
+	tx = tx.Session(&gorm.Session{NewDB: true})
+	if err := tx.Where("expense_id = ?", expense.ID).Delete(&models.ExpenseTag{}).Error; err != nil {
+		return fmt.Errorf("清除标签失败: %w", err)
+	}
+
+	tags, err := findOrCreateTags(tx, expense.HouseholdID, names)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := tx.Create(&models.ExpenseTag{ExpenseID: expense.ID, TagID: tag.ID}).Error; err != nil {
+			return fmt.Errorf("保存标签失败: %w", err)
+		}
+	}
+	expense.Tags = names
+	return nil
+}
+
+// loadExpenseTags 一次查询填充多条消费记录的标签名称
+func loadExpenseTags(db *gorm.DB, expenses []models.Expense) error {
+	if len(expenses) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(expenses))
+	for i := range expenses {
+		ids[i] = expenses[i].ID
+	}
+
+	var rows []struct {
+		ExpenseID uint
+		Name      string
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Table("expense_tags").
+		Select("expense_tags.expense_id, tags.name").
+		Joins("JOIN tags ON tags.id = expense_tags.tag_id").
+		Where("expense_tags.expense_id IN ?", ids).
+		Order("tags.name ASC").
+		Scan(&rows).Error; err != nil {
+		return fmt.Errorf("读取标签失败: %w", err)
+	}
+
+	byExpense := make(map[uint][]string, len(expenses))
+	for _, row := range rows {
+		byExpense[row.ExpenseID] = append(byExpense[row.ExpenseID], row.Name)
+	}
+	for i := range expenses {
+		expenses[i].Tags = byExpense[expenses[i].ID]
+	}
+	return nil
+}
+
+// GetTags 获取家庭的全部标签及其使用次数，按使用次数从多到少排列
+func (r *ExpenseRepository) GetTags() ([]models.TagUsage, error) {
+	tags := []models.TagUsage{}
+	if err := tagHouseholdScope(r.db.Session(&gorm.Session{NewDB: true}).Table("tags"), r.householdID).
+		Select("tags.id, tags.name, COUNT(expense_tags.expense_id) AS count").
+		Joins("LEFT JOIN expense_tags ON expense_tags.tag_id = tags.id").
+		Group("tags.id, tags.name").
+		Order("count DESC, tags.name ASC").
+		Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -43,13 +44,13 @@ func (r *UserSubscriptionRepository) FindByID(id string) (*models.UserSubscripti
 	return &subscription, nil
 }
 
-// GetCurrentSubscription 获取用户当前活跃订阅
+// GetCurrentSubscription 获取用户当前活跃订阅，包括续费失败但仍在宽限期内的订阅
 func (r *UserSubscriptionRepository) GetCurrentSubscription(memberID string) (*models.UserSubscription, error) {
 	var subscription models.UserSubscription
 	now := time.Now()
 
-	if err := r.db.Where("member_id = ? AND status = ? AND start_date <= ? AND end_date >= ?",
-		memberID, "active", now, now).
+	if err := r.db.Where("member_id = ? AND status IN ? AND start_date <= ? AND (end_date >= ? OR grace_ends_at >= ?)",
+		memberID, models.SubscriptionAccessStatuses, now, now, now).
 		Preload("Plan").
 		First(&subscription).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -72,8 +73,8 @@ func (r *UserSubscriptionRepository) GetMemberSubscriptions(memberID string) ([]
 	return subscriptions, nil
 }
 
-// CreateSubscription 创建订阅（带自动计算结束时间）
-func (r *UserSubscriptionRepository) CreateSubscription(memberID, planID string, autoRenew bool) (*models.UserSubscription, error) {
+// CreateSubscription 创建订阅（带自动计算结束时间），trialDays大于0时创建试用订阅，试用期为trialDays天
+func (r *UserSubscriptionRepository) CreateSubscription(memberID, planID string, autoRenew bool, trialDays int, actor string) (*models.UserSubscription, error) {
 	// 获取订阅计划
 	planRepo := NewSubscriptionPlanRepository(r.db)
 	plan, err := planRepo.FindByID(planID)
@@ -87,6 +88,13 @@ func (r *UserSubscriptionRepository) CreateSubscription(memberID, planID string,
 	// 计算订阅结束时间
 	startDate := time.Now()
 	endDate := startDate.AddDate(0, 0, plan.Duration)
+	status := models.SubscriptionActive
+	reason := "手工开通订阅"
+	if trialDays > 0 {
+		endDate = startDate.AddDate(0, 0, trialDays)
+		status = models.SubscriptionTrialing
+		reason = fmt.Sprintf("开通%d天试用", trialDays)
+	}
 
 	// 创建订阅
 	subscription := &models.UserSubscription{
@@ -94,19 +102,22 @@ func (r *UserSubscriptionRepository) CreateSubscription(memberID, planID string,
 		PlanID:     planID,
 		StartDate:  startDate,
 		EndDate:    endDate,
-		Status:     "active",
+		Status:     status,
 		AutoRenew:  autoRenew,
 		PaymentID:  "",
 	}
 
-	if err := r.Create(subscription); err != nil {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		return createSubscription(tx, subscription, actor, reason)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return subscription, nil
 }
 
 // CancelSubscription 取消订阅
-func (r *UserSubscriptionRepository) CancelSubscription(subscriptionID string) error {
+func (r *UserSubscriptionRepository) CancelSubscription(subscriptionID, actor, reason string) error {
 	subscription, err := r.FindByID(subscriptionID)
 	if err != nil {
 		return err
@@ -116,12 +127,11 @@ func (r *UserSubscriptionRepository) CancelSubscription(subscriptionID string) e
 	}
 
 	// 更新订阅状态为已取消
-	subscription.Status = "canceled"
-	return r.db.Save(subscription).Error
+	return r.Transition(subscription, models.SubscriptionCanceled, actor, reason)
 }
 
 // ExpireSubscription 使订阅过期
-func (r *UserSubscriptionRepository) ExpireSubscription(subscriptionID string) error {
+func (r *UserSubscriptionRepository) ExpireSubscription(subscriptionID, actor, reason string) error {
 	subscription, err := r.FindByID(subscriptionID)
 	if err != nil {
 		return err
@@ -131,33 +141,57 @@ func (r *UserSubscriptionRepository) ExpireSubscription(subscriptionID string) e
 	}
 
 	// 更新订阅状态为已过期
-	subscription.Status = "expired"
-	return r.db.Save(subscription).Error
+	return r.Transition(subscription, models.SubscriptionExpired, actor, reason)
 }
 
-// Update 更新订阅
-func (r *UserSubscriptionRepository) Update(subscription *models.UserSubscription) error {
-	if subscription.ID == "" {
-		return fmt.Errorf("订阅ID不能为空")
+// Transition 按状态机变更订阅状态，同时保存订阅的其他字段并记录变更事件
+func (r *UserSubscriptionRepository) Transition(subscription *models.UserSubscription, to, actor, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return transitionSubscription(tx, subscription, to, actor, reason)
+	})
+}
+
+// GetEvents 获取订阅的状态变更记录，按时间先后排序
+func (r *UserSubscriptionRepository) GetEvents(subscriptionID string) ([]models.SubscriptionEvent, error) {
+	events := []models.SubscriptionEvent{}
+	if err := r.db.Where("subscription_id = ?", subscriptionID).
+		Order("id ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
 	}
-	return r.db.Save(subscription).Error
+	return events, nil
 }
 
 // CheckAndExpireSubscriptions 检查并处理过期订阅
 func (r *UserSubscriptionRepository) CheckAndExpireSubscriptions() error {
 	now := time.Now()
 	
-	// 查找所有已过期但状态仍为活跃的订阅
+	// 查找所有已过期但状态仍为试用或活跃的订阅，开启自动续费的试用和活跃订阅由续费任务处理
 	var expiredSubscriptions []models.UserSubscription
-	if err := r.db.Where("status = ? AND end_date < ?", "active", now).
+	if err := r.db.Where("end_date < ? AND status IN ? AND auto_renew = ?",
+		now, []string{models.SubscriptionTrialing, models.SubscriptionActive}, false).
 		Find(&expiredSubscriptions).Error; err != nil {
 		return err
 	}
 
-	// 更新过期订阅的状态
-	for _, subscription := range expiredSubscriptions {
-		subscription.Status = "expired"
-		if err := r.db.Save(&subscription).Error; err != nil {
+	// 更新过期订阅的状态，已被其他请求修改的订阅跳过
+	for i := range expiredSubscriptions {
+		err := r.Transition(&expiredSubscriptions[i], models.SubscriptionExpired, ActorSystem, "订阅到期")
+		if err != nil && !errors.Is(err, ErrSubscriptionChanged) {
+			return err
+		}
+	}
+
+	// 宽限期结束仍未续费成功的订阅过期，没有宽限期信息的（如手工设置）按原结束时间处理
+	var pastDueSubscriptions []models.UserSubscription
+	if err := r.db.Where("status = ? AND ((grace_ends_at IS NOT NULL AND grace_ends_at < ?) OR (grace_ends_at IS NULL AND end_date < ?))",
+		models.SubscriptionPastDue, now, now).
+		Find(&pastDueSubscriptions).Error; err != nil {
+		return err
+	}
+	for i := range pastDueSubscriptions {
+		err := r.Transition(&pastDueSubscriptions[i], models.SubscriptionExpired, ActorSystem, "宽限期结束，自动续费未成功")
+		if err != nil && !errors.Is(err, ErrSubscriptionChanged) {
 			return err
 		}
 	}
@@ -170,61 +204,16 @@ func (r *UserSubscriptionRepository) GetExpiringSubscriptions(daysBefore int) ([
 	var subscriptions []models.UserSubscription
 	expiryDate := time.Now().AddDate(0, 0, daysBefore)
 
-	if err := r.db.Where("status = ? AND end_date <= ? AND end_date > ?",
-		"active", expiryDate, time.Now()).
+	if err := r.db.Where("status IN ? AND end_date <= ? AND end_date > ?",
+		[]string{models.SubscriptionTrialing, models.SubscriptionActive}, expiryDate, time.Now()).
 		Preload("Plan").
+		Preload("Member").
 		Find(&subscriptions).Error; err != nil {
 		return nil, err
 	}
 	return subscriptions, nil
 }
 
-// AutoRenewSubscriptions 自动续费订阅
-func (r *UserSubscriptionRepository) AutoRenewSubscriptions() error {
-	now := time.Now()
-	
-	// 查找需要自动续费的订阅
-	var autoRenewSubscriptions []models.UserSubscription
-	if err := r.db.Where("status = ? AND auto_renew = ? AND end_date < ?",
-		"active", true, now).
-		Find(&autoRenewSubscriptions).Error; err != nil {
-		return err
-	}
-
-	// 续费每个订阅
-	for _, subscription := range autoRenewSubscriptions {
-		if err := r.renewSubscription(&subscription); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// renewSubscription 续费单个订阅
-func (r *UserSubscriptionRepository) renewSubscription(subscription *models.UserSubscription) error {
-	// 获取订阅计划以计算新的结束时间
-	planRepo := NewSubscriptionPlanRepository(r.db)
-	plan, err := planRepo.FindByID(subscription.PlanID)
-	if err != nil {
-		return err
-	}
-	if plan == nil {
-		return fmt.Errorf("订阅计划不存在")
-	}
-
-	// 计算新的订阅期间
-	newStartDate := subscription.EndDate
-	newEndDate := newStartDate.AddDate(0, 0, plan.Duration)
-
-	// 更新订阅
-	subscription.StartDate = newStartDate
-	subscription.EndDate = newEndDate
-	subscription.Status = "active"
-
-	return r.db.Save(subscription).Error
-}
-
 // Exists 检查订阅是否存在
 func (r *UserSubscriptionRepository) Exists(id string) (bool, error) {
 	var count int64
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"homemoney/internal/handlers"
+	"homemoney/internal/repository"
+)
+
+// SetupAccountRoutes 设置资金账户和转账相关路由
+func SetupAccountRoutes(router *gin.Engine, accountRepo *repository.AccountRepository, authMiddlewares ...gin.HandlerFunc) {
+	accountHandler := handlers.NewAccountHandler(accountRepo)
+
+	api := router.Group("/api", authMiddlewares...)
+	{
+		accounts := api.Group("/accounts")
+		{
+			// 获取账户列表
+			accounts.GET("", accountHandler.GetAccounts)
+
+			// 创建账户
+			accounts.POST("", accountHandler.CreateAccount)
+
+			// 获取转账记录
+			accounts.GET("/transfers", accountHandler.GetTransfers)
+
+			// 账户间转账
+			accounts.POST("/transfers", accountHandler.CreateTransfer)
+
+			// 获取单个账户
+			accounts.GET("/:id", accountHandler.GetAccountByID)
+
+			// 更新账户
+			accounts.PUT("/:id", accountHandler.UpdateAccount)
+
+			// 删除账户
+			accounts.DELETE("/:id", accountHandler.DeleteAccount)
+
+			// 获取账户余额
+			accounts.GET("/:id/balance", accountHandler.GetAccountBalance)
+		}
+	}
+}
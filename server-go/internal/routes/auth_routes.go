@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"homemoney/internal/handler"
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAuthRoutes 配置注册、登录相关的API路由
+func SetupAuthRoutes(router *gin.Engine, authService *service.AuthService, authMiddleware gin.HandlerFunc) {
+	authHandler := handler.NewAuthHandler(authService)
+
+	authGroup := router.Group("/api/auth")
+
+	// 注册账号
+	authGroup.POST("/register", authHandler.Register)
+
+	// 使用初始化令牌创建系统管理员，接管启用登录之前的账本数据
+	authGroup.POST("/setup", authHandler.Setup)
+
+	// 使用认领码为尚未设置密码的会员设置密码
+	authGroup.POST("/claim", authHandler.Claim)
+
+	// 为尚未设置密码的会员生成一次性认领码，需要家庭管理权限
+	authGroup.POST("/claim-codes", authMiddleware, middleware.RequirePermission(models.PermissionManageHousehold), authHandler.CreateClaimCode)
+
+	// 登录
+	authGroup.POST("/login", authHandler.Login)
+
+	// 注销当前令牌
+	authGroup.POST("/logout", authMiddleware, authHandler.Logout)
+
+	// 获取当前登录信息
+	authGroup.GET("/me", authMiddleware, authHandler.Me)
+
+	// 修改家庭本位币，需要家庭管理权限
+	authGroup.PUT("/household", authMiddleware, middleware.RequirePermission(models.PermissionManageHousehold), authHandler.UpdateHousehold)
+}
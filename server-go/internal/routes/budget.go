@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"homemoney/internal/handlers"
+	"homemoney/internal/repository"
+)
+
+// SetupBudgetRoutes 设置预算相关路由
+func SetupBudgetRoutes(router *gin.Engine, budgetRepo *repository.BudgetRepository, authMiddlewares ...gin.HandlerFunc) {
+	budgetHandler := handlers.NewBudgetHandler(budgetRepo)
+
+	api := router.Group("/api", authMiddlewares...)
+	{
+		budgets := api.Group("/budgets")
+		{
+			// 获取预算列表
+			budgets.GET("", budgetHandler.GetBudgets)
+
+			// 获取指定月份的预算执行情况
+			budgets.GET("/status", budgetHandler.GetBudgetStatus)
+
+			// 创建预算
+			budgets.POST("", budgetHandler.CreateBudget)
+
+			// 获取单个预算
+			budgets.GET("/:id", budgetHandler.GetBudgetByID)
+
+			// 更新预算
+			budgets.PUT("/:id", budgetHandler.UpdateBudget)
+
+			// 删除预算
+			budgets.DELETE("/:id", budgetHandler.DeleteBudget)
+		}
+	}
+}
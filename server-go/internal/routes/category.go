@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"homemoney/internal/handlers"
+	"homemoney/internal/repository"
+)
+
+// SetupCategoryRoutes 设置消费分类相关路由
+func SetupCategoryRoutes(router *gin.Engine, categoryRepo *repository.CategoryRepository, authMiddlewares ...gin.HandlerFunc) {
+	categoryHandler := handlers.NewCategoryHandler(categoryRepo)
+
+	api := router.Group("/api", authMiddlewares...)
+	{
+		categories := api.Group("/categories")
+		{
+			// 获取分类树
+			categories.GET("", categoryHandler.GetCategories)
+
+			// 创建分类
+			categories.POST("", categoryHandler.CreateCategory)
+
+			// 为已有消费类型创建分类
+			categories.POST("/sync", categoryHandler.SyncCategories)
+
+			// 更新分类的上级分类、图标和颜色
+			categories.PUT("/:id", categoryHandler.UpdateCategory)
+
+			// 删除分类
+			categories.DELETE("/:id", categoryHandler.DeleteCategory)
+
+			// 重命名分类并改写相关记录
+			categories.POST("/:id/rename", categoryHandler.RenameCategory)
+
+			// 合并到其他分类
+			categories.POST("/:id/merge", categoryHandler.MergeCategory)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"homemoney/internal/handler"
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDonationRoutes 配置捐赠记录相关的API路由
+func SetupDonationRoutes(router *gin.Engine, donationService *service.DonationService, authMiddleware gin.HandlerFunc) {
+	donationHandler := handler.NewDonationHandler(donationService)
+
+	donationGroup := router.Group("/api/donations", authMiddleware)
+
+	// 捐赠排行榜，登录用户均可查看
+	donationGroup.GET("/leaderboard", donationHandler.GetLeaderboard)
+
+	// 捐赠明细和导入包含所有用户的数据，仅系统管理员可访问
+	donationGroup.GET("", middleware.RequirePermission(models.PermissionAdmin), donationHandler.GetDonations)
+	donationGroup.POST("/import", middleware.RequirePermission(models.PermissionAdmin), donationHandler.ImportDonations)
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"homemoney/internal/handlers"
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+)
+
+// SetupExchangeRateRoutes 设置汇率相关路由，汇率由系统管理员导入，家庭成员可以查看
+func SetupExchangeRateRoutes(router *gin.Engine, exchangeRateRepo *repository.ExchangeRateRepository, authMiddleware gin.HandlerFunc) {
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateRepo)
+
+	// 获取汇率
+	router.GET("/api/exchange-rates", authMiddleware, middleware.RequirePermission(models.PermissionReadLedger), exchangeRateHandler.GetExchangeRates)
+
+	adminRates := router.Group("/api/admin/exchange-rates", authMiddleware, middleware.RequirePermission(models.PermissionAdmin))
+	{
+		// 批量保存汇率
+		adminRates.POST("", exchangeRateHandler.SaveExchangeRates)
+
+		// 导入汇率CSV文件
+		adminRates.POST("/import", exchangeRateHandler.ImportExchangeRates)
+
+		// 删除汇率
+		adminRates.DELETE("/:id", exchangeRateHandler.DeleteExchangeRate)
+	}
+}
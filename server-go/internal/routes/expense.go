@@ -7,11 +7,11 @@ import (
 )
 
 // SetupExpenseRoutes 设置消费记录相关路由 - 与Node.js版本完全一致
-func SetupExpenseRoutes(router *gin.Engine, expenseRepo *repository.ExpenseRepository) {
+func SetupExpenseRoutes(router *gin.Engine, expenseRepo *repository.ExpenseRepository, authMiddlewares ...gin.HandlerFunc) {
 	expenseHandler := handlers.NewExpenseHandler(expenseRepo)
 
 	// 创建路由组
-	api := router.Group("/api")
+	api := router.Group("/api", authMiddlewares...)
 	{
 		// 消费记录路由组
 		expenses := api.Group("/expenses")
@@ -22,6 +22,21 @@ func SetupExpenseRoutes(router *gin.Engine, expenseRepo *repository.ExpenseRepos
 			// 创建新的消费记录
 			expenses.POST("/", expenseHandler.CreateExpense)
 
+			// 批量创建消费记录
+			expenses.POST("/batch", expenseHandler.BatchCreateExpense)
+
+			// 获取全部标签及使用次数
+			expenses.GET("/tags", expenseHandler.GetExpenseTags)
+
+			// 获取单条消费记录
+			expenses.GET("/:id", expenseHandler.GetExpenseByID)
+
+			// 整体更新消费记录
+			expenses.PUT("/:id", expenseHandler.UpdateExpense)
+
+			// 部分更新消费记录
+			expenses.PATCH("/:id", expenseHandler.PatchExpense)
+
 			// 删除消费记录
 			expenses.DELETE("/:id", expenseHandler.DeleteExpense)
 		}
@@ -31,6 +46,12 @@ func SetupExpenseRoutes(router *gin.Engine, expenseRepo *repository.ExpenseRepos
 		{
 			// 获取消费统计数据
 			expenseStats.GET("/statistics", expenseHandler.GetExpenseStatistics)
+
+			// 获取按日/周/月分桶的消费时间序列
+			expenseStats.GET("/statistics/timeseries", expenseHandler.GetExpenseTimeSeries)
+
+			// 预测未来各月的消费金额
+			expenseStats.GET("/forecast", expenseHandler.GetExpenseForecast)
 		}
 	}
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"homemoney/internal/handlers"
+	"homemoney/internal/repository"
+)
+
+// SetupExportRoutes 设置数据导入导出相关路由 - 对应JS版本的exportRoutes
+func SetupExportRoutes(router *gin.Engine, expenseRepo *repository.ExpenseRepository, authMiddlewares ...gin.HandlerFunc) {
+	exportHandler := handlers.NewExportHandler(expenseRepo)
+	importHandler := handlers.NewImportHandler(expenseRepo)
+
+	api := router.Group("/api", authMiddlewares...)
+	{
+		// 对应JS版本: GET /api/export/excel - 导出消费记录（?format=csv 导出CSV）
+		api.GET("/export/excel", exportHandler.ExportExcel)
+
+		// 对应JS版本: POST /api/import/excel - 导入Excel/CSV（?dryRun=true 仅预览）
+		api.POST("/import/excel", importHandler.ImportExcel)
+	}
+}
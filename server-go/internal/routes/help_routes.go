@@ -16,6 +16,16 @@ func SetupHelpRoutes(router *gin.Engine) {
 			"timestamp": time.Now().UTC().Format(time.RFC3339),
 			"projectName": "Home Finance Tracker API (Go Version)",
 			"description": "家庭财务管理系统后端API文档 - Go语言实现",
+			"authentication": gin.H{
+				"en": "Ledger (expenses, categories, settlements, incomes, budgets, accounts, recurring expenses, import/export), member, subscription, admin and maintenance endpoints require an Authorization: Bearer <token> header obtained from /api/auth/login; ledger data is only visible to members of the same household",
+				"roles": "viewer: read the ledger; member: read and write the ledger; owner: member plus managing household roles, canceling subscriptions and viewing their history; admin: everything, including creating, renewing or changing the status of subscriptions without payment, /api/admin, /api/maintenance and viewing or cleaning /api/logs. Requests without the required role get 403",
+				"zh": "账本（消费、分类、结算、收入、预算、账户、周期性消费、导入导出）、会员、订阅、管理和维护接口需要通过/api/auth/login获取令牌并在Authorization: Bearer <token>请求头中携带；账本数据仅对同一家庭的成员可见",
+				"rolesZh": "viewer：查看账本；member：查看和修改账本；owner：在member基础上管理本家庭成员角色、取消订阅和查看订阅记录；admin：全部权限，包括不经支付开通、续费或变更订阅状态，/api/admin、/api/maintenance以及查看和清理/api/logs。角色不足时返回403",
+			},
+			"amounts": gin.H{
+				"en": "Amounts are stored as integer cents and exchanged as decimal numbers in yuan, e.g. 12.34; ledger request amounts may also be strings such as \"12.34\" and are rounded to the nearest cent",
+				"zh": "金额以分为单位的整数保存，接口中以元为单位的十进制数表示，如12.34；账本接口请求中的金额也可以是字符串，如\"12.34\"，超过两位的小数四舍五入到分",
+			},
 			"availableAPIs": gin.H{
 				"base": []gin.H{
 					{
@@ -43,6 +53,116 @@ func SetupHelpRoutes(router *gin.Engine) {
 						},
 					},
 				},
+				"auth": []gin.H{
+					{
+						"endpoint": "/api/auth/register",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Register account",
+							"zh": "注册账号",
+						},
+						"usage": gin.H{
+							"en": "Body: username, password (at least 8 characters), and either inviteCode to join an existing household or householdName to create one; returns a login token",
+							"zh": "请求体：username、password（至少8位），以及用于加入已有家庭的inviteCode或用于创建新家庭的householdName；返回登录令牌",
+						},
+					},
+					{
+						"endpoint": "/api/auth/setup",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Create the system administrator",
+							"zh": "初始化系统管理员",
+						},
+						"usage": gin.H{
+							"en": "Body: setupToken (must equal the AUTH_SETUP_TOKEN environment variable), username, password, householdName. Creates the admin and a household that takes over ledger data recorded before login was enabled; only works while no admin exists. Registered accounts never become admin",
+							"zh": "请求体：setupToken（须与AUTH_SETUP_TOKEN环境变量一致）、username、password、householdName。创建系统管理员及其家庭，该家庭接管启用登录之前的账本数据；仅在还没有系统管理员时可用。通过注册创建的账号不会成为系统管理员",
+						},
+					},
+					{
+						"endpoint": "/api/auth/claim-codes",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Create a member claim code",
+							"zh": "生成会员认领码",
+						},
+						"usage": gin.H{
+							"en": "Body: username of a member without a password; returns a one-time claimCode valid for 72 hours. Owners can only create codes for members of their household (see POST /api/members); admins can also create codes for legacy members without a household, who then join the admin's household",
+							"zh": "请求体：尚未设置密码的会员的username；返回72小时内有效的一次性认领码claimCode。家庭所有者只能为本家庭的成员生成（见POST /api/members）；系统管理员还可以为没有家庭的旧会员生成，认领后加入系统管理员的家庭",
+						},
+					},
+					{
+						"endpoint": "/api/auth/claim",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Claim a member",
+							"zh": "认领会员",
+						},
+						"usage": gin.H{
+							"en": "Body: username, claimCode, password (at least 8 characters); sets the password of a member without one and returns a login token. Existing usernames cannot be registered, they can only be claimed",
+							"zh": "请求体：username、claimCode、password（至少8位）；为尚未设置密码的会员设置密码并返回登录令牌。已存在的用户名不能注册，只能认领",
+						},
+					},
+					{
+						"endpoint": "/api/auth/login",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Log in",
+							"zh": "登录",
+						},
+						"usage": gin.H{
+							"en": "Body: username, password; returns a token valid for 30 days by default (AUTH_TOKEN_TTL_HOURS)",
+							"zh": "请求体：username、password；返回默认30天有效的令牌（可通过AUTH_TOKEN_TTL_HOURS配置）",
+						},
+					},
+					{
+						"endpoint": "/api/auth/logout",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Log out",
+							"zh": "注销",
+						},
+						"usage": gin.H{
+							"en": "Revokes the token sent in the Authorization header",
+							"zh": "注销Authorization请求头中的令牌",
+						},
+					},
+					{
+						"endpoint": "/api/auth/me",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get current member",
+							"zh": "获取当前登录信息",
+						},
+						"usage": gin.H{
+							"en": "Returns the logged-in member and household, including the invite code",
+							"zh": "返回当前会员及其家庭信息，包含邀请码",
+						},
+					},
+					{
+						"endpoint": "/api/members/:username/role",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Set member role",
+							"zh": "设置会员角色",
+						},
+						"usage": gin.H{
+							"en": "Body: role = owner|member|viewer|admin; owners manage members of their own household, only admins can grant or revoke admin, and nobody can change their own role",
+							"zh": "请求体：role = owner|member|viewer|admin；家庭所有者可管理本家庭成员，只有系统管理员可以授予或撤销admin，不能修改自己的角色",
+						},
+					},
+					{
+						"endpoint": "/api/auth/household",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Change household base currency",
+							"zh": "修改家庭本位币",
+						},
+						"usage": gin.H{
+							"en": "Body: baseCurrency = 3-letter code such as CNY or USD; requires household management permission. Existing expenses without a currency keep the previous base currency",
+							"zh": "请求体：baseCurrency为三位字母币种代码，如CNY、USD；需要家庭管理权限。没有币种的已有消费记录保留原本位币",
+						},
+					},
+				},
 				"expenses": []gin.H{
 					{
 						"endpoint": "/api/expenses",
@@ -52,8 +172,8 @@ func SetupHelpRoutes(router *gin.Engine) {
 							"zh": "获取消费记录",
 						},
 						"usage": gin.H{
-							"en": "Retrieve all expense records with optional filtering",
-							"zh": "获取所有消费记录，支持筛选",
+							"en": "Retrieve all expense records with optional filtering; tags=a,b filters by tag, tagMode=any (default) matches any tag and tagMode=all requires every tag",
+							"zh": "获取所有消费记录，支持筛选；tags=a,b按标签筛选，tagMode=any（默认）包含任意一个标签即可，tagMode=all须包含全部标签",
 						},
 					},
 					{
@@ -64,8 +184,56 @@ func SetupHelpRoutes(router *gin.Engine) {
 							"zh": "添加新的消费记录",
 						},
 						"usage": gin.H{
-							"en": "Create a new expense entry in the system",
-							"zh": "在系统中创建新的消费记录",
+							"en": "Create a new expense entry in the system; tags is an optional array of tag names (up to 10, 20 characters each), unknown tags are created automatically. For shared expenses set payerId, splitMode=equal|percentage|exact and splits=[{memberId, percentage|amount}]; shares are computed in cents and the payer's own share creates no debt. currency is an optional 3-letter code such as USD and defaults to the household base currency",
+							"zh": "在系统中创建新的消费记录；tags为可选的标签名称数组（最多10个，每个不超过20个字符），不存在的标签自动创建。共同消费需提供payerId、splitMode=equal|percentage|exact和splits=[{memberId, percentage|amount}]；分摊金额按分计算，付款人自己承担的部分不产生欠款。currency为可选的三位字母币种代码，如USD，默认为家庭本位币",
+						},
+					},
+					{
+						"endpoint": "/api/expenses/batch",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Batch create expense records",
+							"zh": "批量创建消费记录",
+						},
+						"usage": gin.H{
+							"en": "Send a JSON array of expense records; all records are validated before any is saved",
+							"zh": "请求体为消费记录JSON数组，全部验证通过后才会保存",
+						},
+					},
+					{
+						"endpoint": "/api/expenses/:id",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get expense record",
+							"zh": "获取单条消费记录",
+						},
+						"usage": gin.H{
+							"en": "Retrieve an expense record by its ID",
+							"zh": "通过ID获取消费记录",
+						},
+					},
+					{
+						"endpoint": "/api/expenses/:id",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Replace expense record",
+							"zh": "整体更新消费记录",
+						},
+						"usage": gin.H{
+							"en": "Overwrite all fields of an expense record; omitted fields are cleared",
+							"zh": "覆盖消费记录的全部字段，未提供的字段会被清空",
+						},
+					},
+					{
+						"endpoint": "/api/expenses/:id",
+						"method": "PATCH",
+						"description": gin.H{
+							"en": "Partially update expense record",
+							"zh": "部分更新消费记录",
+						},
+						"usage": gin.H{
+							"en": "Only the provided fields are changed; send an empty remark to clear it; tags replaces all tags, an empty array removes them; an empty payerId removes the payer and splits",
+							"zh": "只修改请求中提供的字段，remark传空字符串可清空备注；tags会替换全部标签，传空数组可清除标签；payerId传空字符串可清除付款人和分摊",
 						},
 					},
 					{
@@ -88,34 +256,790 @@ func SetupHelpRoutes(router *gin.Engine) {
 							"zh": "获取消费统计信息",
 						},
 						"usage": gin.H{
-							"en": "Retrieve statistical analysis of expense data",
-							"zh": "获取消费数据的统计分析",
+							"en": "Retrieve statistical analysis of expense data; tagDistribution counts each tag of a record, so its percentages may add up to more than 100; categoryDistribution rolls child categories up into their parents, types without a category are listed at the top level. Amounts are converted to the household baseCurrency with the latest rate on or before each expense date; records without a rate are left out and reported in unconvertedCount and missingCurrencies",
+							"zh": "获取消费数据的统计分析；tagDistribution按记录的每个标签分别计数，占比之和可能超过100；categoryDistribution将下级分类汇总到上级分类，没有分类的消费类型列为顶级项。金额按消费日期当天或之前最近的汇率折算为家庭本位币baseCurrency，缺少汇率的记录不计入统计，数量和币种见unconvertedCount和missingCurrencies",
+						},
+					},
+					{
+						"endpoint": "/api/expenses/tags",
+						"method": "GET",
+						"description": gin.H{
+							"en": "List expense tags",
+							"zh": "获取消费标签列表",
+						},
+						"usage": gin.H{
+							"en": "Returns the household's tags with the number of expenses using each, most used first",
+							"zh": "返回当前家庭的全部标签及使用次数，按使用次数从多到少排列",
+						},
+					},
+					{
+						"endpoint": "/api/expenses/statistics/timeseries",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get expense time series",
+							"zh": "获取消费时间序列",
+						},
+						"usage": gin.H{
+							"en": "Buckets expenses by granularity=day|week|month|year (weeks start on Monday); groupBy=type returns one series per type; from/to or startDate/endDate/month limit the range, defaulting to the data range; empty periods are filled with zero",
+							"zh": "按granularity=day|week|month|year分桶（周以周一开始）；groupBy=type时按类型返回多条序列；from/to或startDate/endDate/month限定范围，默认使用数据的日期范围；无数据的周期补零",
+						},
+					},
+					{
+						"endpoint": "/api/expenses/forecast",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Forecast expenses",
+							"zh": "预测消费金额",
+						},
+						"usage": gin.H{
+							"en": "Projects per-type spending for months=1-12 (default 3, starting with the current month) from up to 36 months of history: a window-month moving average (default 3) adjusted by month-of-year seasonality once a year of history exists; returns expected values with a 95% confidence band; type limits the forecast to one type",
+							"zh": "基于最多36个月的历史数据预测各类型在接下来months个月（1-12，默认3，从当月开始）的支出：按window个月（默认3）移动平均，历史满一年后叠加按月份的季节性；返回预测值及95%置信区间；type可限定单个类型",
 						},
 					},
 				},
-				"payments": []gin.H{
+				"categories": []gin.H{
 					{
-						"endpoint": "/api/payments/donate",
+						"endpoint": "/api/categories",
+						"method": "GET",
+						"description": gin.H{
+							"en": "List categories",
+							"zh": "获取分类树",
+						},
+						"usage": gin.H{
+							"en": "Returns the household categories as a tree; expenseCount counts expenses whose type equals the category name, excluding child categories",
+							"zh": "以树形结构返回当前家庭的分类；expenseCount为类型等于该分类名称的消费记录数，不含下级分类",
+						},
+					},
+					{
+						"endpoint": "/api/categories",
 						"method": "POST",
 						"description": gin.H{
-							"en": "Donation payment processing",
-							"zh": "捐赠支付处理",
+							"en": "Create category",
+							"zh": "创建分类",
 						},
 						"usage": gin.H{
-							"en": "Process user donation payment requests",
-							"zh": "处理用户的捐赠支付请求",
+							"en": "Body: name (required, matches the expense type), parentId, icon, color (#RGB or #RRGGBB)",
+							"zh": "请求体：name（必填，对应消费类型）、parentId、icon、color（#RGB或#RRGGBB）",
 						},
 					},
 					{
-						"endpoint": "/api/payments/subscribe",
+						"endpoint": "/api/categories/sync",
 						"method": "POST",
 						"description": gin.H{
-							"en": "Membership subscription payment",
-							"zh": "会员订阅支付",
+							"en": "Create categories from expense types",
+							"zh": "根据消费类型创建分类",
+						},
+						"usage": gin.H{
+							"en": "Creates a top-level category for every expense or recurring template type that has none yet; returns created (the new categories) and skipped (types whose name breaks the category rules, e.g. longer than 20 characters, with the reason)",
+							"zh": "为还没有分类的消费类型和周期模板类型创建顶级分类；返回created（新建的分类）和skipped（名称不符合分类规则的类型，如超过20个字符，附原因）",
+						},
+					},
+					{
+						"endpoint": "/api/categories/:id",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Update category",
+							"zh": "更新分类",
+						},
+						"usage": gin.H{
+							"en": "Changes parentId, icon and color; a category cannot be moved under itself or its descendants. Use the rename endpoint to change the name",
+							"zh": "修改parentId、icon和color，分类不能移到自身或其下级分类之下；修改名称请使用重命名接口",
+						},
+					},
+					{
+						"endpoint": "/api/categories/:id",
+						"method": "DELETE",
+						"description": gin.H{
+							"en": "Delete category",
+							"zh": "删除分类",
+						},
+						"usage": gin.H{
+							"en": "Expenses keep their type; categories that still have child categories cannot be deleted",
+							"zh": "消费记录保留原类型；还有下级分类的分类不能删除",
+						},
+					},
+					{
+						"endpoint": "/api/categories/:id/rename",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Rename category",
+							"zh": "重命名分类",
+						},
+						"usage": gin.H{
+							"en": "Body: {\"name\"}. Rewrites the type of expenses and recurring templates and the category of budgets in one transaction; returns 409 if another category already uses the name",
+							"zh": "请求体：{\"name\"}。在一个事务中改写消费记录和周期模板的类型以及预算的分类；名称已被其他分类使用时返回409",
+						},
+					},
+					{
+						"endpoint": "/api/categories/:id/merge",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Merge category",
+							"zh": "合并分类",
+						},
+						"usage": gin.H{
+							"en": "Body: {\"targetId\"}. Moves expenses, recurring templates, budgets and child categories to the target in one transaction, then deletes this category; budgets for the same month are added together",
+							"zh": "请求体：{\"targetId\"}。在一个事务中将消费记录、周期模板、预算和下级分类移到目标分类后删除本分类；同月份的预算金额相加",
+						},
+					},
+				},
+				"export": []gin.H{
+					{
+						"endpoint": "/api/export/excel",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Export expense records",
+							"zh": "导出消费记录",
+						},
+						"usage": gin.H{
+							"en": "Download expenses as xlsx (default) or CSV with format=csv; supports the same filters as /api/expenses",
+							"zh": "下载消费记录，默认xlsx格式，format=csv时导出CSV；支持与/api/expenses相同的筛选参数",
+						},
+					},
+					{
+						"endpoint": "/api/import/excel",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Import expense records",
+							"zh": "导入消费记录",
+						},
+						"usage": gin.H{
+							"en": "Upload an xlsx or CSV file in the 'file' field; returns a per-row report (accepted/rejected/duplicate), dryRun=true previews without writing",
+							"zh": "通过file字段上传xlsx或CSV文件，返回逐行导入报告（accepted/rejected/duplicate），dryRun=true时仅预览不写入",
+						},
+					},
+				},
+				"budgets": []gin.H{
+					{
+						"endpoint": "/api/budgets",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get budgets",
+							"zh": "获取预算列表",
+						},
+						"usage": gin.H{
+							"en": "Optional month=YYYY-MM returns only budgets effective in that month",
+							"zh": "可选month=YYYY-MM，只返回对该月生效的预算",
+						},
+					},
+					{
+						"endpoint": "/api/budgets",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Create budget",
+							"zh": "创建预算",
+						},
+						"usage": gin.H{
+							"en": "category is an expense type (empty for the overall budget), month=YYYY-MM for a single month or empty for a recurring monthly budget",
+							"zh": "category为消费类型（为空表示总预算），month为YYYY-MM表示仅当月生效，为空表示每月循环",
+						},
+					},
+					{
+						"endpoint": "/api/budgets/:id",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Update budget",
+							"zh": "更新预算",
+						},
+						"usage": gin.H{
+							"en": "Replace category, month and amount of a budget",
+							"zh": "更新预算的分类、月份和金额",
+						},
+					},
+					{
+						"endpoint": "/api/budgets/:id",
+						"method": "DELETE",
+						"description": gin.H{
+							"en": "Delete budget",
+							"zh": "删除预算",
+						},
+						"usage": gin.H{
+							"en": "Remove a budget by its ID",
+							"zh": "通过ID删除预算",
+						},
+					},
+					{
+						"endpoint": "/api/budgets/status",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get budget status",
+							"zh": "获取预算执行情况",
+						},
+						"usage": gin.H{
+							"en": "Returns spent, remaining and percent per category for month=YYYY-MM (defaults to current month); spending is converted to the household base currency, expenses without a rate are left out and counted in unconvertedCount",
+							"zh": "返回month=YYYY-MM（默认当月）各分类的已用、剩余金额及百分比；支出折算为家庭本位币，缺少汇率的消费不计入已用金额，数量见unconvertedCount",
+						},
+					},
+				},
+				"incomes": []gin.H{
+					{
+						"endpoint": "/api/incomes",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get income records",
+							"zh": "获取收入记录",
+						},
+						"usage": gin.H{
+							"en": "Supports the same pagination, filter and sort parameters as /api/expenses",
+							"zh": "支持与/api/expenses相同的分页、筛选和排序参数",
+						},
+					},
+					{
+						"endpoint": "/api/incomes",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Add new income record",
+							"zh": "添加新的收入记录",
+						},
+						"usage": gin.H{
+							"en": "Create an income entry such as salary or a refund",
+							"zh": "创建工资、退款等收入记录",
+						},
+					},
+					{
+						"endpoint": "/api/incomes/:id",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get income record",
+							"zh": "获取单条收入记录",
+						},
+						"usage": gin.H{
+							"en": "Retrieve an income record by its ID",
+							"zh": "通过ID获取收入记录",
+						},
+					},
+					{
+						"endpoint": "/api/incomes/:id",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Update income record",
+							"zh": "更新收入记录",
+						},
+						"usage": gin.H{
+							"en": "Overwrite all fields of an income record",
+							"zh": "覆盖收入记录的全部字段",
+						},
+					},
+					{
+						"endpoint": "/api/incomes/:id",
+						"method": "DELETE",
+						"description": gin.H{
+							"en": "Delete income record",
+							"zh": "删除收入记录",
+						},
+						"usage": gin.H{
+							"en": "Remove an income record by its ID",
+							"zh": "通过ID删除收入记录",
+						},
+					},
+					{
+						"endpoint": "/api/cashflow",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get net cash flow",
+							"zh": "获取净现金流",
+						},
+						"usage": gin.H{
+							"en": "Returns income, expense and net per period for from/to (yyyy-mm-dd) and granularity=day|week|month|year; other filters follow /api/expenses",
+							"zh": "按from/to（yyyy-mm-dd）和granularity=day|week|month|year返回每个周期的收入、支出和净额，其他筛选参数与/api/expenses一致",
+						},
+					},
+				},
+				"accounts": []gin.H{
+					{
+						"endpoint": "/api/accounts",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get accounts",
+							"zh": "获取账户列表",
+						},
+						"usage": gin.H{
+							"en": "List all wallets such as cash, bank cards, Alipay and WeChat",
+							"zh": "获取现金、银行卡、支付宝、微信等所有账户",
+						},
+					},
+					{
+						"endpoint": "/api/accounts",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Create account",
+							"zh": "创建账户",
+						},
+						"usage": gin.H{
+							"en": "name, type (cash|bank_card|credit_card|alipay|wechat|other) and openingBalance",
+							"zh": "提供name、type（cash|bank_card|credit_card|alipay|wechat|other）和openingBalance",
+						},
+					},
+					{
+						"endpoint": "/api/accounts/:id",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Update account",
+							"zh": "更新账户",
+						},
+						"usage": gin.H{
+							"en": "Replace name, type and opening balance",
+							"zh": "更新账户名称、类型和期初余额",
+						},
+					},
+					{
+						"endpoint": "/api/accounts/:id",
+						"method": "DELETE",
+						"description": gin.H{
+							"en": "Delete account",
+							"zh": "删除账户",
+						},
+						"usage": gin.H{
+							"en": "Accounts referenced by records cannot be deleted",
+							"zh": "已被记录引用的账户不能删除",
+						},
+					},
+					{
+						"endpoint": "/api/accounts/:id/balance",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get account balance",
+							"zh": "获取账户余额",
+						},
+						"usage": gin.H{
+							"en": "Returns the balance and daily running balance up to asOf=yyyy-mm-dd (defaults to today); expenses are converted to the household base currency, those without a rate are left out and counted in unconvertedCount",
+							"zh": "返回截至asOf=yyyy-mm-dd（默认今天）的余额及按日变动的余额；消费金额折算为家庭本位币，缺少汇率的消费不计入余额，数量见unconvertedCount",
+						},
+					},
+					{
+						"endpoint": "/api/accounts/transfers",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get transfers",
+							"zh": "获取转账记录",
+						},
+						"usage": gin.H{
+							"en": "Optional accountId filters transfers involving that account",
+							"zh": "可选accountId筛选与该账户相关的转账",
+						},
+					},
+					{
+						"endpoint": "/api/accounts/transfers",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Transfer between accounts",
+							"zh": "账户间转账",
+						},
+						"usage": gin.H{
+							"en": "fromAccountId, toAccountId, amount, date (defaults to today) and optional remark",
+							"zh": "提供fromAccountId、toAccountId、amount、date（默认今天）及可选remark",
+						},
+					},
+				},
+				"settlements": []gin.H{
+					{
+						"endpoint": "/api/balances",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get shared expense balances",
+							"zh": "获取共同消费余额",
+						},
+						"usage": gin.H{
+							"en": "For every household member returns paid (advanced for others), owed, settled amounts and net (positive: others owe this member); transfers lists who should pay whom to settle everything, using at most one fewer transfer than members with a balance. Shares are converted to the household base currency; shares without a rate are left out and counted in unconvertedCount",
+							"zh": "返回每个家庭成员的paid（为他人垫付）、owed（应分摊）、已结算金额和net（为正表示其他成员欠该成员）；transfers给出结清全部欠款的转账建议，笔数不超过有余额的成员数减一。分摊金额折算为家庭本位币，缺少汇率的分摊不计入余额，数量见unconvertedCount",
+						},
+					},
+					{
+						"endpoint": "/api/settlements",
+						"method": "GET",
+						"description": gin.H{
+							"en": "List settlements",
+							"zh": "获取结算记录",
+						},
+						"usage": gin.H{
+							"en": "Newest first",
+							"zh": "按日期从新到旧排列",
+						},
+					},
+					{
+						"endpoint": "/api/settlements",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Record settlement",
+							"zh": "记录结算",
+						},
+						"usage": gin.H{
+							"en": "Body: fromMemberId, toMemberId, amount, date (defaults to today), remark; both members must belong to the household",
+							"zh": "请求体：fromMemberId、toMemberId、amount、date（默认今天）、remark；双方必须是本家庭成员",
+						},
+					},
+					{
+						"endpoint": "/api/settlements/settle-all",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Settle all balances",
+							"zh": "结清全部欠款",
+						},
+						"usage": gin.H{
+							"en": "Records the suggested transfers as settlements in one transaction so every balance becomes zero; optional body: date, remark",
+							"zh": "在一个事务中将建议转账记录为结算，使全部余额归零；可选请求体：date、remark",
+						},
+					},
+					{
+						"endpoint": "/api/settlements/:id",
+						"method": "DELETE",
+						"description": gin.H{
+							"en": "Delete settlement",
+							"zh": "删除结算记录",
+						},
+						"usage": gin.H{
+							"en": "The settled amount is owed again",
+							"zh": "对应金额恢复为欠款",
+						},
+					},
+				},
+				"exchange-rates": []gin.H{
+					{
+						"endpoint": "/api/exchange-rates",
+						"method": "GET",
+						"description": gin.H{
+							"en": "List exchange rates",
+							"zh": "获取汇率",
+						},
+						"usage": gin.H{
+							"en": "Optional currency filters rates whose source or target is that currency; a rate means 1 fromCurrency = rate toCurrency on date",
+							"zh": "可选参数currency筛选原币种或目标币种为该币种的汇率；汇率表示date当天1单位fromCurrency兑换rate单位toCurrency",
+						},
+					},
+					{
+						"endpoint": "/api/admin/exchange-rates",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Save exchange rates (admin)",
+							"zh": "保存汇率（管理员）",
+						},
+						"usage": gin.H{
+							"en": "Body: JSON array of {date, fromCurrency, toCurrency, rate}; an existing rate for the same date and currency pair is overwritten. Inverse rates are used when no direct rate exists",
+							"zh": "请求体：{date, fromCurrency, toCurrency, rate}数组，同一日期和币种对已有汇率时覆盖。没有直接汇率时使用反向汇率",
+						},
+					},
+					{
+						"endpoint": "/api/admin/exchange-rates/import",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Import exchange rates from CSV (admin)",
+							"zh": "导入汇率CSV文件（管理员）",
+						},
+						"usage": gin.H{
+							"en": "Upload field file with header date,from,to,rate; invalid rows are skipped and listed in errors",
+							"zh": "上传字段为file，表头为date,from,to,rate；无效的行被跳过并在errors中列出",
+						},
+					},
+					{
+						"endpoint": "/api/admin/exchange-rates/:id",
+						"method": "DELETE",
+						"description": gin.H{
+							"en": "Delete an exchange rate (admin)",
+							"zh": "删除汇率（管理员）",
+						},
+						"usage": gin.H{
+							"en": "Returns 204 on success",
+							"zh": "成功时返回204",
+						},
+					},
+				},
+				"recurring-expenses": []gin.H{
+					{
+						"endpoint": "/api/recurring-expenses",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get recurring expense templates",
+							"zh": "获取周期性消费模板",
+						},
+						"usage": gin.H{
+							"en": "List templates for rent, utilities, subscriptions and other regular expenses",
+							"zh": "获取房租、水电、订阅等周期性消费模板",
+						},
+					},
+					{
+						"endpoint": "/api/recurring-expenses",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Create recurring expense template",
+							"zh": "创建周期性消费模板",
+						},
+						"usage": gin.H{
+							"en": "frequency=weekly (dayOfWeek 0-6), monthly (dayOfMonth 1-31) or yearly (monthOfYear + dayOfMonth), with interval, startDate and optional endDate; due entries are generated automatically",
+							"zh": "frequency为weekly（dayOfWeek 0-6）、monthly（dayOfMonth 1-31）或yearly（monthOfYear与dayOfMonth），可设置interval、startDate及可选endDate；到期后自动生成消费记录",
+						},
+					},
+					{
+						"endpoint": "/api/recurring-expenses/:id",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Update recurring expense template",
+							"zh": "更新周期性消费模板",
+						},
+						"usage": gin.H{
+							"en": "Already generated expenses are not changed",
+							"zh": "已生成的消费记录不受影响",
+						},
+					},
+					{
+						"endpoint": "/api/recurring-expenses/:id",
+						"method": "DELETE",
+						"description": gin.H{
+							"en": "Delete recurring expense template",
+							"zh": "删除周期性消费模板",
+						},
+						"usage": gin.H{
+							"en": "Already generated expenses are kept",
+							"zh": "已生成的消费记录会保留",
+						},
+					},
+					{
+						"endpoint": "/api/recurring-expenses/:id/preview",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Preview next occurrences",
+							"zh": "预览接下来的发生日期",
+						},
+						"usage": gin.H{
+							"en": "Returns the next count (default 5, max 100) occurrence dates",
+							"zh": "返回接下来count次（默认5次，最多100次）的发生日期",
+						},
+					},
+				},
+				"payments": []gin.H{
+					{
+						"endpoint": "/api/payments/donate",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Donation payment processing",
+							"zh": "捐赠支付处理",
+						},
+						"usage": gin.H{
+							"en": "Process user donation payment requests; send an Idempotency-Key header to make retries safe",
+							"zh": "处理用户的捐赠支付请求；携带Idempotency-Key请求头可安全重试",
+						},
+					},
+					{
+						"endpoint": "/api/payments/subscribe",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Membership subscription payment",
+							"zh": "会员订阅支付",
+						},
+						"usage": gin.H{
+							"en": "Process user membership subscription payment requests; send an Idempotency-Key header to make retries safe",
+							"zh": "处理用户的会员订阅支付请求；携带Idempotency-Key请求头可安全重试",
+						},
+					},
+					{
+						"endpoint": "/api/payments/callback",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Payment gateway callback",
+							"zh": "支付网关回调",
+						},
+						"usage": gin.H{
+							"en": "Called by the payment gateway with X-Timestamp and X-Signature (HMAC-SHA256 of timestamp.body) headers; body {orderId,status:paid|failed,transactionId,amount}. A paid subscription order creates or extends the member subscription",
+							"zh": "由支付网关调用，需携带X-Timestamp和X-Signature（对timestamp.body做HMAC-SHA256）请求头；请求体{orderId,status:paid|failed,transactionId,amount}。订阅订单支付成功后创建或延长会员订阅",
+						},
+					},
+					{
+						"endpoint": "/api/admin/payments/:orderId/refund",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Refund a paid order (admin)",
+							"zh": "对已支付订单退款（管理员）",
+						},
+						"usage": gin.H{
+							"en": "Body {mode: full|prorated, reason}; mode defaults to full. Refunds through the payment gateway, records a refund ledger entry and removes the refunded days from the subscription, canceling it when no time remains. prorated refunds the remaining subscription days (at most the plan duration) and only applies to subscription orders. A callback with status refunded is treated as a chargeback and refunded in full",
+							"zh": "请求体{mode: full|prorated, reason}，mode默认为full。通过支付网关退款，记录退款流水并从订阅中扣除退款对应的天数，扣除后无剩余时间时取消订阅。prorated按订阅剩余天数（不超过计划天数）折算，仅适用于订阅订单。status为refunded的回调视为拒付并按全额退款处理",
+						},
+					},
+				},
+				"donations": []gin.H{
+					{
+						"endpoint": "/api/donations",
+						"method": "GET",
+						"description": gin.H{
+							"en": "List donation records (admin)",
+							"zh": "查询捐赠记录（管理员）",
+						},
+						"usage": gin.H{
+							"en": "Filters: username, status (pending|success|failed), startDate, endDate, minAmount, maxAmount, excludeUsers=a,b, page, limit; the summary field holds the count, total and per-user totals of all matching records",
+							"zh": "筛选参数：username、status（pending|success|failed）、startDate、endDate、minAmount、maxAmount、excludeUsers=a,b、page、limit；summary字段为所有匹配记录的笔数、总额和按用户合计",
+						},
+					},
+					{
+						"endpoint": "/api/donations/leaderboard",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Donation leaderboard",
+							"zh": "捐赠排行榜",
+						},
+						"usage": gin.H{
+							"en": "Ranks users by total successful donations; supports limit (default 10, max 100), startDate, endDate and excludeUsers",
+							"zh": "按成功捐赠总额排名；支持limit（默认10，最多100）、startDate、endDate和excludeUsers",
+						},
+					},
+					{
+						"endpoint": "/api/donations/import",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Import donation records (admin)",
+							"zh": "导入捐赠记录（管理员）",
+						},
+						"usage": gin.H{
+							"en": "Upload the legacy donation_records.json (one JSON object per line) as multipart field file; existing records are skipped. data/donation_records.json is imported automatically at startup",
+							"zh": "以multipart字段file上传旧版donation_records.json（每行一条JSON），已存在的记录会被跳过。启动时会自动导入data/donation_records.json",
+						},
+					},
+				},
+				"subscriptions": []gin.H{
+					{
+						"endpoint": "/api/subscriptions/:id/events",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Subscription history",
+							"zh": "订阅状态变更记录",
+						},
+						"usage": gin.H{
+							"en": "Lists every status transition of the subscription with the previous and new status, actor and reason",
+							"zh": "列出订阅的每次状态变更，包括变更前后的状态、操作人和原因",
+						},
+					},
+					{
+						"endpoint": "/api/subscriptions/:id/status",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Change subscription status (admin)",
+							"zh": "变更订阅状态（管理员）",
+						},
+						"usage": gin.H{
+							"en": "Body {status, reason}. Statuses: pending, trialing, active, past_due, canceled, expired. Allowed: pending -> trialing/active/canceled/expired; trialing -> active/past_due/canceled/expired; active -> active/past_due/canceled/expired; past_due -> active/canceled/expired. canceled and expired are final; other changes return 409",
+							"zh": "请求体{status, reason}。状态：pending、trialing、active、past_due、canceled、expired。允许的变更：pending -> trialing/active/canceled/expired；trialing -> active/past_due/canceled/expired；active -> active/past_due/canceled/expired；past_due -> active/canceled/expired。canceled和expired为终态，其他变更返回409",
+						},
+					},
+				},
+				"notifications": []gin.H{
+					{
+						"endpoint": "/api/notifications",
+						"method": "GET",
+						"description": gin.H{
+							"en": "List the current member inbox, newest first",
+							"zh": "获取当前会员的站内信，按时间倒序",
+						},
+						"usage": gin.H{
+							"en": "Query: unread=true for unread only, page, limit. The response includes unread, the total number of unread messages",
+							"zh": "查询参数：unread=true只看未读，page、limit。返回中的unread为未读总数",
+						},
+					},
+					{
+						"endpoint": "/api/notifications/:id/read",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Mark an inbox message as read",
+							"zh": "将站内信标记为已读",
+						},
+						"usage": gin.H{
+							"en": "Returns 404 if the message does not belong to the current member",
+							"zh": "站内信不属于当前会员时返回404",
+						},
+					},
+					{
+						"endpoint": "/api/notifications/read-all",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Mark all inbox messages as read",
+							"zh": "将全部站内信标记为已读",
+						},
+						"usage": gin.H{
+							"en": "Returns the number of messages marked",
+							"zh": "返回标记的数量",
+						},
+					},
+					{
+						"endpoint": "/api/notifications/settings",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Get the current member notification email, locale and the enabled channels",
+							"zh": "获取当前会员的通知邮箱、语言和已启用的通知渠道",
+						},
+						"usage": gin.H{
+							"en": "The inbox channel is always enabled; email needs NOTIFY_SMTP_HOST/PORT/USERNAME/PASSWORD/FROM, webhook needs NOTIFY_WEBHOOK_URL (optionally signed with NOTIFY_WEBHOOK_SECRET like payment callbacks)",
+							"zh": "站内信始终启用；邮件需配置NOTIFY_SMTP_HOST/PORT/USERNAME/PASSWORD/FROM，webhook需配置NOTIFY_WEBHOOK_URL（配置NOTIFY_WEBHOOK_SECRET时按支付回调相同的方式签名）",
+						},
+					},
+					{
+						"endpoint": "/api/notifications/settings",
+						"method": "PUT",
+						"description": gin.H{
+							"en": "Update the current member notification email and locale",
+							"zh": "更新当前会员的通知邮箱和语言",
+						},
+						"usage": gin.H{
+							"en": "Body: {\"email\": \"me@example.com\", \"locale\": \"zh|en\"}; omitted fields are unchanged, an empty email stops email notifications. Members without a locale get NOTIFY_DEFAULT_LOCALE (default zh)",
+							"zh": "请求体：{\"email\": \"me@example.com\", \"locale\": \"zh|en\"}；未提供的字段保持不变，邮箱为空时不再发送邮件。未设置语言的会员使用NOTIFY_DEFAULT_LOCALE（默认zh）",
+						},
+					},
+					{
+						"endpoint": "/api/admin/notifications",
+						"method": "GET",
+						"description": gin.H{
+							"en": "List notification delivery records (admin only)",
+							"zh": "查询通知发送记录（仅系统管理员）",
+						},
+						"usage": gin.H{
+							"en": "Query: username, kind (subscription_expiring, renewal_failed, budget_overrun), channel (email, webhook, inbox), status (sent, failed), page, limit. The send-notifications job sends each reminder once per channel and retries failed ones; subscriptions expiring within NOTIFY_EXPIRING_DAYS (default 7) days are reminded",
+							"zh": "查询参数：username、kind（subscription_expiring、renewal_failed、budget_overrun）、channel（email、webhook、inbox）、status（sent、failed）、page、limit。send-notifications任务对每条提醒在每个渠道只成功发送一次，失败的会重试；NOTIFY_EXPIRING_DAYS（默认7）天内到期的订阅会收到提醒",
+						},
+					},
+				},
+				"maintenance": []gin.H{
+					{
+						"endpoint": "/api/maintenance/jobs",
+						"method": "GET",
+						"description": gin.H{
+							"en": "List background jobs with schedule, running flag, last start time, duration, error, last success and next run time (admin only)",
+							"zh": "查看后台任务的调度规则、是否正在运行、最近一次开始时间、耗时、错误、最近成功时间和下一次运行时间（仅系统管理员）",
+						},
+						"usage": gin.H{
+							"en": "Jobs: recurring-expenses, expire-subscriptions, renew-subscriptions, send-notifications, clean-expired-sessions. Override a schedule with JOB_SCHEDULE_<NAME> (e.g. JOB_SCHEDULE_RENEW_SUBSCRIPTIONS=\"0 3 * * *\"), using 5-field cron, @hourly/@daily/@weekly/@monthly or @every <duration>; off disables the schedule",
+							"zh": "任务：recurring-expenses、expire-subscriptions、renew-subscriptions、send-notifications、clean-expired-sessions。可通过JOB_SCHEDULE_<任务名>覆盖调度规则（如JOB_SCHEDULE_RENEW_SUBSCRIPTIONS=\"0 3 * * *\"），支持5段cron、@hourly/@daily/@weekly/@monthly和@every <时长>；设为off时停止定时执行",
+						},
+					},
+					{
+						"endpoint": "/api/maintenance/jobs/:name/run",
+						"method": "POST",
+						"description": gin.H{
+							"en": "Run a background job immediately and return its status (admin only)",
+							"zh": "立即执行后台任务并返回任务状态（仅系统管理员）",
+						},
+						"usage": gin.H{
+							"en": "Returns 409 if the job is already running, 404 for an unknown job and 500 with the error if the run fails",
+							"zh": "任务正在运行时返回409，任务不存在返回404，执行失败返回500和错误信息",
+						},
+					},
+					{
+						"endpoint": "/api/maintenance/check-subscriptions",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Run the expire-subscriptions job immediately (admin only)",
+							"zh": "立即执行订阅过期检查任务（仅系统管理员）",
+						},
+						"usage": gin.H{
+							"en": "Same as POST /api/maintenance/jobs/expire-subscriptions/run",
+							"zh": "等同于 POST /api/maintenance/jobs/expire-subscriptions/run",
+						},
+					},
+					{
+						"endpoint": "/api/maintenance/process-renewals",
+						"method": "GET",
+						"description": gin.H{
+							"en": "Run the renew-subscriptions job immediately (admin only)",
+							"zh": "立即执行自动续费任务（仅系统管理员）",
 						},
 						"usage": gin.H{
-							"en": "Process user membership subscription payment requests",
-							"zh": "处理用户的会员订阅支付请求",
+							"en": "Same as POST /api/maintenance/jobs/renew-subscriptions/run. Charges each expired auto-renew trial or subscription through the payment gateway; the subscription is extended from its old end date only after the paid callback. A failed charge moves it to past_due, retrying on days SUBSCRIPTION_RENEWAL_RETRY_DAYS (default 1,3,5) after the end date; it keeps access until SUBSCRIPTION_GRACE_DAYS (default 7) have passed and is then expired by expire-subscriptions. A renewal order without a callback after SUBSCRIPTION_RENEWAL_PENDING_HOURS (default 12) is marked failed and counts as a failed charge",
+							"zh": "等同于 POST /api/maintenance/jobs/renew-subscriptions/run。为已到期的自动续费试用或订阅通过支付网关扣款，支付成功回调后才从原结束时间起延长；扣款失败时订阅转为past_due，在到期后第SUBSCRIPTION_RENEWAL_RETRY_DAYS天（默认1,3,5）重试，宽限期SUBSCRIPTION_GRACE_DAYS天（默认7天）内仍可使用会员权益，期满由expire-subscriptions任务设为过期。续费订单超过SUBSCRIPTION_RENEWAL_PENDING_HOURS小时（默认12小时）未收到回调时标记为失败，记为一次扣款失败",
 						},
 					},
 				},
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"homemoney/internal/handlers"
+	"homemoney/internal/repository"
+)
+
+// SetupIncomeRoutes 设置收入记录和现金流相关路由
+func SetupIncomeRoutes(router *gin.Engine, expenseRepo *repository.ExpenseRepository, incomeRepo *repository.IncomeRepository, authMiddlewares ...gin.HandlerFunc) {
+	incomeHandler := handlers.NewIncomeHandler(incomeRepo)
+	cashflowHandler := handlers.NewCashflowHandler(expenseRepo, incomeRepo)
+
+	api := router.Group("/api", authMiddlewares...)
+	{
+		// 收入记录路由组
+		incomes := api.Group("/incomes")
+		{
+			// 获取收入记录列表（支持分页、筛选、排序）
+			incomes.GET("", incomeHandler.GetIncomes)
+
+			// 创建新的收入记录
+			incomes.POST("", incomeHandler.CreateIncome)
+
+			// 获取单条收入记录
+			incomes.GET("/:id", incomeHandler.GetIncomeByID)
+
+			// 更新收入记录
+			incomes.PUT("/:id", incomeHandler.UpdateIncome)
+
+			// 删除收入记录
+			incomes.DELETE("/:id", incomeHandler.DeleteIncome)
+		}
+
+		// 获取按周期汇总的净现金流
+		api.GET("/cashflow", cashflowHandler.GetCashflow)
+	}
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"homemoney/internal/handler"
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupJobRoutes 配置后台任务相关的API路由，仅系统管理员可访问
+func SetupJobRoutes(router *gin.Engine, scheduler *service.Scheduler, authMiddleware gin.HandlerFunc) {
+	jobHandler := handler.NewJobHandler(scheduler)
+
+	maintenanceGroup := router.Group("/api/maintenance", authMiddleware, middleware.RequirePermission(models.PermissionAdmin))
+
+	// 后台任务运行状态和手工执行
+	maintenanceGroup.GET("/jobs", jobHandler.GetJobs)
+	maintenanceGroup.POST("/jobs/:name/run", jobHandler.RunJob)
+
+	// 兼容旧接口：立即执行订阅过期检查和自动续费任务
+	maintenanceGroup.GET("/check-subscriptions", jobHandler.CheckSubscriptionStatus)
+	maintenanceGroup.GET("/process-renewals", jobHandler.ProcessAutoRenewals)
+}
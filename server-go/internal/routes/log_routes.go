@@ -2,6 +2,8 @@ package routes
 
 import (
 	"homemoney/internal/handler"
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
 	"homemoney/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -11,7 +13,8 @@ import (
 // @Summary 设置日志路由
 // @Description 配置日志相关的API端点
 // @Tags 路由配置
-func SetupLogRoutes(router *gin.RouterGroup, logService *service.LogService) {
+// 接收日志无需登录，查看和清理日志仅系统管理员可访问
+func SetupLogRoutes(router *gin.RouterGroup, logService *service.LogService, authMiddleware gin.HandlerFunc) {
 	// 创建日志处理器
 	logHandler := handler.NewLogHandler(logService)
 	
@@ -21,13 +24,15 @@ func SetupLogRoutes(router *gin.RouterGroup, logService *service.LogService) {
 		// 接收操作日志
 		logs.POST("", logHandler.ReceiveLog)
 		
+		adminOnly := middleware.RequirePermission(models.PermissionAdmin)
+
 		// 获取日志列表
-		logs.GET("", logHandler.GetLogsList)
+		logs.GET("", authMiddleware, adminOnly, logHandler.GetLogsList)
 		
 		// 获取日志统计信息
-		logs.GET("/stats", logHandler.GetLogStats)
+		logs.GET("/stats", authMiddleware, adminOnly, logHandler.GetLogStats)
 		
 		// 清理过期日志
-		logs.DELETE("/clean", logHandler.CleanLogs)
+		logs.DELETE("/clean", authMiddleware, adminOnly, logHandler.CleanLogs)
 	}
 }
@@ -2,6 +2,8 @@ package routes
 
 import (
 	"homemoney/internal/handler"
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
 	"homemoney/internal/repository"
 	"homemoney/internal/service"
 
@@ -11,7 +13,7 @@ import (
 // SetupMemberRoutes 配置会员相关的API路由
 // 对应JS版本的memberRoutes功能
 // 注意：这个函数应该在main.go中调用，并且需要传入数据库连接
-func SetupMemberRoutes(router *gin.Engine, memberRepo *repository.MemberRepository, planRepo *repository.SubscriptionPlanRepository, subscriptionRepo *repository.UserSubscriptionRepository) {
+func SetupMemberRoutes(router *gin.Engine, memberRepo *repository.MemberRepository, planRepo *repository.SubscriptionPlanRepository, subscriptionRepo *repository.UserSubscriptionRepository, authMiddleware gin.HandlerFunc) {
 	// 初始化Service层
 	memberService := service.NewMemberService(memberRepo, subscriptionRepo)
 	planService := service.NewSubscriptionPlanService(planRepo)
@@ -22,16 +24,21 @@ func SetupMemberRoutes(router *gin.Engine, memberRepo *repository.MemberReposito
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService, planService)
 
 	// 创建会员相关的路由组 - 与JS版本完全一致
-	memberGroup := router.Group("/api/members")
+	memberGroup := router.Group("/api/members", authMiddleware)
 
 	// 对应JS版本: POST /api/members - 创建或获取会员
-	memberGroup.POST("", memberHandler.GetOrCreateMember)
+	// 新会员属于当前家庭且没有密码，需要家庭所有者生成认领码后才能登录
+	memberGroup.POST("", middleware.RequirePermission(models.PermissionManageHousehold), memberHandler.GetOrCreateMember)
 
 	// 对应JS版本: GET /api/members/:username - 获取会员信息
+	// 会员信息和订阅只对本家庭成员和系统管理员可见，其他家庭的会员返回404
 	memberGroup.GET(":username", memberHandler.GetMemberInfo)
 
 	// 对应JS版本: PUT /api/members/:id/status - 更新会员状态
-	memberGroup.PUT(":username/status", memberHandler.UpdateMemberStatus)
+	memberGroup.PUT(":username/status", middleware.RequirePermission(models.PermissionAdmin), memberHandler.UpdateMemberStatus)
+
+	// 设置会员角色，家庭所有者只能管理本家庭成员，只有系统管理员可以授予或撤销admin角色
+	memberGroup.PUT(":username/role", middleware.RequirePermission(models.PermissionManageHousehold), memberHandler.UpdateMemberRole)
 
 	// 对应JS版本: GET /api/members/:username/subscriptions - 获取会员订阅
 	memberGroup.GET(":username/subscriptions", memberHandler.GetMemberSubscriptions)
@@ -43,20 +50,27 @@ func SetupMemberRoutes(router *gin.Engine, memberRepo *repository.MemberReposito
 	// 订阅计划路由挂载在 /api/members 下，与JS版本一致
 	memberGroup.GET("/subscription-plans", subscriptionHandler.GetSubscriptionPlans)
 
-	// 创建订阅相关的路由组
-	subscriptionGroup := router.Group("/api/subscriptions")
+	// 创建订阅相关的路由组，只有家庭所有者和系统管理员可以管理订阅
+	subscriptionGroup := router.Group("/api/subscriptions", authMiddleware, middleware.RequirePermission(models.PermissionManageHousehold))
 
 	// 对应JS版本: POST /api/subscriptions - 创建订阅
-	subscriptionGroup.POST("", subscriptionHandler.CreateSubscription)
+	// 正常订阅由支付回调创建，这里不经支付直接开通，仅限系统管理员手工处理
+	subscriptionGroup.POST("", middleware.RequirePermission(models.PermissionAdmin), subscriptionHandler.CreateSubscription)
 
 	// 对应JS版本: DELETE /api/subscriptions/:id - 取消订阅
 	subscriptionGroup.DELETE(":id", subscriptionHandler.CancelSubscription)
 
 	// 对应JS版本: POST /api/subscriptions/:id/renew - 续费订阅
-	subscriptionGroup.POST(":id/renew", subscriptionHandler.RenewSubscription)
+	subscriptionGroup.POST(":id/renew", middleware.RequirePermission(models.PermissionAdmin), subscriptionHandler.RenewSubscription)
+
+	// 订阅状态变更记录
+	subscriptionGroup.GET(":id/events", subscriptionHandler.GetSubscriptionEvents)
+
+	// 按状态机手工变更订阅状态，仅限系统管理员
+	subscriptionGroup.PUT(":id/status", middleware.RequirePermission(models.PermissionAdmin), subscriptionHandler.UpdateSubscriptionStatus)
 
-	// 管理员功能路由
-	adminGroup := router.Group("/api/admin")
+	// 管理员功能路由，仅系统管理员可访问
+	adminGroup := router.Group("/api/admin", authMiddleware, middleware.RequirePermission(models.PermissionAdmin))
 	
 	// 管理员获取所有订阅计划
 	adminGroup.GET("/subscription-plans", subscriptionHandler.AdminGetAllPlans)
@@ -64,14 +78,8 @@ func SetupMemberRoutes(router *gin.Engine, memberRepo *repository.MemberReposito
 	// 管理员创建订阅计划
 	adminGroup.POST("/subscription-plans", subscriptionHandler.AdminCreatePlan)
 
-	// 系统维护路由（用于自动处理过期订阅等）
-	maintenanceGroup := router.Group("/api/maintenance")
-	
-	// 检查订阅状态
-	maintenanceGroup.GET("/check-subscriptions", subscriptionHandler.CheckSubscriptionStatus)
-	
-	// 处理自动续费
-	maintenanceGroup.GET("/process-renewals", subscriptionHandler.ProcessAutoRenewals)
+	// 系统维护路由，仅系统管理员可访问；过期检查和自动续费由后台任务执行，见SetupJobRoutes
+	maintenanceGroup := router.Group("/api/maintenance", authMiddleware, middleware.RequirePermission(models.PermissionAdmin))
 	
 	// 获取即将过期的订阅
 	maintenanceGroup.GET("/expiring-subscriptions", subscriptionHandler.GetExpiringSubscriptions)
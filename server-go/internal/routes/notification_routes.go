@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"homemoney/internal/handler"
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
+	"homemoney/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupNotificationRoutes 配置通知相关的API路由
+func SetupNotificationRoutes(router *gin.Engine, notificationService *service.NotificationService, authMiddleware gin.HandlerFunc) {
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+
+	// 站内信和通知设置，登录会员只能访问自己的数据
+	notificationGroup := router.Group("/api/notifications", authMiddleware)
+	notificationGroup.GET("", notificationHandler.GetInbox)
+	notificationGroup.PUT("/read-all", notificationHandler.MarkAllRead)
+	notificationGroup.PUT("/:id/read", notificationHandler.MarkRead)
+	notificationGroup.GET("/settings", notificationHandler.GetSettings)
+	notificationGroup.PUT("/settings", notificationHandler.UpdateSettings)
+
+	// 通知发送记录包含所有会员的数据，仅系统管理员可访问
+	router.GET("/api/admin/notifications", authMiddleware, middleware.RequirePermission(models.PermissionAdmin), notificationHandler.GetRecords)
+}
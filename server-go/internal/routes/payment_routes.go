@@ -3,12 +3,14 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"homemoney/internal/handler"
+	"homemoney/internal/middleware"
+	"homemoney/internal/models"
 	"homemoney/internal/service"
 )
 
 // SetupPaymentRoutes 设置支付相关的API路由
 // 对应JS版本的支付功能路由
-func SetupPaymentRoutes(router *gin.Engine, paymentService *service.PaymentService) {
+func SetupPaymentRoutes(router *gin.Engine, paymentService *service.PaymentService, authMiddleware gin.HandlerFunc) {
 	// 创建支付处理器
 	paymentHandler := handler.NewPaymentHandler(paymentService)
 	
@@ -22,5 +24,12 @@ func SetupPaymentRoutes(router *gin.Engine, paymentService *service.PaymentServi
 		// 会员订阅支付接口
 		// 对应JS版本: POST /api/payments/subscribe
 		payments.POST("/subscribe", paymentHandler.SubscribePayment)
+
+		// 支付网关回调，通过签名校验身份
+		payments.POST("/callback", paymentHandler.PaymentCallback)
 	}
+
+	// 退款管理，仅系统管理员可访问
+	adminPayments := router.Group("/api/admin/payments", authMiddleware, middleware.RequirePermission(models.PermissionAdmin))
+	adminPayments.POST("/:orderId/refund", paymentHandler.RefundOrder)
 }
\ No newline at end of file
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"homemoney/internal/handlers"
+	"homemoney/internal/repository"
+)
+
+// SetupRecurringExpenseRoutes 设置周期性消费模板相关路由
+func SetupRecurringExpenseRoutes(router *gin.Engine, recurringRepo *repository.RecurringExpenseRepository, authMiddlewares ...gin.HandlerFunc) {
+	recurringHandler := handlers.NewRecurringExpenseHandler(recurringRepo)
+
+	api := router.Group("/api", authMiddlewares...)
+	{
+		recurring := api.Group("/recurring-expenses")
+		{
+			// 获取周期模板列表
+			recurring.GET("", recurringHandler.GetRecurringExpenses)
+
+			// 创建周期模板
+			recurring.POST("", recurringHandler.CreateRecurringExpense)
+
+			// 获取单个周期模板
+			recurring.GET("/:id", recurringHandler.GetRecurringExpenseByID)
+
+			// 更新周期模板
+			recurring.PUT("/:id", recurringHandler.UpdateRecurringExpense)
+
+			// 删除周期模板
+			recurring.DELETE("/:id", recurringHandler.DeleteRecurringExpense)
+
+			// 预览接下来的N次发生日期
+			recurring.GET("/:id/preview", recurringHandler.PreviewRecurringExpense)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"homemoney/internal/handlers"
+	"homemoney/internal/repository"
+)
+
+// SetupSettlementRoutes 设置共同消费余额和结算相关路由
+func SetupSettlementRoutes(router *gin.Engine, settlementRepo *repository.SettlementRepository, authMiddlewares ...gin.HandlerFunc) {
+	settlementHandler := handlers.NewSettlementHandler(settlementRepo)
+
+	api := router.Group("/api", authMiddlewares...)
+	{
+		// 获取成员之间的余额、欠款和建议转账
+		api.GET("/balances", settlementHandler.GetBalances)
+
+		settlements := api.Group("/settlements")
+		{
+			// 获取结算记录
+			settlements.GET("", settlementHandler.GetSettlements)
+
+			// 记录一笔还款
+			settlements.POST("", settlementHandler.CreateSettlement)
+
+			// 按建议转账结清全部欠款
+			settlements.POST("/settle-all", settlementHandler.SettleAll)
+
+			// 删除结算记录
+			settlements.DELETE("/:id", settlementHandler.DeleteSettlement)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 认证相关错误，处理器据此返回对应的HTTP状态码
+var (
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	ErrUsernameTaken      = errors.New("用户名已被注册")
+	ErrInvalidInviteCode  = errors.New("邀请码无效")
+	ErrInvalidToken       = errors.New("登录已失效，请重新登录")
+	ErrSetupDisabled      = errors.New("未配置AUTH_SETUP_TOKEN，无法初始化系统管理员")
+	ErrInvalidSetupToken  = errors.New("初始化令牌无效")
+	ErrInvalidClaimCode   = errors.New("认领码无效或已过期")
+	ErrMemberClaimed      = errors.New("会员已设置密码，无需认领")
+)
+
+// defaultTokenTTLHours 登录令牌默认有效期（小时），可通过 AUTH_TOKEN_TTL_HOURS 环境变量覆盖
+const defaultTokenTTLHours = 720
+
+// claimCodeTTL 会员认领码有效期
+const claimCodeTTL = 72 * time.Hour
+
+// AuthService 认证服务：注册、登录、令牌校验
+type AuthService struct {
+	authRepo   *repository.AuthRepository
+	memberRepo *repository.MemberRepository
+	tokenTTL   time.Duration
+	// setupToken 初始化系统管理员使用的令牌，为空时不能初始化
+	setupToken string
+}
+
+// NewAuthService 创建新的认证服务，初始化令牌从 AUTH_SETUP_TOKEN 环境变量读取
+func NewAuthService(authRepo *repository.AuthRepository, memberRepo *repository.MemberRepository) *AuthService {
+	ttlHours, err := strconv.Atoi(getEnv("AUTH_TOKEN_TTL_HOURS", strconv.Itoa(defaultTokenTTLHours)))
+	if err != nil || ttlHours <= 0 {
+		ttlHours = defaultTokenTTLHours
+	}
+
+	return &AuthService{
+		authRepo:   authRepo,
+		memberRepo: memberRepo,
+		tokenTTL:   time.Duration(ttlHours) * time.Hour,
+		setupToken: strings.TrimSpace(getEnv("AUTH_SETUP_TOKEN", "")),
+	}
+}
+
+// Register 注册账号并登录
+// inviteCode不为空时加入对应家庭，否则以householdName创建新家庭；
+// 用户名已存在时不能注册，尚未设置密码的旧会员需要使用认领码设置密码，见Claim
+func (s *AuthService) Register(username, password, householdName, inviteCode string) (*models.AuthResult, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, fmt.Errorf("用户名不能为空")
+	}
+
+	exists, err := s.memberRepo.Exists(username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrUsernameTaken
+	}
+	member := &models.Member{Username: username}
+
+	var household *models.Household
+	if inviteCode = strings.TrimSpace(inviteCode); inviteCode != "" {
+		household, err = s.authRepo.FindHouseholdByInviteCode(inviteCode)
+		if err != nil {
+			return nil, err
+		}
+		if household == nil {
+			return nil, ErrInvalidInviteCode
+		}
+	} else {
+		household, err = newHousehold(householdName, username)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setPassword(member, password); err != nil {
+		return nil, err
+	}
+
+	if err := s.authRepo.RegisterMember(member, household); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	return s.issueToken(member, household)
+}
+
+// Setup 使用初始化令牌创建系统管理员及其家庭，该家庭接管启用登录之前没有归属的账本数据；
+// 已有系统管理员后不能再次初始化
+func (s *AuthService) Setup(setupToken, username, password, householdName string) (*models.AuthResult, error) {
+	if s.setupToken == "" {
+		return nil, ErrSetupDisabled
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(setupToken)), []byte(s.setupToken)) != 1 {
+		return nil, ErrInvalidSetupToken
+	}
+
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, fmt.Errorf("用户名不能为空")
+	}
+
+	member, err := s.memberRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if member != nil && member.PasswordHash != "" {
+		return nil, ErrUsernameTaken
+	}
+	if member == nil {
+		member = &models.Member{Username: username}
+	}
+
+	household, err := newHousehold(householdName, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := setPassword(member, password); err != nil {
+		return nil, err
+	}
+
+	if err := s.authRepo.BootstrapAdmin(member, household); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	return s.issueToken(member, household)
+}
+
+// CreateClaimCode 由operator为尚未设置密码的会员生成一次性认领码，同一会员之前的认领码失效；
+// 家庭所有者只能为本家庭的成员生成，系统管理员还可以为没有家庭的旧会员生成，认领后会员加入系统管理员的家庭
+func (s *AuthService) CreateClaimCode(operator *models.Member, username string) (*models.ClaimCodeResult, error) {
+	member, err := s.memberRepo.FindByUsername(strings.TrimSpace(username))
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+	if member.PasswordHash != "" {
+		return nil, ErrMemberClaimed
+	}
+
+	householdID := member.HouseholdID
+	if !operator.Can(models.PermissionAdmin) {
+		if !inSameHousehold(operator, member) {
+			return nil, fmt.Errorf("%w: 只能为本家庭的成员生成认领码", ErrPermissionDenied)
+		}
+	} else if householdID == nil {
+		householdID = operator.HouseholdID
+	}
+	if householdID == nil {
+		return nil, fmt.Errorf("%w: 无法确定会员认领后所属的家庭", ErrPermissionDenied)
+	}
+
+	code, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	claim := &models.MemberClaim{
+		MemberID:    member.ID,
+		CodeHash:    hashToken(code),
+		HouseholdID: *householdID,
+		CreatedBy:   operator.ID,
+		ExpiresAt:   time.Now().Add(claimCodeTTL),
+	}
+	if err := s.authRepo.SaveClaim(claim); err != nil {
+		return nil, fmt.Errorf("保存认领码失败: %w", err)
+	}
+
+	return &models.ClaimCodeResult{
+		Username:  member.Username,
+		ClaimCode: code,
+		ExpiresAt: claim.ExpiresAt,
+	}, nil
+}
+
+// Claim 使用认领码为尚未设置密码的会员设置密码并登录，认领码只能使用一次
+func (s *AuthService) Claim(username, claimCode, password string) (*models.AuthResult, error) {
+	member, err := s.memberRepo.FindByUsername(strings.TrimSpace(username))
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.PasswordHash != "" {
+		return nil, ErrInvalidClaimCode
+	}
+
+	claim, err := s.authRepo.FindClaimByMemberID(member.ID)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil || claim.IsExpired(time.Now()) ||
+		subtle.ConstantTimeCompare([]byte(hashToken(strings.TrimSpace(claimCode))), []byte(claim.CodeHash)) != 1 {
+		return nil, ErrInvalidClaimCode
+	}
+
+	household, err := s.authRepo.FindHouseholdByID(claim.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	if household == nil {
+		return nil, ErrInvalidClaimCode
+	}
+
+	if err := setPassword(member, password); err != nil {
+		return nil, err
+	}
+	if err := s.authRepo.ClaimMember(member, claim); err != nil {
+		if errors.Is(err, repository.ErrClaimUsed) {
+			return nil, ErrInvalidClaimCode
+		}
+		return nil, err
+	}
+
+	return s.issueToken(member, household)
+}
+
+// Login 校验用户名和密码并签发登录令牌
+func (s *AuthService) Login(username, password string) (*models.AuthResult, error) {
+	member, err := s.memberRepo.FindByUsername(strings.TrimSpace(username))
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.PasswordHash == "" || member.HouseholdID == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(member.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	household, err := s.authRepo.FindHouseholdByID(*member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	if household == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issueToken(member, household)
+}
+
+// Logout 注销登录令牌
+func (s *AuthService) Logout(token string) error {
+	return s.authRepo.DeleteSessionByTokenHash(hashToken(token))
+}
+
+// Authenticate 校验登录令牌，返回令牌所属的会员
+func (s *AuthService) Authenticate(token string) (*models.Member, error) {
+	session, err := s.authRepo.FindSessionByTokenHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.IsExpired(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	member, err := s.memberRepo.FindByID(session.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.HouseholdID == nil {
+		return nil, ErrInvalidToken
+	}
+	return member, nil
+}
+
+// GetHousehold 获取家庭信息
+func (s *AuthService) GetHousehold(id uint) (*models.Household, error) {
+	return s.authRepo.FindHouseholdByID(id)
+}
+
+// UpdateBaseCurrency 修改家庭本位币，返回修改后的家庭
+func (s *AuthService) UpdateBaseCurrency(householdID uint, currency string) (*models.Household, error) {
+	currency = models.NormalizeCurrency(currency)
+	if err := models.ValidateCurrency(currency); err != nil {
+		return nil, fmt.Errorf("验证失败: %w", err)
+	}
+
+	household, err := s.authRepo.FindHouseholdByID(householdID)
+	if err != nil {
+		return nil, err
+	}
+	if household == nil {
+		return nil, fmt.Errorf("记录不存在")
+	}
+	if household.BaseCurrency == currency {
+		return household, nil
+	}
+
+	if err := s.authRepo.UpdateBaseCurrency(household, currency); err != nil {
+		return nil, err
+	}
+	household.BaseCurrency = currency
+	return household, nil
+}
+
+// issueToken 生成随机令牌并保存其摘要
+func (s *AuthService) issueToken(member *models.Member, household *models.Household) (*models.AuthResult, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.AuthSession{
+		TokenHash: hashToken(token),
+		MemberID:  member.ID,
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	}
+	if err := s.authRepo.CreateSession(session); err != nil {
+		return nil, fmt.Errorf("创建登录会话失败: %w", err)
+	}
+
+	return &models.AuthResult{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		Member:    member,
+		Household: household,
+	}, nil
+}
+
+// newHousehold 生成带随机邀请码的新家庭，名称为空时使用"<用户名>的家"
+func newHousehold(name, username string) (*models.Household, error) {
+	code, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = username + "的家"
+	}
+	return &models.Household{Name: name, InviteCode: code, BaseCurrency: models.DefaultBaseCurrency}, nil
+}
+
+// setPassword 计算并设置会员的密码摘要
+func setPassword(member *models.Member, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %w", err)
+	}
+	member.PasswordHash = string(hash)
+	return nil
+}
+
+// randomHex 生成n字节的随机数并编码为十六进制字符串
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken 计算令牌的SHA-256摘要，数据库中只保存摘要
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"1-2-3 * * * *",
+		"@yearly",
+		"@every",
+		"@every x",
+		"@every 10ms",
+	}
+	for _, spec := range specs {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) 应返回错误", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(value string) time.Time {
+		for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05"} {
+			if parsed, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+				return parsed
+			}
+		}
+		t.Fatalf("解析时间%s失败", value)
+		return time.Time{}
+	}
+
+	tests := []struct {
+		spec  string
+		after string
+		want  string
+	}{
+		// 整点跨年
+		{"0 * * * *", "2026-12-31 23:30", "2027-01-01 00:00"},
+		{"@hourly", "2026-10-17 10:00", "2026-10-17 11:00"},
+		// 步长从0开始，结果严格晚于after
+		{"*/15 * * * *", "2026-10-17 10:59", "2026-10-17 11:00"},
+		{"*/15 * * * *", "2026-10-17 10:15", "2026-10-17 10:30"},
+		{"*/10 * * * *", "2026-10-17 10:05:59", "2026-10-17 10:10"},
+		// 起始值加步长一直取到最大值，范围加步长只在范围内取值
+		{"10/20 * * * *", "2026-10-17 10:50", "2026-10-17 11:10"},
+		{"5-10/2 * * * *", "2026-10-17 10:06", "2026-10-17 10:07"},
+		{"5-10/2 * * * *", "2026-10-17 10:09", "2026-10-17 11:05"},
+		// 跨月和跨年
+		{"@monthly", "2026-01-31 12:00", "2026-02-01 00:00"},
+		{"@daily", "2026-12-31 00:00", "2027-01-01 00:00"},
+		{"0 12 * 1,7 *", "2026-07-31 13:00", "2027-01-01 12:00"},
+		// 没有31日的月份和非闰年跳过
+		{"0 0 31 * *", "2026-04-01 00:00", "2026-05-31 00:00"},
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		// 周字段：范围、周日写作0或7
+		{"30 2 * * 1-5", "2026-10-16 03:00", "2026-10-19 02:30"},
+		{"0 0 * * 0", "2026-10-17 12:00", "2026-10-18 00:00"},
+		{"0 0 * * 7", "2026-10-17 12:00", "2026-10-18 00:00"},
+		{"@weekly", "2026-10-18 00:00", "2026-10-25 00:00"},
+		// 日和周都有限制时满足任一即可
+		{"0 9 1 * 1", "2026-10-17 00:00", "2026-10-19 09:00"},
+		{"0 9 1 * 1", "2026-10-26 10:00", "2026-11-01 09:00"},
+		{"0 0 13 * 5", "2026-10-17 00:00", "2026-10-23 00:00"},
+		{"@every 90m", "2026-10-17 23:00", "2026-10-18 00:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+" after "+tt.after, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) 返回错误: %v", tt.spec, err)
+			}
+			if got, want := schedule.Next(at(tt.after)), at(tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s，期望 %s", tt.after, got.Format("2006-01-02 15:04"), want.Format("2006-01-02 15:04"))
+			}
+		})
+	}
+}
+
+func TestRegisterScheduleFromEnv(t *testing.T) {
+	const job = "test-job"
+	noop := func(ctx context.Context) error { return nil }
+
+	if spec := JobScheduleFromEnv(job, "@daily"); spec != "@daily" {
+		t.Fatalf("未设置环境变量时应使用默认规则，实际为%q", spec)
+	}
+
+	tests := []struct {
+		env     string
+		wantErr bool
+	}{
+		{"0 3 * * *", false},
+		{"off", false},
+		{"0 25 * * *", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("JOB_SCHEDULE_TEST_JOB", tt.env)
+		spec := JobScheduleFromEnv(job, "@daily")
+		if spec != tt.env {
+			t.Fatalf("JOB_SCHEDULE_TEST_JOB=%q 时读取到%q", tt.env, spec)
+		}
+		// 无效的调度规则在注册时报错，服务不会带着错误的规则启动
+		err := NewScheduler().Register(job, spec, false, noop)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Register(%q) 错误 = %v，期望返回错误: %v", spec, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+)
+
+// DonationService 捐赠记录服务
+type DonationService struct {
+	donationRepo *repository.DonationRepository
+}
+
+// NewDonationService 创建捐赠记录服务
+func NewDonationService(donationRepo *repository.DonationRepository) *DonationService {
+	return &DonationService{
+		donationRepo: donationRepo,
+	}
+}
+
+// ListDonations 分页查询捐赠记录，同时返回满足条件的汇总
+func (s *DonationService) ListDonations(query *models.DonationQuery) ([]models.DonationRecord, int64, *models.DonationSummary, error) {
+	if err := query.Validate(); err != nil {
+		return nil, 0, nil, err
+	}
+	records, total, err := s.donationRepo.FindWithPagination(query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	summary, err := s.donationRepo.Summarize(query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return records, total, summary, nil
+}
+
+// Leaderboard 获取捐赠排行榜
+func (s *DonationService) Leaderboard(query *models.DonationQuery, limit int) ([]models.DonorTotal, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	return s.donationRepo.Leaderboard(query, limit)
+}
+
+// ImportJSONL 导入每行一条JSON的捐赠记录，无法解析或缺少必填字段的行计为无效，
+// 已存在的记录（ID相同）计为重复，因此可以重复导入同一个文件
+func (s *DonationService) ImportJSONL(reader io.Reader) (*models.DonationImportResult, error) {
+	result := &models.DonationImportResult{}
+	var records []models.DonationRecord
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		record, err := parseDonationLine(line)
+		if err != nil {
+			log.Printf("捐赠记录第%d行无效: %v", lineNo, err)
+			result.Invalid++
+			continue
+		}
+		records = append(records, *record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取捐赠记录文件失败: %w", err)
+	}
+
+	imported, err := s.donationRepo.Import(records)
+	if err != nil {
+		return nil, fmt.Errorf("导入捐赠记录失败: %w", err)
+	}
+	result.Imported = imported
+	result.Duplicates = len(records) - imported
+	return result, nil
+}
+
+// ImportLegacyFile 一次性导入旧版的捐赠记录文件，导入成功后将文件重命名为*.imported，文件不存在时直接返回
+func (s *DonationService) ImportLegacyFile(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := s.ImportJSONL(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(path, path+".imported"); err != nil {
+		return fmt.Errorf("重命名捐赠记录文件失败: %w", err)
+	}
+	log.Printf("已导入捐赠记录文件%s: 新增%d条，重复%d条，无效%d条", path, result.Imported, result.Duplicates, result.Invalid)
+	return nil
+}
+
+// parseDonationLine 解析一行捐赠记录，缺少ID时用该行内容的哈希作为ID，保证重复导入不会产生新记录
+func parseDonationLine(line string) (*models.DonationRecord, error) {
+	var raw struct {
+		ID        json.RawMessage `json:"id"`
+		Username  string          `json:"username"`
+		Amount    json.Number     `json:"amount"`
+		Timestamp string          `json:"timestamp"`
+		OrderID   string          `json:"orderId"`
+		Status    string          `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return nil, err
+	}
+
+	amount, err := models.ParseMoney(raw.Amount.String())
+	if err != nil || amount <= 0 {
+		return nil, fmt.Errorf("金额无效: %s", raw.Amount)
+	}
+	if raw.Username == "" {
+		return nil, errors.New("用户名为空")
+	}
+	if _, err := time.Parse("2006-01-02 15:04:05", raw.Timestamp); err != nil {
+		return nil, fmt.Errorf("时间格式无效: %s", raw.Timestamp)
+	}
+
+	// 旧版文件中的id可能是字符串或数字
+	id := strings.Trim(string(raw.ID), `"`)
+	if id == "" || id == "null" {
+		sum := sha256.Sum256([]byte(line))
+		id = hex.EncodeToString(sum[:16])
+	}
+
+	status := raw.Status
+	if status == "" {
+		status = models.DonationSuccess
+	}
+
+	return &models.DonationRecord{
+		ID:        id,
+		Username:  raw.Username,
+		Amount:    amount,
+		Timestamp: raw.Timestamp,
+		OrderID:   raw.OrderID,
+		Status:    status,
+	}, nil
+}
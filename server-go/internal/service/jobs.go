@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"homemoney/internal/repository"
+)
+
+// 后台任务名称
+const (
+	JobRecurringExpenses    = "recurring-expenses"
+	JobExpireSubscriptions  = "expire-subscriptions"
+	JobRenewSubscriptions   = "renew-subscriptions"
+	JobCleanExpiredSessions = "clean-expired-sessions"
+	JobSendNotifications    = "send-notifications"
+)
+
+// RegisterMaintenanceJobs 注册系统后台任务，调度规则可通过 JOB_SCHEDULE_<任务名> 环境变量覆盖
+func RegisterMaintenanceJobs(scheduler *Scheduler, recurringRepo *repository.RecurringExpenseRepository, subscriptionService *SubscriptionService, paymentService *PaymentService, notificationService *NotificationService, authRepo *repository.AuthRepository) error {
+	jobs := []struct {
+		name        string
+		defaultSpec string
+		runOnStart  bool
+		run         JobFunc
+	}{
+		// 周期性消费：启动时补齐错过的记录，之后每小时检查一次
+		{JobRecurringExpenses, "@every 1h", true, NewRecurringExpenseJob(recurringRepo)},
+		// 订阅过期检查（包括宽限期结束的订阅）：启动时检查一次，之后每10分钟检查一次
+		{JobExpireSubscriptions, "*/10 * * * *", true, func(ctx context.Context) error {
+			return subscriptionService.CheckExpiredSubscriptions()
+		}},
+		// 自动续费：每小时整点为到期和到了重试时间的订阅发起扣款
+		{JobRenewSubscriptions, "0 * * * *", false, paymentService.ProcessAutoRenewals},
+		// 订阅到期、续费失败和预算超支提醒：每30分钟检查一次，已发送的提醒不会重复发送
+		{JobSendNotifications, "*/30 * * * *", false, notificationService.SendReminders},
+		// 清理过期的登录会话：每天凌晨处理一次
+		{JobCleanExpiredSessions, "@daily", false, func(ctx context.Context) error {
+			deleted, err := authRepo.DeleteExpiredSessions(time.Now())
+			if err == nil && deleted > 0 {
+				log.Printf("已清理 %d 个过期登录会话", deleted)
+			}
+			return err
+		}},
+	}
+
+	for _, job := range jobs {
+		spec := JobScheduleFromEnv(job.name, job.defaultSpec)
+		if err := scheduler.Register(job.name, spec, job.runOnStart, job.run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
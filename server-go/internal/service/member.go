@@ -1,12 +1,19 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 
 	"homemoney/internal/models"
 	"homemoney/internal/repository"
 )
 
+// 会员管理相关错误
+var (
+	ErrMemberNotFound   = errors.New("会员不存在")
+	ErrPermissionDenied = errors.New("权限不足")
+)
+
 // MemberService 会员服务
 type MemberService struct {
 	memberRepo       *repository.MemberRepository
@@ -21,22 +28,32 @@ func NewMemberService(memberRepo *repository.MemberRepository, subscriptionRepo
 	}
 }
 
-// GetOrCreateMember 获取或创建会员
-func (s *MemberService) GetOrCreateMember(username string) (*models.Member, error) {
+// GetOrCreateMember 获取或创建operator家庭的会员
+// 新会员属于operator的家庭且没有密码，需要家庭所有者生成认领码后才能登录；
+// 已存在的其他家庭的会员只有系统管理员可以获取
+func (s *MemberService) GetOrCreateMember(operator *models.Member, username string) (*models.Member, error) {
 	if username == "" {
 		return nil, fmt.Errorf("用户名不能为空")
 	}
-	return s.memberRepo.GetOrCreate(username)
-}
+	if operator.HouseholdID == nil {
+		return nil, fmt.Errorf("%w: 当前会员不属于任何家庭", ErrPermissionDenied)
+	}
 
-// GetMemberInfo 获取会员信息
-func (s *MemberService) GetMemberInfo(username string) (*models.MemberResponse, error) {
-	member, err := s.memberRepo.GetMemberInfo(username)
+	member, err := s.memberRepo.GetOrCreate(username, operator.HouseholdID)
 	if err != nil {
 		return nil, err
 	}
-	if member == nil {
-		return nil, fmt.Errorf("会员不存在")
+	if !operator.Can(models.PermissionAdmin) && !inSameHousehold(operator, member) {
+		return nil, fmt.Errorf("%w: 用户名已被其他家庭使用", ErrPermissionDenied)
+	}
+	return member, nil
+}
+
+// GetMemberInfo 获取operator可以查看的会员信息，其他家庭的会员视为不存在
+func (s *MemberService) GetMemberInfo(operator *models.Member, username string) (*models.MemberResponse, error) {
+	member, err := findAccessibleMember(s.memberRepo, operator, username)
+	if err != nil {
+		return nil, err
 	}
 
 	// 获取当前订阅状态
@@ -69,13 +86,53 @@ func (s *MemberService) UpdateMemberStatus(username string, isActive bool) error
 	return s.memberRepo.UpdateMemberStatus(username, isActive)
 }
 
-// GetMemberSubscriptions 获取会员订阅列表
-func (s *MemberService) GetMemberSubscriptions(username string) ([]models.UserSubscription, error) {
+// UpdateMemberRole 由operator设置会员角色
+// 家庭所有者只能管理本家庭的成员，授予或撤销admin角色需要系统管理员，不能修改自己的角色
+func (s *MemberService) UpdateMemberRole(operator *models.Member, username, role string) (*models.Member, error) {
+	if err := models.ValidateRole(role); err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+
+	if member.ID == operator.ID {
+		return nil, fmt.Errorf("%w: 不能修改自己的角色", ErrPermissionDenied)
+	}
+	if !operator.Can(models.PermissionAdmin) {
+		if !inSameHousehold(operator, member) {
+			return nil, fmt.Errorf("%w: 只能管理本家庭的成员", ErrPermissionDenied)
+		}
+		if role == models.RoleAdmin || member.Role == models.RoleAdmin {
+			return nil, fmt.Errorf("%w: 只有系统管理员可以授予或撤销admin角色", ErrPermissionDenied)
+		}
+	}
+
+	member.Role = role
+	if err := s.memberRepo.Update(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// GetMemberSubscriptions 获取operator可以查看的会员的订阅列表
+func (s *MemberService) GetMemberSubscriptions(operator *models.Member, username string) ([]models.UserSubscription, error) {
+	if _, err := findAccessibleMember(s.memberRepo, operator, username); err != nil {
+		return nil, err
+	}
 	return s.memberRepo.GetMemberSubscriptions(username)
 }
 
-// GetMemberWithActiveSubscription 获取会员及其活跃订阅
-func (s *MemberService) GetMemberWithActiveSubscription(username string) (*models.Member, *models.UserSubscription, error) {
+// GetMemberWithActiveSubscription 获取operator可以查看的会员及其活跃订阅
+func (s *MemberService) GetMemberWithActiveSubscription(operator *models.Member, username string) (*models.Member, *models.UserSubscription, error) {
+	if _, err := findAccessibleMember(s.memberRepo, operator, username); err != nil {
+		return nil, nil, err
+	}
 	return s.memberRepo.GetMemberWithActiveSubscription(username)
 }
 
@@ -84,13 +141,30 @@ func (s *MemberService) GetMemberList() ([]models.Member, error) {
 	return s.memberRepo.FindAll()
 }
 
+// canAccessMember operator是否可以查看member，系统管理员可以查看所有会员，其他角色只能查看本家庭的会员
+func canAccessMember(operator, member *models.Member) bool {
+	return operator.Can(models.PermissionAdmin) || inSameHousehold(operator, member)
+}
+
+// findAccessibleMember 按用户名查找operator可以查看的会员，不存在或属于其他家庭时返回ErrMemberNotFound
+func findAccessibleMember(memberRepo *repository.MemberRepository, operator *models.Member, username string) (*models.Member, error) {
+	member, err := memberRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || !canAccessMember(operator, member) {
+		return nil, ErrMemberNotFound
+	}
+	return member, nil
+}
+
+// inSameHousehold 两个会员是否属于同一个家庭
+func inSameHousehold(a, b *models.Member) bool {
+	return a.HouseholdID != nil && b.HouseholdID != nil && *a.HouseholdID == *b.HouseholdID
+}
+
 // CheckSubscriptionStatus 检查并更新订阅状态
 func (s *MemberService) CheckSubscriptionStatus() error {
 	// 检查并使过期订阅失效
 	return s.subscriptionRepo.CheckAndExpireSubscriptions()
-}
-
-// ProcessAutoRenewal 处理自动续费
-func (s *MemberService) ProcessAutoRenewal() error {
-	return s.subscriptionRepo.AutoRenewSubscriptions()
 }
\ No newline at end of file
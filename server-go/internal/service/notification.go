@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+)
+
+// ErrInboxMessageNotFound 站内信不存在
+var ErrInboxMessageNotFound = errors.New("站内信不存在")
+
+// NotificationConfig 通知配置
+type NotificationConfig struct {
+	// DefaultLocale 会员未设置语言时使用的通知语言
+	DefaultLocale string
+	// ExpiringDays 订阅到期前多少天发送提醒
+	ExpiringDays int
+}
+
+// NotificationConfigFromEnv 从环境变量读取通知配置：NOTIFY_DEFAULT_LOCALE（默认zh）、NOTIFY_EXPIRING_DAYS（默认7）
+func NotificationConfigFromEnv() NotificationConfig {
+	config := NotificationConfig{
+		DefaultLocale: getEnv("NOTIFY_DEFAULT_LOCALE", models.LocaleZh),
+		ExpiringDays:  7,
+	}
+	if models.ValidateLocale(config.DefaultLocale) != nil {
+		config.DefaultLocale = models.LocaleZh
+	}
+	if days, err := strconv.Atoi(getEnv("NOTIFY_EXPIRING_DAYS", "")); err == nil && days > 0 {
+		config.ExpiringDays = days
+	}
+	return config
+}
+
+// NotificationService 通知服务，检查订阅到期、续费失败和预算超支并通过各渠道提醒会员，
+// 每条提醒在每个渠道只成功发送一次
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	subscriptionRepo *repository.UserSubscriptionRepository
+	budgetRepo       *repository.BudgetRepository
+	notifiers        []Notifier
+	config           NotificationConfig
+}
+
+// NewNotificationService 创建通知服务
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	subscriptionRepo *repository.UserSubscriptionRepository,
+	budgetRepo *repository.BudgetRepository,
+	notifiers []Notifier,
+	config NotificationConfig,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		subscriptionRepo: subscriptionRepo,
+		budgetRepo:       budgetRepo,
+		notifiers:        notifiers,
+		config:           config,
+	}
+}
+
+// SendReminders 检查并发送全部提醒，有发送失败的通知时返回错误，失败的通知在下次运行时重试
+func (s *NotificationService) SendReminders(ctx context.Context) error {
+	var errs []error
+	for _, check := range []func(context.Context) (int, error){
+		s.notifyExpiringSubscriptions,
+		s.notifyRenewalFailures,
+		s.notifyBudgetOverruns,
+	} {
+		failed, err := check(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if failed > 0 {
+			errs = append(errs, fmt.Errorf("%d条通知发送失败", failed))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifyExpiringSubscriptions 提醒即将到期的订阅，每个订阅的每个到期日只提醒一次
+func (s *NotificationService) notifyExpiringSubscriptions(ctx context.Context) (int, error) {
+	subscriptions, err := s.subscriptionRepo.GetExpiringSubscriptions(s.config.ExpiringDays)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		dedupKey := fmt.Sprintf("%s:%s:%s", models.NotificationSubscriptionExpiring, subscription.ID, subscription.EndDate.Format("2006-01-02"))
+		n, err := s.notify(ctx, &subscription.Member, models.NotificationSubscriptionExpiring, dedupKey, map[string]interface{}{
+			"PlanName":  subscription.Plan.Name,
+			"EndDate":   subscription.EndDate.Format("2006-01-02"),
+			"DaysLeft":  int(math.Ceil(time.Until(subscription.EndDate).Hours() / 24)),
+			"AutoRenew": subscription.AutoRenew,
+			"Price":     subscription.Plan.Price,
+		})
+		if err != nil {
+			return failed, err
+		}
+		failed += n
+	}
+	return failed, nil
+}
+
+// notifyRenewalFailures 提醒自动续费扣款失败的订阅，每次扣款失败提醒一次
+func (s *NotificationService) notifyRenewalFailures(ctx context.Context) (int, error) {
+	subscriptions, err := s.notificationRepo.FindPastDueSubscriptions()
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		dedupKey := fmt.Sprintf("%s:%s:%s:%d", models.NotificationRenewalFailed, subscription.ID,
+			subscription.EndDate.Format("2006-01-02"), subscription.RenewalAttempts)
+		data := map[string]interface{}{
+			"PlanName":    subscription.Plan.Name,
+			"Attempts":    subscription.RenewalAttempts,
+			"NextRetryAt": "",
+			"GraceEndsAt": subscription.EndDate.Format("2006-01-02 15:04"),
+		}
+		if subscription.NextRenewalAt != nil {
+			data["NextRetryAt"] = subscription.NextRenewalAt.Format("2006-01-02 15:04")
+		}
+		if subscription.GraceEndsAt != nil {
+			data["GraceEndsAt"] = subscription.GraceEndsAt.Format("2006-01-02 15:04")
+		}
+		n, err := s.notify(ctx, &subscription.Member, models.NotificationRenewalFailed, dedupKey, data)
+		if err != nil {
+			return failed, err
+		}
+		failed += n
+	}
+	return failed, nil
+}
+
+// notifyBudgetOverruns 提醒家庭全部成员本月超支的预算，每个预算每月提醒一次
+func (s *NotificationService) notifyBudgetOverruns(ctx context.Context) (int, error) {
+	householdIDs, err := s.notificationRepo.FindHouseholdsWithBudgets()
+	if err != nil {
+		return 0, err
+	}
+
+	month := time.Now().Format("2006-01")
+	failed := 0
+	for _, householdID := range householdIDs {
+		statuses, err := s.budgetRepo.ForHousehold(householdID).GetStatus(month)
+		if err != nil {
+			return failed, err
+		}
+		var members []models.Member
+		for _, status := range statuses {
+			if !status.OverBudget {
+				continue
+			}
+			if members == nil {
+				if members, err = s.notificationRepo.FindHouseholdMembers(householdID); err != nil {
+					return failed, err
+				}
+			}
+
+			dedupKey := fmt.Sprintf("%s:%d:%s", models.NotificationBudgetOverrun, status.BudgetID, month)
+			for i := range members {
+				n, err := s.notify(ctx, &members[i], models.NotificationBudgetOverrun, dedupKey, map[string]interface{}{
+					"Month":    month,
+					"Category": status.Category,
+					"Budget":   status.Budget,
+					"Spent":    status.Spent,
+					"Percent":  status.Percent,
+					"Over":     status.Spent - status.Budget,
+				})
+				if err != nil {
+					return failed, err
+				}
+				failed += n
+			}
+		}
+	}
+	return failed, nil
+}
+
+// notify 按会员的语言渲染通知，通过尚未成功发送过的渠道发送并记录结果，返回发送失败的渠道数；
+// 只有读写发送记录出错时返回错误
+func (s *NotificationService) notify(ctx context.Context, member *models.Member, kind, dedupKey string, data map[string]interface{}) (int, error) {
+	if member.ID == "" {
+		return 0, nil
+	}
+
+	locale := notificationLocale(member, s.config.DefaultLocale)
+	data["Username"] = member.Username
+	subject, body, err := renderNotification(kind, locale, data)
+	if err != nil {
+		return 0, err
+	}
+	message := &NotificationMessage{
+		Kind:     kind,
+		DedupKey: dedupKey,
+		Locale:   locale,
+		Subject:  subject,
+		Body:     body,
+		Data:     data,
+	}
+
+	failed := 0
+	for _, notifier := range s.notifiers {
+		sent, err := s.notificationRepo.WasSent(dedupKey, notifier.Channel(), member.ID)
+		if err != nil {
+			return failed, err
+		}
+		if sent {
+			continue
+		}
+
+		sendErr := notifier.Send(ctx, member, message)
+		if errors.Is(sendErr, ErrNoRecipientAddress) {
+			continue
+		}
+
+		record := &models.NotificationRecord{
+			DedupKey: dedupKey,
+			Channel:  notifier.Channel(),
+			MemberID: member.ID,
+			Username: member.Username,
+			Kind:     kind,
+			Locale:   locale,
+			Subject:  subject,
+			Status:   models.NotificationSent,
+		}
+		if sendErr != nil {
+			failed++
+			record.Status = models.NotificationFailed
+			record.Error = sendErr.Error()
+			log.Printf("通过%s向%s发送通知失败: %v", notifier.Channel(), member.Username, sendErr)
+		} else {
+			now := time.Now()
+			record.SentAt = &now
+		}
+		if err := s.notificationRepo.SaveRecord(record); err != nil {
+			return failed, err
+		}
+	}
+	return failed, nil
+}
+
+// ListInbox 分页获取会员的站内信和未读数量
+func (s *NotificationService) ListInbox(memberID string, unreadOnly bool, limit, offset int) ([]models.InboxMessage, int64, int64, error) {
+	return s.notificationRepo.FindInboxMessages(memberID, unreadOnly, limit, offset)
+}
+
+// MarkRead 将会员的一条站内信标记为已读
+func (s *NotificationService) MarkRead(memberID string, id uint) (*models.InboxMessage, error) {
+	message, err := s.notificationRepo.FindInboxMessage(memberID, id)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, ErrInboxMessageNotFound
+	}
+	if _, err := s.notificationRepo.MarkInboxRead(memberID, id); err != nil {
+		return nil, err
+	}
+	return s.notificationRepo.FindInboxMessage(memberID, id)
+}
+
+// MarkAllRead 将会员的全部站内信标记为已读，返回标记的数量
+func (s *NotificationService) MarkAllRead(memberID string) (int64, error) {
+	return s.notificationRepo.MarkInboxRead(memberID, 0)
+}
+
+// GetSettings 获取会员的通知设置，返回的语言为实际使用的语言
+func (s *NotificationService) GetSettings(member *models.Member) map[string]interface{} {
+	return map[string]interface{}{
+		"email":    member.Email,
+		"locale":   notificationLocale(member, s.config.DefaultLocale),
+		"channels": s.Channels(),
+	}
+}
+
+// UpdateSettings 更新会员的通知邮箱和语言，未提供的字段保持不变，邮箱为空字符串表示不接收邮件
+func (s *NotificationService) UpdateSettings(member *models.Member, settings *models.NotificationSettings) (map[string]interface{}, error) {
+	email := member.Email
+	locale := member.Locale
+	if settings.Email != nil {
+		email = strings.TrimSpace(*settings.Email)
+		if email != "" {
+			address, err := mail.ParseAddress(email)
+			if err != nil || address.Address != email {
+				return nil, errors.New("邮箱格式错误")
+			}
+		}
+	}
+	if settings.Locale != nil {
+		locale = strings.ToLower(strings.TrimSpace(*settings.Locale))
+		if err := models.ValidateLocale(locale); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.notificationRepo.UpdateSettings(member.ID, email, locale); err != nil {
+		return nil, err
+	}
+	member.Email = email
+	member.Locale = locale
+	return s.GetSettings(member), nil
+}
+
+// ListRecords 分页查询通知发送记录
+func (s *NotificationService) ListRecords(query *models.NotificationQuery) ([]models.NotificationRecord, int64, error) {
+	if err := query.Validate(); err != nil {
+		return nil, 0, err
+	}
+	return s.notificationRepo.FindRecords(query)
+}
+
+// Channels 已启用的通知渠道
+func (s *NotificationService) Channels() []string {
+	channels := make([]string, 0, len(s.notifiers))
+	for _, notifier := range s.notifiers {
+		channels = append(channels, notifier.Channel())
+	}
+	return channels
+}
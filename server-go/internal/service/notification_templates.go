@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"homemoney/internal/models"
+)
+
+// notificationTemplate 一种通知在一种语言下的标题和正文模板
+type notificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// notificationTemplateSources 通知模板，按通知类型和语言组织，金额保留两位小数
+var notificationTemplateSources = map[string]map[string][2]string{
+	models.NotificationSubscriptionExpiring: {
+		models.LocaleZh: {
+			"您的{{.PlanName}}订阅将于{{.EndDate}}到期",
+			"{{.Username}}，您好：\n\n您的{{.PlanName}}订阅将于{{.EndDate}}到期，剩余{{.DaysLeft}}天。" +
+				"{{if .AutoRenew}}到期时将自动续费{{.Price}}元，请确保支付方式可用。{{else}}如需继续使用会员权益，请及时续费。{{end}}",
+		},
+		models.LocaleEn: {
+			"Your {{.PlanName}} subscription expires on {{.EndDate}}",
+			"Hi {{.Username}},\n\nYour {{.PlanName}} subscription expires on {{.EndDate}} ({{.DaysLeft}} day(s) left). " +
+				"{{if .AutoRenew}}It will be renewed automatically for {{.Price}}; please make sure your payment method is available.{{else}}Please renew it in time to keep your membership benefits.{{end}}",
+		},
+	},
+	models.NotificationRenewalFailed: {
+		models.LocaleZh: {
+			"{{.PlanName}}订阅自动续费失败",
+			"{{.Username}}，您好：\n\n您的{{.PlanName}}订阅第{{.Attempts}}次自动续费扣款未成功。" +
+				"{{if .NextRetryAt}}我们将于{{.NextRetryAt}}再次尝试扣款。{{end}}会员权益将保留至{{.GraceEndsAt}}，届时仍未续费成功订阅将过期。",
+		},
+		models.LocaleEn: {
+			"Automatic renewal of your {{.PlanName}} subscription failed",
+			"Hi {{.Username}},\n\nAttempt {{.Attempts}} to renew your {{.PlanName}} subscription was not successful. " +
+				"{{if .NextRetryAt}}We will try again on {{.NextRetryAt}}. {{end}}Your membership benefits are kept until {{.GraceEndsAt}}; the subscription expires if it has not been renewed by then.",
+		},
+	},
+	models.NotificationBudgetOverrun: {
+		models.LocaleZh: {
+			"{{.Month}}{{if .Category}}“{{.Category}}”{{else}}总{{end}}预算已超支",
+			"{{.Username}}，您好：\n\n{{.Month}}{{if .Category}}“{{.Category}}”{{else}}总{{end}}预算{{.Budget}}元，" +
+				"已支出{{.Spent}}元（{{.Percent}}%），超出{{.Over}}元。",
+		},
+		models.LocaleEn: {
+			"{{if .Category}}Budget for {{.Category}}{{else}}Total budget{{end}} exceeded in {{.Month}}",
+			"Hi {{.Username}},\n\n{{if .Category}}The budget for {{.Category}}{{else}}The total budget{{end}} in {{.Month}} is {{.Budget}}; " +
+				"{{.Spent}} has been spent ({{.Percent}}%), {{.Over}} over budget.",
+		},
+	},
+}
+
+// notificationTemplates 解析后的通知模板
+var notificationTemplates = parseNotificationTemplates()
+
+// parseNotificationTemplates 解析全部通知模板，模板有误时启动即失败
+func parseNotificationTemplates() map[string]map[string]notificationTemplate {
+	templates := make(map[string]map[string]notificationTemplate)
+	for kind, locales := range notificationTemplateSources {
+		templates[kind] = make(map[string]notificationTemplate)
+		for locale, source := range locales {
+			name := kind + "." + locale
+			templates[kind][locale] = notificationTemplate{
+				subject: template.Must(template.New(name + ".subject").Option("missingkey=error").Parse(source[0])),
+				body:    template.Must(template.New(name + ".body").Option("missingkey=error").Parse(source[1])),
+			}
+		}
+	}
+	return templates
+}
+
+// renderNotification 按通知类型和语言渲染标题和正文
+func renderNotification(kind, locale string, data map[string]interface{}) (string, string, error) {
+	tmpl, ok := notificationTemplates[kind][locale]
+	if !ok {
+		return "", "", fmt.Errorf("没有%s通知的%s模板", kind, locale)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
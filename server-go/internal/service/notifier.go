@@ -0,0 +1,224 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+)
+
+// ErrNoRecipientAddress 会员没有配置该渠道的接收地址，跳过该渠道且不记录
+var ErrNoRecipientAddress = errors.New("会员未配置该渠道的接收地址")
+
+// NotificationMessage 渲染后的通知内容
+type NotificationMessage struct {
+	Kind     string
+	DedupKey string
+	Locale   string
+	Subject  string
+	Body     string
+	// Data 渲染模板使用的数据，webhook原样发送
+	Data map[string]interface{}
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	// Channel 渠道名称：email、webhook、inbox
+	Channel() string
+	// Send 向会员发送通知，会员没有该渠道的接收地址时返回ErrNoRecipientAddress
+	Send(ctx context.Context, member *models.Member, message *NotificationMessage) error
+}
+
+// NotifiersFromEnv 按环境变量配置的渠道创建通知渠道，站内信始终启用；
+// 配置NOTIFY_SMTP_HOST时启用邮件，配置NOTIFY_WEBHOOK_URL时启用webhook
+func NotifiersFromEnv(notificationRepo *repository.NotificationRepository) []Notifier {
+	notifiers := []Notifier{NewInboxNotifier(notificationRepo)}
+	if config, ok := SMTPConfigFromEnv(); ok {
+		notifiers = append(notifiers, NewSMTPNotifier(config))
+	}
+	if url := getEnv("NOTIFY_WEBHOOK_URL", ""); url != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(url, getEnv("NOTIFY_WEBHOOK_SECRET", ""), 10*time.Second))
+	}
+	return notifiers
+}
+
+// InboxNotifier 站内信渠道
+type InboxNotifier struct {
+	notificationRepo *repository.NotificationRepository
+}
+
+// NewInboxNotifier 创建站内信渠道
+func NewInboxNotifier(notificationRepo *repository.NotificationRepository) *InboxNotifier {
+	return &InboxNotifier{notificationRepo: notificationRepo}
+}
+
+// Channel 渠道名称
+func (n *InboxNotifier) Channel() string {
+	return models.NotificationChannelInbox
+}
+
+// Send 写入会员的站内信
+func (n *InboxNotifier) Send(ctx context.Context, member *models.Member, message *NotificationMessage) error {
+	return n.notificationRepo.CreateInboxMessage(&models.InboxMessage{
+		MemberID: member.ID,
+		Kind:     message.Kind,
+		Subject:  message.Subject,
+		Body:     message.Body,
+	})
+}
+
+// SMTPConfig 邮件服务器配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From 发件人地址
+	From string
+}
+
+// SMTPConfigFromEnv 从环境变量读取邮件服务器配置，未配置NOTIFY_SMTP_HOST时返回false
+func SMTPConfigFromEnv() (SMTPConfig, bool) {
+	config := SMTPConfig{
+		Host:     getEnv("NOTIFY_SMTP_HOST", ""),
+		Port:     587,
+		Username: getEnv("NOTIFY_SMTP_USERNAME", ""),
+		Password: getEnv("NOTIFY_SMTP_PASSWORD", ""),
+		From:     getEnv("NOTIFY_SMTP_FROM", ""),
+	}
+	if port, err := strconv.Atoi(getEnv("NOTIFY_SMTP_PORT", "")); err == nil && port > 0 {
+		config.Port = port
+	}
+	if config.From == "" {
+		config.From = config.Username
+	}
+	return config, config.Host != ""
+}
+
+// SMTPNotifier 邮件渠道，服务器支持时使用STARTTLS
+type SMTPNotifier struct {
+	config SMTPConfig
+}
+
+// NewSMTPNotifier 创建邮件渠道
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{config: config}
+}
+
+// Channel 渠道名称
+func (n *SMTPNotifier) Channel() string {
+	return models.NotificationChannelEmail
+}
+
+// Send 向会员的通知邮箱发送邮件
+func (n *SMTPNotifier) Send(ctx context.Context, member *models.Member, message *NotificationMessage) error {
+	if member.Email == "" {
+		return ErrNoRecipientAddress
+	}
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+	addr := n.config.Host + ":" + strconv.Itoa(n.config.Port)
+	return smtp.SendMail(addr, auth, n.config.From, []string{member.Email}, buildMail(n.config.From, member.Email, message))
+}
+
+// buildMail 生成UTF-8纯文本邮件，正文使用base64编码
+func buildMail(from, to string, message *NotificationMessage) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + to + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", message.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(message.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
+
+// WebhookNotifier 通用webhook渠道，以JSON POST通知内容；配置密钥时按支付网关相同的方式签名
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建webhook渠道
+func NewWebhookNotifier(url, secret string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Channel 渠道名称
+func (n *WebhookNotifier) Channel() string {
+	return models.NotificationChannelWebhook
+}
+
+// Send 发送通知到webhook，非2xx响应视为失败
+func (n *WebhookNotifier) Send(ctx context.Context, member *models.Member, message *NotificationMessage) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"kind":     message.Kind,
+		"dedupKey": message.DedupKey,
+		"memberId": member.ID,
+		"username": member.Username,
+		"locale":   message.Locale,
+		"subject":  message.Subject,
+		"body":     message.Body,
+		"data":     message.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderPaymentTimestamp, timestamp)
+	if n.secret != "" {
+		req.Header.Set(HeaderPaymentSignature, SignPaymentPayload(n.secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook返回HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// notificationLocale 会员的通知语言，未设置或无效时使用默认语言
+func notificationLocale(member *models.Member, defaultLocale string) string {
+	locale := strings.ToLower(member.Locale)
+	if models.ValidateLocale(locale) != nil {
+		return defaultLocale
+	}
+	return locale
+}
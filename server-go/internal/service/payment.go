@@ -1,39 +1,66 @@
 package service
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
+	"log"
 	"os"
-	"path/filepath"
 	"time"
 
 	"homemoney/internal/models"
 	"homemoney/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// 支付回调错误
+var (
+	ErrCallbackNotConfigured = errors.New("未配置支付回调签名密钥")
+	ErrInvalidSignature      = errors.New("支付回调签名无效")
+	ErrCallbackExpired       = errors.New("支付回调时间戳已过期")
+	ErrInvalidCallback       = errors.New("支付回调内容无效")
 )
 
 // PaymentService 支付服务
 type PaymentService struct {
 	subscriptionPlanRepo *repository.SubscriptionPlanRepository
 	memberRepo           *repository.MemberRepository
+	subscriptionRepo     *repository.UserSubscriptionRepository
+	paymentOrderRepo     *repository.PaymentOrderRepository
+	gateway              PaymentGateway
+	// callbackSecret 校验支付网关回调签名的共享密钥
+	callbackSecret string
+	// renewalPolicy 自动续费扣款失败后的重试计划
+	renewalPolicy models.RenewalPolicy
 }
 
 // NewPaymentService 创建支付服务
 func NewPaymentService(
 	subscriptionPlanRepo *repository.SubscriptionPlanRepository,
 	memberRepo *repository.MemberRepository,
+	subscriptionRepo *repository.UserSubscriptionRepository,
+	paymentOrderRepo *repository.PaymentOrderRepository,
+	gateway PaymentGateway,
+	callbackSecret string,
+	renewalPolicy models.RenewalPolicy,
 ) *PaymentService {
 	return &PaymentService{
 		subscriptionPlanRepo: subscriptionPlanRepo,
 		memberRepo:           memberRepo,
+		subscriptionRepo:     subscriptionRepo,
+		paymentOrderRepo:     paymentOrderRepo,
+		gateway:              gateway,
+		callbackSecret:       callbackSecret,
+		renewalPolicy:        renewalPolicy,
 	}
 }
 
 // DonationRequest 捐赠请求参数
 type DonationRequest struct {
-	Username string  `json:"username" binding:"required"`
-	Amount   float64 `json:"amount" binding:"required,gt=0"`
+	Username string       `json:"username" binding:"required"`
+	Amount   models.Money `json:"amount" binding:"required,gt=0"`
 }
 
 // SubscribeRequest 订阅支付请求参数
@@ -44,11 +71,11 @@ type SubscribeRequest struct {
 
 // PaymentData 第三方支付请求数据
 type PaymentData struct {
-	Username       string  `json:"username"`
-	Amount         float64 `json:"amount"`
-	ThirdPartyID   string  `json:"thirdPartyId"`
-	ThirdPartyName string  `json:"thirdPartyName"`
-	Description    string  `json:"description"`
+	Username       string       `json:"username"`
+	Amount         models.Money `json:"amount"`
+	ThirdPartyID   string       `json:"thirdPartyId"`
+	ThirdPartyName string       `json:"thirdPartyName"`
+	Description    string       `json:"description"`
 }
 
 // PaymentResponse 支付响应
@@ -63,8 +90,8 @@ type ThirdPartyPaymentResponse struct {
 	OrderID string `json:"orderId"`
 }
 
-// Donate 处理捐赠
-func (s *PaymentService) Donate(username string, amount float64) (*PaymentResponse, error) {
+// Donate 处理捐赠，idempotencyKey为空时自动生成
+func (s *PaymentService) Donate(ctx context.Context, username string, amount models.Money, idempotencyKey string) (*PaymentResponse, error) {
 	// 验证金额
 	if amount <= 0 {
 		return &PaymentResponse{
@@ -82,7 +109,7 @@ func (s *PaymentService) Donate(username string, amount float64) (*PaymentRespon
 		ThirdPartyName: getEnv("THIRD_PARTY_NAME", "家庭财务管理应用"),
 		Description: fmt.Sprintf(`
 			Donation by user %s的捐赠
-			金额 Amount：%f
+			金额 Amount：%s
 			时间 Time：%s
 			若有问题请联系我们：
 			If you have any questions, please contact us: 
@@ -91,29 +118,39 @@ func (s *PaymentService) Donate(username string, amount float64) (*PaymentRespon
 	}
 
 	// 调用第三方支付API
-	response, err := s.callThirdPartyPaymentAPI(paymentData)
+	if idempotencyKey == "" {
+		idempotencyKey = uuid.New().String()
+	}
+	response, err := s.callThirdPartyPaymentAPI(ctx, paymentData, idempotencyKey)
 	if err != nil {
 		return &PaymentResponse{
 			Success: false,
-			Error:   "支付处理失败，请稍后重试",
+			Error:   paymentErrorMessage(err),
 		}, err
 	}
 
-	// 记录捐赠日志
-	fmt.Printf("用户%s捐赠了%f元\n", username, amount)
-
-	// 保存捐赠记录
-	donationRecord := models.DonationRecord{
-		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
+	// 保存待支付订单和捐赠记录，等待支付网关回调确认
+	order := &models.PaymentOrder{
+		OrderID:        response.OrderID,
+		IdempotencyKey: idempotencyKey,
+		Kind:           models.PaymentKindDonation,
+		Username:       username,
+		Amount:         amount,
+	}
+	donationRecord := &models.DonationRecord{
 		Username:  username,
 		Amount:    amount,
 		Timestamp: currentTime,
-		OrderID:   response.OrderID,
-		Status:    "success",
+	}
+	if _, err := s.paymentOrderRepo.CreateDonationOrder(order, donationRecord); err != nil {
+		return &PaymentResponse{
+			Success: false,
+			Error:   "保存支付订单失败",
+		}, err
 	}
 
-	// 异步保存捐赠记录
-	go s.saveDonationRecord(donationRecord)
+	// 记录捐赠日志
+	fmt.Printf("用户%s捐赠了%s元\n", username, amount)
 
 	return &PaymentResponse{
 		Success: true,
@@ -121,8 +158,8 @@ func (s *PaymentService) Donate(username string, amount float64) (*PaymentRespon
 	}, nil
 }
 
-// SubscribePayment 处理订阅支付
-func (s *PaymentService) SubscribePayment(username string, planID string) (*PaymentResponse, error) {
+// SubscribePayment 处理订阅支付，idempotencyKey为空时自动生成
+func (s *PaymentService) SubscribePayment(ctx context.Context, username string, planID string, idempotencyKey string) (*PaymentResponse, error) {
 	// 获取订阅计划
 	plan, err := s.subscriptionPlanRepo.FindByPeriod(planID)
 	if err != nil || plan == nil || !plan.IsActive {
@@ -132,6 +169,15 @@ func (s *PaymentService) SubscribePayment(username string, planID string) (*Paym
 		}, fmt.Errorf("订阅计划不存在或已停用")
 	}
 
+	// 支付成功后订阅归属该会员
+	member, err := s.memberRepo.FindByUsername(username)
+	if err != nil || member == nil {
+		return &PaymentResponse{
+			Success: false,
+			Error:   "会员不存在",
+		}, fmt.Errorf("会员不存在")
+	}
+
 	// 构建支付数据
 	currentTime := time.Now().Format("2006-01-02 15:04:05")
 	periodText := "月度"
@@ -149,7 +195,7 @@ func (s *PaymentService) SubscribePayment(username string, planID string) (*Paym
 		Description: fmt.Sprintf(`
 			会员订阅支付 - %s
 			User %s Membership Subscription
-			金额 Amount：%f
+			金额 Amount：%s
 			订阅周期 Subscription Period：%s
 			时间 Time：%s
 			订阅计划ID Plan ID：%s
@@ -157,97 +203,169 @@ func (s *PaymentService) SubscribePayment(username string, planID string) (*Paym
 	}
 
 	// 调用第三方支付API
-	response, err := s.callThirdPartyPaymentAPI(paymentData)
+	if idempotencyKey == "" {
+		idempotencyKey = uuid.New().String()
+	}
+	response, err := s.callThirdPartyPaymentAPI(ctx, paymentData, idempotencyKey)
 	if err != nil {
 		return &PaymentResponse{
 			Success: false,
-			Error:   "支付处理失败，请稍后重试",
+			Error:   paymentErrorMessage(err),
 		}, err
 	}
 
-	// 生成订单ID
-	orderID := response.OrderID
-	if orderID == "" {
-		orderID = fmt.Sprintf("SUB_%s_%d", username, time.Now().UnixNano())
+	// 保存待支付订单，订阅在支付网关回调确认支付后才生效
+	order, err := s.paymentOrderRepo.Create(&models.PaymentOrder{
+		OrderID:        response.OrderID,
+		IdempotencyKey: idempotencyKey,
+		Kind:           models.PaymentKindSubscription,
+		Username:       username,
+		MemberID:       member.ID,
+		PlanID:         plan.ID,
+		Amount:         plan.Price,
+	})
+	if err != nil {
+		return &PaymentResponse{
+			Success: false,
+			Error:   "保存支付订单失败",
+		}, err
 	}
 
 	// 记录订阅支付日志
-	fmt.Printf("用户%s订阅了%s，金额%f元\n", username, plan.Name, plan.Price)
+	fmt.Printf("用户%s订阅了%s，金额%s元\n", username, plan.Name, plan.Price)
 
-	// 返回支付结果，包含订单ID供后续创建订阅使用
 	return &PaymentResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"orderId":  orderID,
+			"orderId":  order.OrderID,
 			"planId":   plan.ID,
 			"planName": plan.Name,
 			"price":    plan.Price,
+			"status":   order.Status,
 		},
 	}, nil
 }
 
-// callThirdPartyPaymentAPI 调用第三方支付API
-func (s *PaymentService) callThirdPartyPaymentAPI(paymentData PaymentData) (*ThirdPartyPaymentResponse, error) {
-	apiURL := getEnv("THIRD_PARTY_PAYMENT_API", "http://192.168.0.197:3200/api/third-party/payments")
-
-	// 转换为JSON
-	jsonData, err := json.Marshal(paymentData)
-	if err != nil {
+// HandleCallback 校验支付网关回调签名并更新订单状态，status为paid、failed或refunded（拒付）；
+// 重复投递的回调（订单已处于相同状态）直接返回订单
+func (s *PaymentService) HandleCallback(timestamp, signature string, body []byte) (*models.PaymentOrder, error) {
+	if s.callbackSecret == "" {
+		return nil, ErrCallbackNotConfigured
+	}
+	if err := VerifyPaymentSignature(s.callbackSecret, timestamp, signature, body, time.Now()); err != nil {
 		return nil, err
 	}
 
-	// 创建请求
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
+	var callback models.PaymentCallback
+	if err := json.Unmarshal(body, &callback); err != nil || callback.OrderID == "" {
+		return nil, ErrInvalidCallback
+	}
+
+	transactionID := callback.TransactionID
+	if transactionID == "" {
+		transactionID = callback.OrderID
+	}
+
+	var order *models.PaymentOrder
+	var err error
+	switch callback.Status {
+	case models.PaymentOrderPaid:
+		order, err = s.paymentOrderRepo.MarkPaid(callback.OrderID, transactionID, callback.Amount)
+	case models.PaymentOrderFailed:
+		order, err = s.paymentOrderRepo.MarkFailed(callback.OrderID, transactionID)
+		if err == nil && order.Renewal {
+			err = s.recordRenewalFailure(order.SubscriptionID, "支付网关通知扣款失败", time.Now())
+		}
+	case models.PaymentOrderRefunded:
+		order, err = s.applyChargeback(callback.OrderID, transactionID)
+	default:
+		return nil, ErrInvalidCallback
+	}
+
+	if errors.Is(err, repository.ErrPaymentOrderSettled) {
+		existing, findErr := s.paymentOrderRepo.FindByOrderID(callback.OrderID)
+		if findErr == nil && existing != nil && existing.Status == callback.Status {
+			return existing, nil
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	// 由于这是模拟环境，我们直接返回一个模拟的响应
-	// 在实际环境中，这里应该发送HTTP请求到第三方支付API
-	return &ThirdPartyPaymentResponse{
-		OrderID: fmt.Sprintf("ORDER_%d", time.Now().UnixNano()),
-	}, nil
+	log.Printf("支付订单%s已更新为%s", order.OrderID, order.Status)
+	return order, nil
 }
 
-// saveDonationRecord 保存捐赠记录到JSON文件
-func (s *PaymentService) saveDonationRecord(record models.DonationRecord) {
-	// 确保数据目录存在
-	dataDir := filepath.Join(".", "data")
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		fmt.Printf("创建数据目录失败: %v\n", err)
-		return
+// RefundOrder 通过支付网关对已支付订单退款，mode为full或prorated，退款成功后记录退款流水并调整订阅
+func (s *PaymentService) RefundOrder(ctx context.Context, orderID, mode, reason, operator string) (*models.PaymentOrder, *models.PaymentRefund, error) {
+	if err := models.ValidateRefundMode(mode); err != nil {
+		return nil, nil, err
+	}
+	order, err := s.paymentOrderRepo.FindByOrderID(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if order == nil {
+		return nil, nil, repository.ErrPaymentOrderNotFound
 	}
 
-	// 捐赠记录文件路径
-	donationFilePath := filepath.Join(dataDir, "donation_records.json")
+	now := time.Now()
+	refund, err := s.paymentOrderRepo.QuoteRefund(order, mode, now)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// 将记录转换为JSON字符串并添加换行符
-	recordJSON, err := json.Marshal(record)
+	// 每个订单只能退款一次，使用订单ID作为幂等键，重复提交不会重复退款
+	response, err := s.gateway.Refund(ctx, RefundData{
+		OrderID:       order.OrderID,
+		TransactionID: order.TransactionID,
+		Amount:        refund.Amount,
+		Reason:        reason,
+	}, "refund-"+order.ID)
 	if err != nil {
-		fmt.Printf("序列化捐赠记录失败: %v\n", err)
-		return
+		return nil, nil, err
 	}
 
-	recordLine := string(recordJSON) + "\n"
+	refund.RefundID = response.RefundID
+	refund.Reason = reason
+	refund.Operator = operator
+	order, err = s.paymentOrderRepo.ApplyRefund(refund, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Printf("支付订单%s已退款%s元（%s），操作人%s", order.OrderID, refund.Amount, mode, operator)
+	return order, refund, nil
+}
 
-	// 追加记录到文件，每个记录占一行
-	// 使用 os.OpenFile 以追加模式打开文件
-	file, err := os.OpenFile(donationFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// applyChargeback 处理支付网关通知的拒付，按全额退款记录，不再调用网关退款接口
+func (s *PaymentService) applyChargeback(orderID, transactionID string) (*models.PaymentOrder, error) {
+	order, err := s.paymentOrderRepo.FindByOrderID(orderID)
 	if err != nil {
-		fmt.Printf("打开捐赠记录文件失败: %v\n", err)
-		return
+		return nil, err
+	}
+	if order == nil {
+		return nil, repository.ErrPaymentOrderNotFound
+	}
+	if order.Status == models.PaymentOrderRefunded {
+		return nil, repository.ErrPaymentOrderSettled
 	}
-	defer file.Close()
 
-	if _, err := file.WriteString(recordLine); err != nil {
-		fmt.Printf("保存捐赠记录失败: %v\n", err)
-		return
+	now := time.Now()
+	refund, err := s.paymentOrderRepo.QuoteRefund(order, models.RefundFull, now)
+	if err != nil {
+		return nil, err
 	}
+	refund.Mode = models.RefundChargeback
+	refund.RefundID = transactionID
+	refund.Reason = "支付网关通知拒付"
+	refund.Operator = "gateway"
+	return s.paymentOrderRepo.ApplyRefund(refund, now)
+}
 
-	fmt.Printf("捐赠记录已保存到文件: %s\n", donationFilePath)
-	// 错误处理已在各步骤中完成
+// callThirdPartyPaymentAPI 通过支付网关创建支付订单
+func (s *PaymentService) callThirdPartyPaymentAPI(ctx context.Context, paymentData PaymentData, idempotencyKey string) (*ThirdPartyPaymentResponse, error) {
+	return s.gateway.CreatePayment(ctx, paymentData, idempotencyKey)
 }
 
 // getEnv 获取环境变量，如果不存在则返回默认值
@@ -265,7 +383,7 @@ func try(f func(), catch func(error)) {
 			var err error
 			switch x := r.(type) {
 			case string:
-				err = fmt.Errorf(x)
+				err = fmt.Errorf("%s", x)
 			case error:
 				err = x
 			default:
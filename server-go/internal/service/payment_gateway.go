@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"homemoney/internal/models"
+)
+
+// 支付网关请求头
+const (
+	HeaderPaymentTimestamp = "X-Timestamp"
+	HeaderPaymentSignature = "X-Signature"
+	HeaderIdempotencyKey   = "Idempotency-Key"
+)
+
+// PaymentGateway 第三方支付网关，测试时可替换为指向本地假服务的实现
+type PaymentGateway interface {
+	// CreatePayment 创建支付订单，相同idempotencyKey的重复请求只会创建一个订单
+	CreatePayment(ctx context.Context, data PaymentData, idempotencyKey string) (*ThirdPartyPaymentResponse, error)
+	// Refund 对已支付订单退款，相同idempotencyKey的重复请求只会退款一次
+	Refund(ctx context.Context, data RefundData, idempotencyKey string) (*ThirdPartyRefundResponse, error)
+}
+
+// RefundData 退款请求数据
+type RefundData struct {
+	OrderID       string       `json:"orderId"`
+	TransactionID string       `json:"transactionId"`
+	Amount        models.Money `json:"amount"`
+	Reason        string       `json:"reason"`
+}
+
+// ThirdPartyRefundResponse 第三方退款响应
+type ThirdPartyRefundResponse struct {
+	RefundID string `json:"refundId"`
+}
+
+// PaymentGatewayConfig 支付网关配置
+type PaymentGatewayConfig struct {
+	APIURL    string
+	RefundURL string
+	// Secret 与支付网关共享的签名密钥
+	Secret string
+	// Timeout 单次请求超时时间
+	Timeout time.Duration
+	// MaxRetries 网络错误、超时、429和5xx时的最大重试次数
+	MaxRetries int
+	// RetryBackoff 首次重试前的等待时间，之后每次翻倍
+	RetryBackoff time.Duration
+}
+
+// PaymentGatewayConfigFromEnv 从环境变量读取支付网关配置
+func PaymentGatewayConfigFromEnv() PaymentGatewayConfig {
+	config := PaymentGatewayConfig{
+		APIURL:       getEnv("THIRD_PARTY_PAYMENT_API", "http://192.168.0.197:3200/api/third-party/payments"),
+		RefundURL:    getEnv("THIRD_PARTY_REFUND_API", "http://192.168.0.197:3200/api/third-party/refunds"),
+		Secret:       getEnv("THIRD_PARTY_PAYMENT_SECRET", ""),
+		Timeout:      10 * time.Second,
+		MaxRetries:   2,
+		RetryBackoff: 500 * time.Millisecond,
+	}
+	if seconds, err := strconv.Atoi(getEnv("THIRD_PARTY_PAYMENT_TIMEOUT_SECONDS", "")); err == nil && seconds > 0 {
+		config.Timeout = time.Duration(seconds) * time.Second
+	}
+	if retries, err := strconv.Atoi(getEnv("THIRD_PARTY_PAYMENT_MAX_RETRIES", "")); err == nil && retries >= 0 {
+		config.MaxRetries = retries
+	}
+	return config
+}
+
+// GatewayError 支付网关返回的错误
+type GatewayError struct {
+	// StatusCode HTTP状态码，请求未得到响应时为0
+	StatusCode int
+	Message    string
+	// Retryable 是否可以重试
+	Retryable bool
+	// Timeout 是否为超时
+	Timeout bool
+}
+
+// Error 实现error接口
+func (e *GatewayError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("支付网关请求失败: %s", e.Message)
+	}
+	return fmt.Sprintf("支付网关返回错误(HTTP %d): %s", e.StatusCode, e.Message)
+}
+
+// HTTPPaymentGateway 基于HTTP的支付网关客户端
+type HTTPPaymentGateway struct {
+	config PaymentGatewayConfig
+	client *http.Client
+}
+
+// NewHTTPPaymentGateway 创建HTTP支付网关客户端
+func NewHTTPPaymentGateway(config PaymentGatewayConfig) *HTTPPaymentGateway {
+	if config.Secret == "" {
+		log.Println("警告: 未配置THIRD_PARTY_PAYMENT_SECRET，支付请求将不带签名")
+	}
+	return &HTTPPaymentGateway{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// CreatePayment 创建支付订单
+func (g *HTTPPaymentGateway) CreatePayment(ctx context.Context, data PaymentData, idempotencyKey string) (*ThirdPartyPaymentResponse, error) {
+	body, err := g.post(ctx, g.config.APIURL, data, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ThirdPartyPaymentResponse
+	if err := decodeGatewayResponse(body, &response); err != nil {
+		return nil, err
+	}
+	if response.OrderID == "" {
+		return nil, &GatewayError{StatusCode: http.StatusOK, Message: "响应中缺少orderId"}
+	}
+	return &response, nil
+}
+
+// Refund 对已支付订单发起退款
+func (g *HTTPPaymentGateway) Refund(ctx context.Context, data RefundData, idempotencyKey string) (*ThirdPartyRefundResponse, error) {
+	body, err := g.post(ctx, g.config.RefundURL, data, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ThirdPartyRefundResponse
+	if err := decodeGatewayResponse(body, &response); err != nil {
+		return nil, err
+	}
+	if response.RefundID == "" {
+		return nil, &GatewayError{StatusCode: http.StatusOK, Message: "响应中缺少refundId"}
+	}
+	return &response, nil
+}
+
+// post 发送签名请求，可重试的错误按指数退避重试，所有重试使用同一个幂等键
+func (g *HTTPPaymentGateway) post(ctx context.Context, url string, payload interface{}, idempotencyKey string) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff := g.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		respBody, err := g.send(ctx, url, body, idempotencyKey)
+		if err == nil {
+			return respBody, nil
+		}
+
+		var gatewayErr *GatewayError
+		if !errors.As(err, &gatewayErr) || !gatewayErr.Retryable || attempt >= g.config.MaxRetries {
+			return nil, err
+		}
+
+		log.Printf("支付网关请求失败，%v后第%d次重试: %v", backoff, attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return nil, &GatewayError{Message: ctx.Err().Error(), Timeout: errors.Is(ctx.Err(), context.DeadlineExceeded)}
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send 发送一次签名请求，返回2xx响应的内容
+func (g *HTTPPaymentGateway) send(ctx context.Context, url string, body []byte, idempotencyKey string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderIdempotencyKey, idempotencyKey)
+	req.Header.Set(HeaderPaymentTimestamp, timestamp)
+	if g.config.Secret != "" {
+		req.Header.Set(HeaderPaymentSignature, SignPaymentPayload(g.config.Secret, timestamp, body))
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		timeout := errors.As(err, &netErr) && netErr.Timeout()
+		return nil, &GatewayError{Message: err.Error(), Retryable: ctx.Err() == nil, Timeout: timeout}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, &GatewayError{StatusCode: resp.StatusCode, Message: err.Error(), Retryable: true}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &GatewayError{
+			StatusCode: resp.StatusCode,
+			Message:    gatewayErrorMessage(respBody, resp.Status),
+			Retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+		}
+	}
+	return respBody, nil
+}
+
+// decodeGatewayResponse 解析网关响应，兼容直接返回对象和 {"data": {...}} 两种格式
+func decodeGatewayResponse(body []byte, out interface{}) error {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && len(envelope.Data) > 0 && envelope.Data[0] == '{' {
+		body = envelope.Data
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return &GatewayError{StatusCode: http.StatusOK, Message: "无法解析响应: " + err.Error()}
+	}
+	return nil
+}
+
+// gatewayErrorMessage 从错误响应中提取错误信息
+func gatewayErrorMessage(body []byte, status string) string {
+	var payload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if payload.Message != "" {
+			return payload.Message
+		}
+		if payload.Error != "" {
+			return payload.Error
+		}
+	}
+	return status
+}
+
+// SignPaymentPayload 计算签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+func SignPaymentPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// paymentCallbackTolerance 回调时间戳与当前时间允许的最大偏差，用于防止重放
+const paymentCallbackTolerance = 5 * time.Minute
+
+// VerifyPaymentSignature 校验回调签名和时间戳
+func VerifyPaymentSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+	expected := SignPaymentPayload(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > paymentCallbackTolerance || skew < -paymentCallbackTolerance {
+		return ErrCallbackExpired
+	}
+	return nil
+}
+
+// paymentErrorMessage 将支付网关错误转换为返回给客户端的提示
+func paymentErrorMessage(err error) string {
+	var gatewayErr *GatewayError
+	if !errors.As(err, &gatewayErr) {
+		return "支付处理失败，请稍后重试"
+	}
+	switch {
+	case gatewayErr.Timeout:
+		return "支付网关响应超时，请稍后重试"
+	case gatewayErr.StatusCode >= 400 && gatewayErr.StatusCode < 500 && !gatewayErr.Retryable:
+		return "支付请求被拒绝: " + gatewayErr.Message
+	default:
+		return "支付处理失败，请稍后重试"
+	}
+}
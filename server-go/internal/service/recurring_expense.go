@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"homemoney/internal/repository"
+)
+
+// NewRecurringExpenseJob 创建周期性消费后台任务，把截至今天到期的模板生成消费记录
+func NewRecurringExpenseJob(recurringRepo *repository.RecurringExpenseRepository) JobFunc {
+	return func(ctx context.Context) error {
+		created, err := recurringRepo.MaterializeDue(time.Now().Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+		if created > 0 {
+			log.Printf("周期性消费已生成 %d 条消费记录", created)
+		}
+		return nil
+	}
+}
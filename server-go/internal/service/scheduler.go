@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 后台任务错误
+var (
+	ErrJobNotFound = errors.New("后台任务不存在")
+	ErrJobRunning  = errors.New("后台任务正在运行，请稍后再试")
+)
+
+// JobFunc 后台任务，ctx在服务关闭时取消
+type JobFunc func(ctx context.Context) error
+
+// JobStatus 后台任务的运行状态
+type JobStatus struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Running  bool   `json:"running"`
+	// LastStartedAt 最近一次开始时间，从未运行时为空
+	LastStartedAt  *time.Time `json:"lastStartedAt"`
+	LastDurationMs int64      `json:"lastDurationMs"`
+	LastError      string     `json:"lastError,omitempty"`
+	LastSuccessAt  *time.Time `json:"lastSuccessAt"`
+	NextRunAt      *time.Time `json:"nextRunAt"`
+	RunCount       int        `json:"runCount"`
+	FailureCount   int        `json:"failureCount"`
+	// SkippedCount 因上一次仍在运行而跳过的次数
+	SkippedCount int `json:"skippedCount"`
+}
+
+// scheduledJob 已注册的后台任务
+type scheduledJob struct {
+	name       string
+	spec       string
+	schedule   Schedule
+	runOnStart bool
+	run        JobFunc
+
+	// lock 单次运行锁，保证同一任务不会重叠执行
+	lock   sync.Mutex
+	status JobStatus
+}
+
+// Scheduler 进程内后台任务调度器，每个任务按各自的调度规则运行，同一任务同一时间只运行一个实例
+type Scheduler struct {
+	mu   sync.Mutex
+	jobs map[string]*scheduledJob
+	ctx  context.Context
+	wg   sync.WaitGroup
+}
+
+// NewScheduler 创建后台任务调度器
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		jobs: make(map[string]*scheduledJob),
+		ctx:  context.Background(),
+	}
+}
+
+// JobScheduleFromEnv 读取任务的调度规则，环境变量名为 JOB_SCHEDULE_ 加上大写的任务名（-替换为_），
+// 如 JOB_SCHEDULE_EXPIRE_SUBSCRIPTIONS；设置为off时禁用该任务的定时执行
+func JobScheduleFromEnv(name, defaultSpec string) string {
+	key := "JOB_SCHEDULE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	return getEnv(key, defaultSpec)
+}
+
+// Register 注册后台任务，spec为off时任务只能手动执行；runOnStart为true时调度器启动后立即执行一次
+func (s *Scheduler) Register(name, spec string, runOnStart bool, run JobFunc) error {
+	job := &scheduledJob{
+		name:       name,
+		spec:       spec,
+		runOnStart: runOnStart,
+		run:        run,
+		status:     JobStatus{Name: name, Schedule: spec},
+	}
+	if spec == "off" {
+		job.runOnStart = false
+	} else {
+		schedule, err := ParseSchedule(spec)
+		if err != nil {
+			return fmt.Errorf("任务%s: %w", name, err)
+		}
+		job.schedule = schedule
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("任务%s已注册", name)
+	}
+	s.jobs[name] = job
+	return nil
+}
+
+// Start 启动所有任务的定时执行，ctx取消后不再启动新的运行
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	jobs := make([]*scheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.mu.Unlock()
+
+	for _, job := range jobs {
+		if job.schedule == nil {
+			continue
+		}
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Wait 等待正在运行的任务结束，超时返回false
+func (s *Scheduler) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		// 手工触发的运行不在wg中，通过单次运行锁等待其结束
+		s.mu.Lock()
+		jobs := make([]*scheduledJob, 0, len(s.jobs))
+		for _, job := range s.jobs {
+			jobs = append(jobs, job)
+		}
+		s.mu.Unlock()
+		for _, job := range jobs {
+			job.lock.Lock()
+			job.lock.Unlock()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// RunNow 立即执行任务并返回任务的执行结果，任务正在运行时返回ErrJobRunning
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	ctx := s.ctx
+	s.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	return s.execute(ctx, job)
+}
+
+// Statuses 返回所有任务的运行状态，按任务名排序
+func (s *Scheduler) Statuses() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, job.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// loop 按调度规则循环执行任务，直到ctx取消
+func (s *Scheduler) loop(ctx context.Context, job *scheduledJob) {
+	defer s.wg.Done()
+
+	if job.runOnStart {
+		s.execute(ctx, job)
+	}
+
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("后台任务%s没有下一次执行时间，停止调度", job.name)
+			return
+		}
+		s.mu.Lock()
+		job.status.NextRunAt = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.execute(ctx, job)
+		}
+	}
+}
+
+// execute 在单次运行锁内执行任务并记录状态，任务正在运行时跳过
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob) error {
+	if !job.lock.TryLock() {
+		s.mu.Lock()
+		job.status.SkippedCount++
+		s.mu.Unlock()
+		log.Printf("后台任务%s上一次运行尚未结束，跳过本次运行", job.name)
+		return ErrJobRunning
+	}
+	defer job.lock.Unlock()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	started := time.Now()
+	s.mu.Lock()
+	job.status.Running = true
+	job.status.LastStartedAt = &started
+	s.mu.Unlock()
+
+	err := runJob(ctx, job)
+
+	finished := time.Now()
+	s.mu.Lock()
+	job.status.Running = false
+	job.status.LastDurationMs = finished.Sub(started).Milliseconds()
+	job.status.RunCount++
+	if err != nil {
+		job.status.LastError = err.Error()
+		job.status.FailureCount++
+	} else {
+		job.status.LastError = ""
+		job.status.LastSuccessAt = &finished
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("后台任务%s执行失败: %v", job.name, err)
+	}
+	return err
+}
+
+// runJob 执行任务，任务panic时转换为错误，避免影响调度器
+func runJob(ctx context.Context, job *scheduledJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("任务panic: %v", r)
+		}
+	}()
+	return job.run(ctx)
+}
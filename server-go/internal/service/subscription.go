@@ -1,12 +1,16 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 
 	"homemoney/internal/models"
 	"homemoney/internal/repository"
 )
 
+// ErrSubscriptionNotFound 订阅不存在，或属于其他家庭的会员
+var ErrSubscriptionNotFound = errors.New("订阅记录不存在")
+
 // SubscriptionPlanService 订阅计划服务
 type SubscriptionPlanService struct {
 	planRepo *repository.SubscriptionPlanRepository
@@ -102,8 +106,8 @@ func (s *SubscriptionService) GetSubscriptionPlans() ([]models.SubscriptionPlan,
 	return s.planRepo.GetActivePlans()
 }
 
-// CreateSubscription 创建订阅
-func (s *SubscriptionService) CreateSubscription(username, planID string, autoRenew bool) (*models.UserSubscription, error) {
+// CreateSubscription 创建订阅，trialDays大于0时创建试用订阅
+func (s *SubscriptionService) CreateSubscription(username, planID string, autoRenew bool, trialDays int, actor string) (*models.UserSubscription, error) {
 	// 验证用户存在
 	member, err := s.memberRepo.FindByUsername(username)
 	if err != nil {
@@ -132,7 +136,7 @@ func (s *SubscriptionService) CreateSubscription(username, planID string, autoRe
 	}
 
 	// 创建新订阅
-	subscription, err := s.subscriptionRepo.CreateSubscription(member.ID, planID, autoRenew)
+	subscription, err := s.subscriptionRepo.CreateSubscription(member.ID, planID, autoRenew, trialDays, actor)
 	if err != nil {
 		return nil, err
 	}
@@ -140,39 +144,87 @@ func (s *SubscriptionService) CreateSubscription(username, planID string, autoRe
 	return subscription, nil
 }
 
-// GetCurrentSubscription 获取用户当前订阅
-func (s *SubscriptionService) GetCurrentSubscription(username string) (*models.UserSubscription, error) {
-	member, err := s.memberRepo.FindByUsername(username)
+// GetCurrentSubscription 获取operator可以查看的会员的当前订阅
+func (s *SubscriptionService) GetCurrentSubscription(operator *models.Member, username string) (*models.UserSubscription, error) {
+	member, err := findAccessibleMember(s.memberRepo, operator, username)
 	if err != nil {
 		return nil, err
 	}
-	if member == nil {
-		return nil, fmt.Errorf("会员不存在")
-	}
 
 	return s.subscriptionRepo.GetCurrentSubscription(member.ID)
 }
 
-// GetMemberSubscriptions 获取用户所有订阅记录
-func (s *SubscriptionService) GetMemberSubscriptions(username string) ([]models.UserSubscription, error) {
-	member, err := s.memberRepo.FindByUsername(username)
+// GetMemberSubscriptions 获取operator可以查看的会员的所有订阅记录
+func (s *SubscriptionService) GetMemberSubscriptions(operator *models.Member, username string) ([]models.UserSubscription, error) {
+	member, err := findAccessibleMember(s.memberRepo, operator, username)
 	if err != nil {
 		return nil, err
 	}
-	if member == nil {
-		return nil, fmt.Errorf("会员不存在")
-	}
 
 	return s.subscriptionRepo.GetMemberSubscriptions(member.ID)
 }
 
-// CancelSubscription 取消订阅
-func (s *SubscriptionService) CancelSubscription(subscriptionID string) error {
-	return s.subscriptionRepo.CancelSubscription(subscriptionID)
+// CancelSubscription 由operator取消订阅，已取消或已过期的订阅不能再次取消；
+// 家庭所有者只能取消本家庭成员的订阅
+func (s *SubscriptionService) CancelSubscription(operator *models.Member, subscriptionID, reason string) error {
+	if _, err := s.findAccessibleSubscription(operator, subscriptionID); err != nil {
+		return err
+	}
+	return s.subscriptionRepo.CancelSubscription(subscriptionID, operator.Username, reason)
 }
 
-// RenewSubscription 续费订阅
-func (s *SubscriptionService) RenewSubscription(subscriptionID string) error {
+// TransitionSubscription 手工变更订阅状态，只允许状态机中定义的流转
+func (s *SubscriptionService) TransitionSubscription(subscriptionID, to, actor, reason string) (*models.UserSubscription, error) {
+	if err := models.ValidateSubscriptionStatus(to); err != nil {
+		return nil, err
+	}
+	subscription, err := s.subscriptionRepo.FindByID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, fmt.Errorf("订阅记录不存在")
+	}
+	if err := s.subscriptionRepo.Transition(subscription, to, actor, reason); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetSubscriptionEvents 获取operator可以查看的订阅的状态变更记录
+func (s *SubscriptionService) GetSubscriptionEvents(operator *models.Member, subscriptionID string) ([]models.SubscriptionEvent, error) {
+	if _, err := s.findAccessibleSubscription(operator, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.subscriptionRepo.GetEvents(subscriptionID)
+}
+
+// findAccessibleSubscription 查找operator可以管理的订阅，系统管理员可以管理所有订阅，
+// 其他角色只能管理本家庭成员的订阅；其他家庭的订阅返回ErrSubscriptionNotFound
+func (s *SubscriptionService) findAccessibleSubscription(operator *models.Member, subscriptionID string) (*models.UserSubscription, error) {
+	subscription, err := s.subscriptionRepo.FindByID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	if operator.Can(models.PermissionAdmin) {
+		return subscription, nil
+	}
+
+	member, err := s.memberRepo.FindByID(subscription.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || !inSameHousehold(operator, member) {
+		return nil, ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+// RenewSubscription 续费订阅，已取消或已过期的订阅不能续费
+func (s *SubscriptionService) RenewSubscription(subscriptionID, actor string) error {
 	subscription, err := s.subscriptionRepo.FindByID(subscriptionID)
 	if err != nil {
 		return err
@@ -195,9 +247,8 @@ func (s *SubscriptionService) RenewSubscription(subscriptionID string) error {
 
 	// 更新订阅
 	subscription.EndDate = newEndDate
-	subscription.Status = "active"
 
-	return s.subscriptionRepo.Update(subscription)
+	return s.subscriptionRepo.Transition(subscription, models.SubscriptionActive, actor, "手工续费")
 }
 
 // CheckExpiredSubscriptions 检查过期订阅
@@ -205,11 +256,6 @@ func (s *SubscriptionService) CheckExpiredSubscriptions() error {
 	return s.subscriptionRepo.CheckAndExpireSubscriptions()
 }
 
-// ProcessAutoRenewals 处理自动续费
-func (s *SubscriptionService) ProcessAutoRenewals() error {
-	return s.subscriptionRepo.AutoRenewSubscriptions()
-}
-
 // GetExpiringSubscriptions 获取即将过期的订阅
 func (s *SubscriptionService) GetExpiringSubscriptions(daysBefore int) ([]models.UserSubscription, error) {
 	return s.subscriptionRepo.GetExpiringSubscriptions(daysBefore)
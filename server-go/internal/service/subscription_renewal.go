@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"homemoney/internal/models"
+	"homemoney/internal/repository"
+)
+
+// RenewalPolicyFromEnv 从环境变量读取自动续费重试计划：
+// SUBSCRIPTION_RENEWAL_RETRY_DAYS 为到期后第几天重试扣款（逗号分隔，如1,3,5），SUBSCRIPTION_GRACE_DAYS 为宽限期天数，
+// SUBSCRIPTION_RENEWAL_PENDING_HOURS 为续费订单等待支付网关回调的小时数
+func RenewalPolicyFromEnv() models.RenewalPolicy {
+	policy := models.RenewalPolicy{
+		RetryDelays:    models.DefaultRenewalPolicy.RetryDelays,
+		GracePeriod:    models.DefaultRenewalPolicy.GracePeriod,
+		PendingTimeout: models.DefaultRenewalPolicy.PendingTimeout,
+	}
+	if value := getEnv("SUBSCRIPTION_RENEWAL_RETRY_DAYS", ""); value != "" {
+		var delays []time.Duration
+		for _, part := range strings.Split(value, ",") {
+			days, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || days <= 0 {
+				log.Printf("SUBSCRIPTION_RENEWAL_RETRY_DAYS格式错误，使用默认重试计划: %s", value)
+				delays = nil
+				break
+			}
+			delays = append(delays, time.Duration(days)*24*time.Hour)
+		}
+		if delays != nil {
+			policy.RetryDelays = delays
+		}
+	}
+	if days, err := strconv.Atoi(getEnv("SUBSCRIPTION_GRACE_DAYS", "")); err == nil && days >= 0 {
+		policy.GracePeriod = time.Duration(days) * 24 * time.Hour
+	}
+	if hours, err := strconv.Atoi(getEnv("SUBSCRIPTION_RENEWAL_PENDING_HOURS", "")); err == nil && hours > 0 {
+		policy.PendingTimeout = time.Duration(hours) * time.Hour
+	}
+	return policy
+}
+
+// ProcessAutoRenewals 为到期的自动续费订阅创建扣款订单，订阅在支付网关回调确认支付后才延长；
+// 扣款请求失败或续费订单超时未回调时订阅进入宽限期并按重试计划重试，单个订阅失败不影响其他订阅
+func (s *PaymentService) ProcessAutoRenewals(ctx context.Context) error {
+	now := time.Now()
+	errs := s.failStaleRenewalOrders(now)
+
+	subscriptions, err := s.subscriptionRepo.FindDueRenewals(now)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	for i := range subscriptions {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.chargeRenewal(ctx, &subscriptions[i], now); err != nil {
+			errs = append(errs, fmt.Errorf("订阅%s: %w", subscriptions[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// chargeRenewal 为订阅创建一次续费扣款；网关拒绝或请求失败记为一次扣款失败，只有保存数据出错时返回错误
+func (s *PaymentService) chargeRenewal(ctx context.Context, subscription *models.UserSubscription, now time.Time) error {
+	plan := subscription.Plan
+	if plan.ID == "" || !plan.IsActive {
+		return s.recordRenewalFailure(subscription.ID, "订阅计划不存在或已停用", now)
+	}
+
+	paymentData := PaymentData{
+		Username:       subscription.Member.Username,
+		Amount:         plan.Price,
+		ThirdPartyID:   getEnv("THIRD_PARTY_ID", "HomeMoney"),
+		ThirdPartyName: getEnv("THIRD_PARTY_NAME", "家庭财务管理应用"),
+		Description: fmt.Sprintf(`
+			会员自动续费 - %s
+			User %s Membership Renewal
+			金额 Amount：%s
+			原到期时间 Previous End Date：%s
+			订阅ID Subscription ID：%s
+		`, plan.Name, subscription.Member.Username, plan.Price, subscription.EndDate.Format("2006-01-02 15:04:05"), subscription.ID),
+	}
+
+	// 同一订阅、同一到期时间的同一次尝试使用相同的幂等键，任务重复执行也只会扣款一次
+	idempotencyKey := fmt.Sprintf("renewal-%s-%d-%d", subscription.ID, subscription.EndDate.Unix(), subscription.RenewalAttempts+1)
+	response, err := s.callThirdPartyPaymentAPI(ctx, paymentData, idempotencyKey)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		log.Printf("订阅%s自动续费扣款失败: %v", subscription.ID, err)
+		return s.recordRenewalFailure(subscription.ID, paymentErrorMessage(err), now)
+	}
+
+	order, err := s.paymentOrderRepo.Create(&models.PaymentOrder{
+		OrderID:        response.OrderID,
+		IdempotencyKey: idempotencyKey,
+		Kind:           models.PaymentKindSubscription,
+		Username:       subscription.Member.Username,
+		MemberID:       subscription.MemberID,
+		PlanID:         plan.ID,
+		Amount:         plan.Price,
+		SubscriptionID: subscription.ID,
+		Renewal:        true,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("订阅%s已创建自动续费订单%s，金额%s元", subscription.ID, order.OrderID, order.Amount)
+	return nil
+}
+
+// failStaleRenewalOrders 将超过PendingTimeout仍未回调的续费订单标记为失败并记为一次扣款失败，
+// 否则订阅会因存在待支付订单而不再重试，也不会进入宽限期和过期；返回处理各订单时的错误
+func (s *PaymentService) failStaleRenewalOrders(now time.Time) []error {
+	orders, err := s.paymentOrderRepo.FindStalePendingRenewals(now.Add(-s.renewalPolicy.PendingTimeout))
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, order := range orders {
+		// 支付网关在此期间回调的订单已不是待支付状态，跳过
+		if _, err := s.paymentOrderRepo.MarkFailed(order.OrderID, order.TransactionID); err != nil {
+			if !errors.Is(err, repository.ErrPaymentOrderSettled) {
+				errs = append(errs, fmt.Errorf("续费订单%s: %w", order.OrderID, err))
+			}
+			continue
+		}
+		log.Printf("续费订单%s超过%s未收到支付网关回调，已标记为失败", order.OrderID, s.renewalPolicy.PendingTimeout)
+		if err := s.recordRenewalFailure(order.SubscriptionID, "支付网关超时未确认扣款结果", now); err != nil {
+			errs = append(errs, fmt.Errorf("订阅%s: %w", order.SubscriptionID, err))
+		}
+	}
+	return errs
+}
+
+// recordRenewalFailure 记录一次续费扣款失败并安排重试，订阅已被其他请求修改时跳过
+func (s *PaymentService) recordRenewalFailure(subscriptionID, reason string, now time.Time) error {
+	subscription, err := s.subscriptionRepo.RecordRenewalFailure(subscriptionID, s.renewalPolicy, reason, now)
+	if errors.Is(err, repository.ErrSubscriptionChanged) || errors.Is(err, models.ErrInvalidSubscriptionTransition) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if subscription.NextRenewalAt != nil {
+		log.Printf("订阅%s将于%s重试扣款", subscription.ID, subscription.NextRenewalAt.Format("2006-01-02 15:04:05"))
+	} else {
+		log.Printf("订阅%s没有剩余重试次数，将于%s过期", subscription.ID, subscription.GraceEndsAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
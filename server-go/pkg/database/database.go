@@ -10,6 +10,7 @@ import (
 	"homemoney/internal/models"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -47,12 +48,42 @@ func InitDB(dbPath string) (*Database, error) {
 
 // AutoMigrate 自动迁移数据库结构
 func AutoMigrate(db *gorm.DB) error {
+	// 预算和账户的唯一索引改为按家庭区分，先删除旧索引
+	if err := dropLegacyIndexes(db); err != nil {
+		return err
+	}
+
+	// 金额字段由浮点数改为以分为单位的整数，先转换已有数据
+	if err := migrateMoneyColumns(db); err != nil {
+		return err
+	}
+
 	// 执行迁移，忽略表已存在的错误
 	err := db.AutoMigrate(
 		&models.Expense{},
 		&models.Member{},
 		&models.SubscriptionPlan{},
 		&models.UserSubscription{},
+		&models.Budget{},
+		&models.Income{},
+		&models.Account{},
+		&models.Transfer{},
+		&models.RecurringExpense{},
+		&models.Household{},
+		&models.AuthSession{},
+		&models.PaymentOrder{},
+		&models.DonationRecord{},
+		&models.PaymentRefund{},
+		&models.SubscriptionEvent{},
+		&models.NotificationRecord{},
+		&models.InboxMessage{},
+		&models.Tag{},
+		&models.ExpenseTag{},
+		&models.Category{},
+		&models.ExpenseSplit{},
+		&models.Settlement{},
+		&models.ExchangeRate{},
+		&models.MemberClaim{},
 	)
 	
 	// 如果是表已存在的错误，记录日志并返回nil
@@ -64,6 +95,88 @@ func AutoMigrate(db *gorm.DB) error {
 	return err
 }
 
+// dropLegacyIndexes 删除已被按家庭区分的唯一索引取代的旧索引
+func dropLegacyIndexes(db *gorm.DB) error {
+	legacyIndexes := []struct {
+		model interface{}
+		name  string
+	}{
+		{&models.Budget{}, "idx_budget_category_month"},
+		{&models.Account{}, "idx_accounts_name"},
+	}
+
+	migrator := db.Migrator()
+	for _, index := range legacyIndexes {
+		if !migrator.HasTable(index.model) || !migrator.HasIndex(index.model, index.name) {
+			continue
+		}
+		if err := migrator.DropIndex(index.model, index.name); err != nil {
+			return fmt.Errorf("failed to drop legacy index %s: %w", index.name, err)
+		}
+	}
+	return nil
+}
+
+// migrateMoneyColumns 将以元为单位的浮点金额列转换为以分为单位的整数列；
+// 列类型已为整数时跳过，因此可以重复执行
+func migrateMoneyColumns(db *gorm.DB) error {
+	moneyColumns := []struct {
+		model  interface{}
+		field  string
+		column string
+	}{
+		{&models.Expense{}, "Amount", "amount"},
+		{&models.Income{}, "Amount", "amount"},
+		{&models.Budget{}, "Amount", "amount"},
+		{&models.Account{}, "OpeningBalance", "opening_balance"},
+		{&models.Transfer{}, "Amount", "amount"},
+		{&models.RecurringExpense{}, "Amount", "amount"},
+		{&models.ExpenseSplit{}, "Amount", "amount"},
+		{&models.Settlement{}, "Amount", "amount"},
+		{&models.SubscriptionPlan{}, "Price", "price"},
+		{&models.DonationRecord{}, "Amount", "amount"},
+		{&models.PaymentOrder{}, "Amount", "amount"},
+		{&models.PaymentOrder{}, "RefundedAmount", "refunded_amount"},
+		{&models.PaymentRefund{}, "Amount", "amount"},
+	}
+
+	migrator := db.Migrator()
+	for _, money := range moneyColumns {
+		if !migrator.HasTable(money.model) || !migrator.HasColumn(money.model, money.column) {
+			continue
+		}
+		columnTypes, err := migrator.ColumnTypes(money.model)
+		if err != nil {
+			return fmt.Errorf("failed to read columns of %T: %w", money.model, err)
+		}
+		converted := false
+		for _, columnType := range columnTypes {
+			if columnType.Name() == money.column {
+				converted = strings.Contains(strings.ToLower(columnType.DatabaseTypeName()), "int")
+			}
+		}
+		if converted {
+			continue
+		}
+
+		// 先修改列类型再换算为分，ROUND避免 19.99*100 之类的浮点误差在取整时被截断
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Migrator().AlterColumn(money.model, money.field); err != nil {
+				return err
+			}
+			// 已软删除的记录同样需要转换
+			return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).
+				Unscoped().
+				Model(money.model).
+				UpdateColumn(money.column, gorm.Expr("CAST(ROUND(? * 100) AS INTEGER)", clause.Column{Name: money.column})).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate money column %T.%s: %w", money.model, money.column, err)
+		}
+	}
+	return nil
+}
+
 // Close 关闭数据库连接
 func (d *Database) Close() error {
 	sqlDB, err := d.DB.DB()